@@ -3,4 +3,20 @@ package golog
 import "io"
 
 
-type Appender = io.WriteCloser
\ No newline at end of file
+type Appender = io.WriteCloser
+
+// LevelWriter is an optional interface of Appender.
+// If an appender implements it, the logger calls WriteLevel instead of Write
+// so that the appender can handle the event by its level.
+type LevelWriter interface {
+	WriteLevel(level LogLevel, data []byte) (n int, err error)
+}
+
+// EventWriter is an optional interface of Appender.
+// If an appender implements it, the logger calls WriteEvent instead of Write
+// so that the appender can export the metadata and the event as structured data.
+// metadata is nil if metadata is disabled. The appender must not retain metadata and event after WriteEvent returns,
+// because the logger reuses them.
+type EventWriter interface {
+	WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error
+}
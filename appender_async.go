@@ -0,0 +1,308 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultAsyncQueueSize
+const defaultAsyncQueueSize = 1024
+
+// ErrAsyncQueueFull is returned when an event is dropped by the overflow policy
+var ErrAsyncQueueFull = errors.New("golog: async appender queue is full")
+
+// ErrAsyncAppenderClosed is returned when writing to a closed AsyncAppender
+var ErrAsyncAppenderClosed = errors.New("golog: async appender is closed")
+
+// OverflowPolicy decides what happens when the queue of AsyncAppender is full
+type OverflowPolicy int
+
+// OverflowPolicy Constants
+const (
+	// OverflowPolicy_BLOCK waits until the queue has room
+	OverflowPolicy_BLOCK OverflowPolicy = 0
+	// OverflowPolicy_DROP_NEWEST drops the event being written
+	OverflowPolicy_DROP_NEWEST OverflowPolicy = 1
+	// OverflowPolicy_DROP_OLDEST drops the oldest queued event
+	OverflowPolicy_DROP_OLDEST OverflowPolicy = 2
+	// OverflowPolicy_DROP_BELOW_LEVEL drops events below the discard level and blocks the others
+	OverflowPolicy_DROP_BELOW_LEVEL OverflowPolicy = 3
+)
+
+// asyncEvent
+type asyncEvent struct {
+	level LogLevel
+	data  []byte
+	flush chan struct{}
+}
+
+// AsyncAppender writes events to the wrapped appender in a background goroutine.
+//
+// Events are queued in a bounded queue and the overflow policy decides
+// what happens when the queue is full. Events written by Write
+// (instead of WriteLevel) are treated as LogLevel_INFO.
+// Records are encoded on the logging goroutine before queueing, by the Encoder of the wrapped EncoderAppender if any.
+type AsyncAppender struct {
+	appender Appender
+	queue    chan asyncEvent
+	// flushes receives flush requests taken from the head of the queue by OverflowPolicy_DROP_OLDEST
+	flushes      chan chan struct{}
+	policy       OverflowPolicy
+	discardLevel LogLevel
+	dropped      uint64
+
+	done chan struct{}
+
+	mu     *sync.RWMutex
+	closed bool
+}
+
+// AsyncOption
+type AsyncOption func(appender *AsyncAppender)
+
+// WithAsyncQueueSize sets the number of events which can be queued
+func WithAsyncQueueSize(size int) AsyncOption {
+	return func(appender *AsyncAppender) {
+		if size > 0 {
+			appender.queue = make(chan asyncEvent, size)
+		}
+	}
+}
+
+// WithAsyncOverflowPolicy sets the overflow policy. The default is OverflowPolicy_BLOCK.
+func WithAsyncOverflowPolicy(policy OverflowPolicy) AsyncOption {
+	return func(appender *AsyncAppender) {
+		appender.policy = policy
+	}
+}
+
+// WithAsyncDiscardLevel sets the level used by OverflowPolicy_DROP_BELOW_LEVEL.
+// Events below the level are dropped when the queue is full. The default is LogLevel_WARN.
+func WithAsyncDiscardLevel(level LogLevel) AsyncOption {
+	return func(appender *AsyncAppender) {
+		appender.discardLevel = level
+	}
+}
+
+// NewAsyncAppender returns new AsyncAppender wrapping appender
+func NewAsyncAppender(appender Appender, options ...AsyncOption) *AsyncAppender {
+	asyncAppender := &AsyncAppender{
+		appender:     appender,
+		queue:        make(chan asyncEvent, defaultAsyncQueueSize),
+		policy:       OverflowPolicy_BLOCK,
+		discardLevel: LogLevel_WARN,
+		done:         make(chan struct{}),
+		mu:           new(sync.RWMutex),
+	}
+
+	for _, option := range options {
+		option(asyncAppender)
+	}
+	asyncAppender.flushes = make(chan chan struct{}, cap(asyncAppender.queue))
+
+	go asyncAppender.run()
+
+	return asyncAppender
+}
+
+// Write implements io.Writer
+func (appender *AsyncAppender) Write(data []byte) (n int, err error) {
+	return appender.WriteLevel(LogLevel_INFO, data)
+}
+
+// WriteLevel implements LevelWriter.
+// data is copied because the caller may reuse it.
+func (appender *AsyncAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	return appender.enqueue(level, append([]byte(nil), data...))
+}
+
+// WriteRecord implements RecordWriter.
+// The record is encoded on the calling goroutine and the encoded bytes are queued,
+// because the record must not be retained after WriteRecord returns.
+// If the wrapped appender is EncoderAppender, the record is encoded by its Encoder,
+// otherwise it is encoded by the event as the logger does.
+func (appender *AsyncAppender) WriteRecord(record *Record) error {
+	if encoder, ok := appender.appender.(recordEncoder); ok {
+		_, err := appender.enqueue(record.Level, encoder.encodeRecord(record))
+		return err
+	}
+
+	appendEncoder, ok := record.Event.(AppendEncoder)
+	if !ok {
+		_, err := appender.enqueue(record.Level, record.Event.Encode(record.Metadata))
+		return err
+	}
+
+	pooled := encodeBufferPool.Get().(*[]byte)
+	encoded := appendEncoder.AppendEncode((*pooled)[:0], record.Metadata)
+	_, err := appender.WriteLevel(record.Level, encoded)
+	if cap(encoded) <= encodeBuffer_MAX_SIZE {
+		*pooled = encoded[:0]
+		encodeBufferPool.Put(pooled)
+	}
+	return err
+}
+
+// enqueue queues data by the overflow policy. data must not be modified by the caller.
+func (appender *AsyncAppender) enqueue(level LogLevel, data []byte) (n int, err error) {
+	appender.mu.RLock()
+	defer appender.mu.RUnlock()
+
+	if appender.closed {
+		return 0, ErrAsyncAppenderClosed
+	}
+
+	event := asyncEvent{level: level, data: data}
+
+	switch appender.policy {
+	case OverflowPolicy_DROP_NEWEST:
+		if !appender.tryEnqueue(event) {
+			atomic.AddUint64(&appender.dropped, 1)
+			return 0, ErrAsyncQueueFull
+		}
+
+	case OverflowPolicy_DROP_OLDEST:
+		for !appender.tryEnqueue(event) {
+			select {
+			case oldest := <-appender.queue:
+				if oldest.flush != nil {
+					// never drop flush requests. The events before it have been taken by run,
+					// so that it is handed to run without waiting for the events after it.
+					appender.flushes <- oldest.flush
+				} else {
+					atomic.AddUint64(&appender.dropped, 1)
+				}
+			default:
+			}
+		}
+
+	case OverflowPolicy_DROP_BELOW_LEVEL:
+		if level >= appender.discardLevel {
+			appender.queue <- event
+		} else if !appender.tryEnqueue(event) {
+			atomic.AddUint64(&appender.dropped, 1)
+			return 0, ErrAsyncQueueFull
+		}
+
+	default:
+		appender.queue <- event
+	}
+
+	return len(data), nil
+}
+
+// tryEnqueue
+func (appender *AsyncAppender) tryEnqueue(event asyncEvent) bool {
+	select {
+	case appender.queue <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// Dropped returns the number of events dropped by the overflow policy
+func (appender *AsyncAppender) Dropped() uint64 {
+	return atomic.LoadUint64(&appender.dropped)
+}
+
+// Flush waits until all events queued before the call are written.
+// If the wrapped appender has Flush() error, it is also called.
+func (appender *AsyncAppender) Flush(ctx context.Context) error {
+	appender.mu.RLock()
+	if appender.closed {
+		appender.mu.RUnlock()
+		return ErrAsyncAppenderClosed
+	}
+
+	flushed := make(chan struct{})
+	select {
+	case appender.queue <- asyncEvent{flush: flushed}:
+		appender.mu.RUnlock()
+	case <-ctx.Done():
+		appender.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close implements io.Closer.
+// Close writes all queued events and closes the wrapped appender.
+func (appender *AsyncAppender) Close() error {
+	appender.mu.Lock()
+	if appender.closed {
+		appender.mu.Unlock()
+		return nil
+	}
+	appender.closed = true
+	close(appender.queue)
+	appender.mu.Unlock()
+
+	<-appender.done
+	return appender.appender.Close()
+}
+
+// run writes queued events to the wrapped appender
+func (appender *AsyncAppender) run() {
+	defer close(appender.done)
+
+	for {
+		select {
+		case event, ok := <-appender.queue:
+			if !ok {
+				appender.drainFlushes()
+				return
+			}
+			if event.flush != nil {
+				appender.flush(event.flush)
+				continue
+			}
+			appender.write(event)
+		case flushed := <-appender.flushes:
+			appender.flush(flushed)
+		}
+	}
+}
+
+// drainFlushes completes the flush requests left after the queue is closed
+func (appender *AsyncAppender) drainFlushes() {
+	for {
+		select {
+		case flushed := <-appender.flushes:
+			appender.flush(flushed)
+		default:
+			return
+		}
+	}
+}
+
+// flush flushes the wrapped appender and completes the flush request
+func (appender *AsyncAppender) flush(flushed chan struct{}) {
+	if flusher, ok := appender.appender.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			warnLogger.Warnf("flush appender is failed , error : %s", err.Error())
+		}
+	}
+	close(flushed)
+}
+
+// write writes event to the wrapped appender
+func (appender *AsyncAppender) write(event asyncEvent) {
+	var err error
+	if levelWriter, ok := appender.appender.(LevelWriter); ok {
+		_, err = levelWriter.WriteLevel(event.level, event.data)
+	} else {
+		_, err = appender.appender.Write(event.data)
+	}
+	if err != nil {
+		warnLogger.Warnf("async append is failed , error : %s", err.Error())
+	}
+}
@@ -0,0 +1,217 @@
+package golog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedAppender blocks Write until the gate is opened
+type gatedAppender struct {
+	gate    chan struct{}
+	mu      sync.Mutex
+	written []string
+	flushed int
+	closed  bool
+}
+
+func newGatedAppender() *gatedAppender {
+	return &gatedAppender{gate: make(chan struct{})}
+}
+
+func (appender *gatedAppender) Write(data []byte) (n int, err error) {
+	<-appender.gate
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	appender.written = append(appender.written, string(data))
+	return len(data), nil
+}
+
+func (appender *gatedAppender) Flush() error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	appender.flushed++
+	return nil
+}
+
+func (appender *gatedAppender) Close() error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	appender.closed = true
+	return nil
+}
+
+func (appender *gatedAppender) events() []string {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	return append([]string(nil), appender.written...)
+}
+
+// fillQueue writes "first" which is taken by the background goroutine and blocked,
+// then fills the queue
+func fillQueue(t *testing.T, appender *AsyncAppender, data ...string) {
+	appender.Write([]byte("first"))
+	assert.Eventually(t, func() bool { return len(appender.queue) == 0 }, time.Second, time.Millisecond)
+	for _, d := range data {
+		appender.Write([]byte(d))
+	}
+}
+
+func TestAsyncAppender_Write(t *testing.T) {
+
+	t.Run("events are written in background and drained on Close", func(t *testing.T) {
+		inner := newGatedAppender()
+		close(inner.gate)
+		appender := NewAsyncAppender(inner)
+
+		appender.Write([]byte("test1"))
+		appender.Write([]byte("test2"))
+		assert.Nil(t, appender.Close())
+
+		assert.Equal(t, []string{"test1", "test2"}, inner.events())
+		assert.True(t, inner.closed)
+	})
+
+	t.Run("written data is copied", func(t *testing.T) {
+		inner := newGatedAppender()
+		appender := NewAsyncAppender(inner)
+
+		data := []byte("test")
+		appender.Write(data)
+		copy(data, "xxxx")
+		close(inner.gate)
+		appender.Close()
+
+		assert.Equal(t, []string{"test"}, inner.events())
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		inner := newGatedAppender()
+		appender := NewAsyncAppender(inner, WithAsyncQueueSize(1), WithAsyncOverflowPolicy(OverflowPolicy_DROP_NEWEST))
+
+		fillQueue(t, appender, "test1")
+		_, err := appender.Write([]byte("test2"))
+		assert.Equal(t, ErrAsyncQueueFull, err)
+
+		close(inner.gate)
+		appender.Close()
+		assert.Equal(t, []string{"first", "test1"}, inner.events())
+		assert.Equal(t, uint64(1), appender.Dropped())
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		inner := newGatedAppender()
+		appender := NewAsyncAppender(inner, WithAsyncQueueSize(1), WithAsyncOverflowPolicy(OverflowPolicy_DROP_OLDEST))
+
+		fillQueue(t, appender, "test1")
+		_, err := appender.Write([]byte("test2"))
+		assert.Nil(t, err)
+
+		close(inner.gate)
+		appender.Close()
+		assert.Equal(t, []string{"first", "test2"}, inner.events())
+		assert.Equal(t, uint64(1), appender.Dropped())
+	})
+
+	t.Run("drop below level", func(t *testing.T) {
+		inner := newGatedAppender()
+		appender := NewAsyncAppender(inner,
+			WithAsyncQueueSize(1),
+			WithAsyncOverflowPolicy(OverflowPolicy_DROP_BELOW_LEVEL),
+			WithAsyncDiscardLevel(LogLevel_ERROR))
+
+		fillQueue(t, appender, "test1")
+		_, err := appender.WriteLevel(LogLevel_INFO, []byte("info"))
+		assert.Equal(t, ErrAsyncQueueFull, err)
+
+		written := make(chan struct{})
+		go func() {
+			appender.WriteLevel(LogLevel_ERROR, []byte("error"))
+			close(written)
+		}()
+
+		close(inner.gate)
+		<-written
+		appender.Close()
+		assert.Equal(t, []string{"first", "test1", "error"}, inner.events())
+		assert.Equal(t, uint64(1), appender.Dropped())
+	})
+
+	t.Run("returns error after Close", func(t *testing.T) {
+		inner := newGatedAppender()
+		close(inner.gate)
+		appender := NewAsyncAppender(inner)
+		appender.Close()
+
+		_, err := appender.Write([]byte("test"))
+		assert.Equal(t, ErrAsyncAppenderClosed, err)
+	})
+}
+
+func TestAsyncAppender_Flush(t *testing.T) {
+
+	t.Run("waits until queued events are written", func(t *testing.T) {
+		inner := newGatedAppender()
+		close(inner.gate)
+		appender := NewAsyncAppender(inner)
+		defer appender.Close()
+
+		appender.Write([]byte("test1"))
+		assert.Nil(t, appender.Flush(context.Background()))
+		assert.Equal(t, []string{"test1"}, inner.events())
+		assert.Equal(t, 1, inner.flushed)
+	})
+
+	t.Run("returns error if context is done", func(t *testing.T) {
+		inner := newGatedAppender()
+		appender := NewAsyncAppender(inner)
+
+		appender.Write([]byte("test1"))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, appender.Flush(ctx))
+
+		close(inner.gate)
+		appender.Close()
+	})
+
+	t.Run("drop oldest does not move the flush request behind later events", func(t *testing.T) {
+		inner := newGatedAppender()
+		appender := NewAsyncAppender(inner, WithAsyncQueueSize(2), WithAsyncOverflowPolicy(OverflowPolicy_DROP_OLDEST))
+
+		fillQueue(t, appender, "test1")
+		flushed := make(chan error, 1)
+		go func() {
+			flushed <- appender.Flush(context.Background())
+		}()
+		assert.Eventually(t, func() bool { return len(appender.queue) == 2 }, time.Second, time.Millisecond)
+
+		// test1 is dropped, and then the flush request is taken from the head of the queue
+		appender.Write([]byte("test2"))
+		appender.Write([]byte("test3"))
+		assert.Equal(t, uint64(1), appender.Dropped())
+		assert.Len(t, appender.flushes, 1)
+		assert.Len(t, appender.queue, 2)
+
+		close(inner.gate)
+		assert.Nil(t, <-flushed)
+		appender.Close()
+		assert.Equal(t, []string{"first", "test2", "test3"}, inner.events())
+	})
+}
+
+func TestAsyncAppender_Logger(t *testing.T) {
+	inner := newGatedAppender()
+	close(inner.gate)
+	appender := NewAsyncAppender(inner)
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	logger.DisableLogEventMetadata()
+
+	logger.Info("message")
+	logger.Close()
+
+	assert.Equal(t, []string{"message"}, inner.events())
+}
@@ -7,25 +7,20 @@ import (
 
 // ByteBufferAppender
 type ByteBufferAppender struct {
-	bufferPool *sync.Pool
+	buffer *bytes.Buffer
+	mu     *sync.Mutex
 }
 
 // Write implements Appender interface
 func (appender *ByteBufferAppender) Write(data []byte) (n int, err error) {
 
 	if appender != nil {
-
-		// get buffer from bufferPool
-		buffer := appender.bufferPool.Get().(*bytes.Buffer)
-
-		// release
-		defer func() {
-			appender.bufferPool.Put(buffer)
-		}()
+		appender.mu.Lock()
+		defer appender.mu.Unlock()
 
 		// write
-		buffer.Write(data)
-		buffer.WriteString("\n")
+		appender.buffer.Write(data)
+		appender.buffer.WriteString("\n")
 	}
 
 	return 0, nil
@@ -40,8 +35,9 @@ func (appender *ByteBufferAppender) Close() error {
 func (appender *ByteBufferAppender) String() string {
 
 	if appender != nil {
-		buffer := appender.bufferPool.Get().(*bytes.Buffer)
-		return buffer.String()
+		appender.mu.Lock()
+		defer appender.mu.Unlock()
+		return appender.buffer.String()
 	}
 
 	return ""
@@ -50,13 +46,8 @@ func (appender *ByteBufferAppender) String() string {
 // NewByteBufferAppender
 func NewByteBufferAppender() *ByteBufferAppender {
 
-	bufferPool := &sync.Pool{
-		New: func() interface{} {
-			return new(bytes.Buffer)
-		},
-	}
-
 	return &ByteBufferAppender{
-		bufferPool: bufferPool,
+		buffer: new(bytes.Buffer),
+		mu:     new(sync.Mutex),
 	}
 }
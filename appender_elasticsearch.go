@@ -0,0 +1,265 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultElasticsearchIndex
+const defaultElasticsearchIndex = "logs-{loggerName}-{date}"
+
+// defaultElasticsearchDateLayout
+const defaultElasticsearchDateLayout = "2006.01.02"
+
+// defaultElasticsearchLoggerName is used in index names for events without logger name
+const defaultElasticsearchLoggerName = "default"
+
+// ElasticsearchAppender writes events to Elasticsearch or OpenSearch by the bulk API.
+//
+// Each event is a document. JSON events, e.g. JsonLogEvent with the JSON metadata, are indexed as they are,
+// and other events are indexed as {"message": ...}. "@timestamp" is added if the document does not have it.
+// Documents rejected with 429 or 5xx in a bulk response are retried, and other rejected documents are reported by warnLogger.
+// Batching and retries are done by HTTPAppender.
+type ElasticsearchAppender struct {
+	index       string
+	dateLayout  string
+	dataStream  bool
+	httpOptions []HTTPOption
+
+	http *HTTPAppender
+}
+
+// ElasticsearchOption
+type ElasticsearchOption func(appender *ElasticsearchAppender)
+
+// WithElasticsearchIndex sets the index name.
+// "{loggerName}" is replaced by the logger name and "{date}" by the date of the event in UTC.
+// The default is "logs-{loggerName}-{date}", e.g. "logs-sample-2018.05.07".
+func WithElasticsearchIndex(index string) ElasticsearchOption {
+	return func(appender *ElasticsearchAppender) {
+		if index != "" {
+			appender.index = index
+		}
+	}
+}
+
+// WithElasticsearchDateLayout sets the layout of "{date}". The default is "2006.01.02".
+func WithElasticsearchDateLayout(layout string) ElasticsearchOption {
+	return func(appender *ElasticsearchAppender) {
+		if layout != "" {
+			appender.dateLayout = layout
+		}
+	}
+}
+
+// WithElasticsearchDataStream writes documents to the data stream by "create" actions.
+// "{loggerName}" in name is replaced by the logger name, e.g. "logs-{loggerName}-default".
+func WithElasticsearchDataStream(name string) ElasticsearchOption {
+	return func(appender *ElasticsearchAppender) {
+		appender.index = name
+		appender.dataStream = true
+	}
+}
+
+// WithElasticsearchHTTPOptions sets options of the underlying HTTPAppender, e.g. batching, retries and authentication
+func WithElasticsearchHTTPOptions(options ...HTTPOption) ElasticsearchOption {
+	return func(appender *ElasticsearchAppender) {
+		appender.httpOptions = append(appender.httpOptions, options...)
+	}
+}
+
+// NewElasticsearchAppender returns new ElasticsearchAppender.
+//
+// endpoint is the url of Elasticsearch, e.g. "http://localhost:9200".
+// If the path is empty, "/_bulk" is used.
+func NewElasticsearchAppender(endpoint string, options ...ElasticsearchOption) (*ElasticsearchAppender, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpointURL.Path == "" || endpointURL.Path == "/" {
+		endpointURL.Path = "/_bulk"
+	}
+
+	appender := &ElasticsearchAppender{
+		index:      defaultElasticsearchIndex,
+		dateLayout: defaultElasticsearchDateLayout,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	httpOptions := append(appender.httpOptions,
+		WithHTTPBodyBuilder(HTTPBodyBuilderFunc(appender.build)),
+		func(httpAppender *HTTPAppender) {
+			httpAppender.responseHandler = handleElasticsearchBulkResponse
+		})
+
+	if appender.http, err = NewHTTPAppender(endpointURL.String(), httpOptions...); err != nil {
+		return nil, fmt.Errorf("golog: invalid elasticsearch endpoint %q", endpoint)
+	}
+	return appender, nil
+}
+
+// Write implements io.Writer. The event is written with info level.
+func (appender *ElasticsearchAppender) Write(data []byte) (n int, err error) {
+	return appender.http.WriteLevel(LogLevel_INFO, data)
+}
+
+// WriteLevel implements LevelWriter
+func (appender *ElasticsearchAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	return appender.http.WriteLevel(level, data)
+}
+
+// WriteEvent implements EventWriter. The event is encoded as the document.
+func (appender *ElasticsearchAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	return appender.http.writeEvent(level, metadata, event)
+}
+
+// Flush writes queued events and waits until the requests finish
+func (appender *ElasticsearchAppender) Flush() error {
+	return appender.http.Flush()
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (appender *ElasticsearchAppender) Dropped() uint64 {
+	return appender.http.Dropped()
+}
+
+// Close implements io.Closer
+func (appender *ElasticsearchAppender) Close() error {
+	return appender.http.Close()
+}
+
+// build implements HTTPBodyBuilder
+func (appender *ElasticsearchAppender) build(events []HTTPEvent) ([]byte, string, error) {
+	action := "index"
+	if appender.dataStream {
+		action = "create"
+	}
+
+	var body []byte
+	for _, event := range events {
+		meta, err := json.Marshal(map[string]map[string]string{action: {"_index": appender.indexName(event)}})
+		if err != nil {
+			return nil, "", err
+		}
+		document, err := elasticsearchDocument(event)
+		if err != nil {
+			return nil, "", err
+		}
+
+		body = append(body, meta...)
+		body = append(body, '\n')
+		body = append(body, document...)
+		body = append(body, '\n')
+	}
+	return body, "application/x-ndjson", nil
+}
+
+// indexName returns the index of event
+func (appender *ElasticsearchAppender) indexName(event HTTPEvent) string {
+	loggerName := event.LoggerName
+	if loggerName == "" {
+		loggerName = defaultElasticsearchLoggerName
+	}
+
+	index := strings.ReplaceAll(appender.index, "{loggerName}", elasticsearchIndexPart(loggerName))
+	if !appender.dataStream {
+		index = strings.ReplaceAll(index, "{date}", event.Time.UTC().Format(appender.dateLayout))
+	}
+	return index
+}
+
+// elasticsearchIndexPart converts s to lowercase and replaces characters not allowed in index names
+func elasticsearchIndexPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '"', '*', '\\', '<', '>', '|', ',', '/', '?', '#', ':':
+			return '_'
+		default:
+			return r
+		}
+	}, strings.ToLower(s))
+}
+
+// elasticsearchDocument returns the JSON object of event with "@timestamp"
+func elasticsearchDocument(event HTTPEvent) ([]byte, error) {
+	timestamp := event.Time.UTC().Format(time.RFC3339Nano)
+	data := bytes.TrimSpace(event.Data)
+
+	var object map[string]json.RawMessage
+	if len(data) > 0 && data[0] == '{' && json.Unmarshal(data, &object) == nil {
+		if _, ok := object["@timestamp"]; ok {
+			return data, nil
+		}
+
+		document := []byte(`{"@timestamp":"` + timestamp + `"`)
+		if rest := bytes.TrimSpace(data[1:]); len(rest) > 0 && rest[0] != '}' {
+			document = append(document, ',')
+		}
+		return append(document, data[1:]...), nil
+	}
+
+	return json.Marshal(map[string]string{
+		"@timestamp": timestamp,
+		"log.level":  strings.ToLower(otlpSeverityText(event.Level)),
+		"message":    string(data),
+	})
+}
+
+// elasticsearchBulkResponse is the response of the bulk API
+type elasticsearchBulkResponse struct {
+	Errors bool                               `json:"errors"`
+	Items  []map[string]elasticsearchBulkItem `json:"items"`
+}
+
+// elasticsearchBulkItem is the result of an action
+type elasticsearchBulkItem struct {
+	Index  string          `json:"_index"`
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// handleElasticsearchBulkResponse returns documents rejected with 429 or 5xx,
+// and reports the other rejected documents by warnLogger
+func handleElasticsearchBulkResponse(batch []HTTPEvent, body []byte) ([]HTTPEvent, error) {
+	var response elasticsearchBulkResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("golog: invalid bulk response : %v", err)
+	}
+
+	if !response.Errors {
+		return nil, nil
+	}
+
+	if len(response.Items) != len(batch) {
+		return nil, fmt.Errorf("golog: bulk response has %d items for %d documents", len(response.Items), len(batch))
+	}
+
+	var retryEvents []HTTPEvent
+	var retryError string
+	for i, actions := range response.Items {
+		for _, item := range actions {
+			switch {
+			case item.Status/100 == 2:
+			case item.Status == 429 || item.Status/100 == 5:
+				retryEvents = append(retryEvents, batch[i])
+				retryError = string(item.Error)
+			default:
+				warnLogger.Warnf("golog: document is rejected by %s , status : %d , error : %s", item.Index, item.Status, string(item.Error))
+			}
+		}
+	}
+
+	if len(retryEvents) == 0 {
+		return nil, nil
+	}
+	return retryEvents, fmt.Errorf("golog: %d of %d documents are failed : %s", len(retryEvents), len(batch), retryError)
+}
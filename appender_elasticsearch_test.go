@@ -0,0 +1,219 @@
+package golog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBulkDocument is a document received by fakeBulk
+type fakeBulkDocument struct {
+	action   string
+	index    string
+	document map[string]interface{}
+}
+
+// fakeBulk is a bulk endpoint. status returns the status of each document.
+type fakeBulk struct {
+	*httptest.Server
+	mu        sync.Mutex
+	path      string
+	requests  int
+	documents []fakeBulkDocument
+	status    func(document fakeBulkDocument) int
+}
+
+func newFakeBulk(t *testing.T) *fakeBulk {
+	bulk := &fakeBulk{status: func(fakeBulkDocument) int { return http.StatusCreated }}
+	bulk.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bulk.mu.Lock()
+		defer bulk.mu.Unlock()
+		bulk.path = r.URL.Path
+		bulk.requests++
+
+		var items []string
+		errors := false
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var meta map[string]map[string]string
+			if err := json.Unmarshal(scanner.Bytes(), &meta); err != nil || !scanner.Scan() {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var document fakeBulkDocument
+			if err := json.Unmarshal(scanner.Bytes(), &document.document); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for action, params := range meta {
+				document.action = action
+				document.index = params["_index"]
+			}
+
+			status := bulk.status(document)
+			if status/100 == 2 {
+				bulk.documents = append(bulk.documents, document)
+				items = append(items, fmt.Sprintf(`{%q:{"_index":%q,"status":%d}}`, document.action, document.index, status))
+			} else {
+				errors = true
+				items = append(items, fmt.Sprintf(`{%q:{"_index":%q,"status":%d,"error":{"type":"error"}}}`, document.action, document.index, status))
+			}
+		}
+
+		fmt.Fprintf(w, `{"took":1,"errors":%t,"items":[%s]}`, errors, strings.Join(items, ","))
+	}))
+	return bulk
+}
+
+func (bulk *fakeBulk) received() []fakeBulkDocument {
+	bulk.mu.Lock()
+	defer bulk.mu.Unlock()
+	return append([]fakeBulkDocument(nil), bulk.documents...)
+}
+
+func TestElasticsearchAppender(t *testing.T) {
+	bulk := newFakeBulk(t)
+	defer bulk.Close()
+
+	appender, err := NewElasticsearchAppender(bulk.URL)
+	assert.Nil(t, err)
+
+	now := time.Date(2018, 5, 7, 12, 14, 20, 0, time.UTC)
+	appender.http.now = func() time.Time { return now }
+
+	logger := NewLogger("Sample Logger", LogLevel_INFO, appender)
+	logger.SetMetadataConfig(&MetadataConfig{IsEnabledLoggerName: true})
+	logger.Infoj(map[string]string{"message": "json"})
+	appender.WriteLevel(LogLevel_WARN, []byte("text\n"))
+	appender.Write([]byte(`{"@timestamp":"2018-01-01T00:00:00Z","message":"timestamp"}`))
+	assert.Nil(t, appender.Close())
+
+	assert.Equal(t, "/_bulk", bulk.path)
+	documents := bulk.received()
+	assert.Len(t, documents, 3)
+
+	assert.Equal(t, "index", documents[0].action)
+	assert.Equal(t, "logs-sample_logger-2018.05.07", documents[0].index)
+	assert.Equal(t, "2018-05-07T12:14:20Z", documents[0].document["@timestamp"])
+	assert.Equal(t, map[string]interface{}{"message": "json"}, documents[0].document["EventData"])
+	assert.Equal(t, "Sample Logger", documents[0].document["loggerName"])
+
+	assert.Equal(t, "logs-default-2018.05.07", documents[1].index)
+	assert.Equal(t, map[string]interface{}{"@timestamp": "2018-05-07T12:14:20Z", "log.level": "warn", "message": "text"}, documents[1].document)
+
+	assert.Equal(t, "2018-01-01T00:00:00Z", documents[2].document["@timestamp"])
+}
+
+func TestElasticsearchAppender_EventTime(t *testing.T) {
+	bulk := newFakeBulk(t)
+	defer bulk.Close()
+
+	appender, err := NewElasticsearchAppender(bulk.URL)
+	assert.Nil(t, err)
+	appender.http.now = func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	metadata := newPatternTestMetadata()
+	metadata.UnixTime = time.Date(2018, 5, 7, 23, 59, 59, 0, time.UTC).Unix()
+	assert.Nil(t, appender.WriteEvent(LogLevel_INFO, metadata, newJsonLogEvent(map[string]string{"message": "json"}, nil)))
+	assert.Nil(t, appender.Close())
+
+	documents := bulk.received()
+	assert.Len(t, documents, 1)
+	assert.Equal(t, "logs-app.db.pool-2018.05.07", documents[0].index)
+	assert.Equal(t, "2018-05-07T23:59:59.123456789Z", documents[0].document["@timestamp"])
+}
+
+func TestElasticsearchAppender_DataStream(t *testing.T) {
+	bulk := newFakeBulk(t)
+	defer bulk.Close()
+
+	appender, err := NewElasticsearchAppender(bulk.URL+"/custom/_bulk", WithElasticsearchDataStream("logs-{loggerName}-default"))
+	assert.Nil(t, err)
+
+	appender.Write([]byte(`{}`))
+	assert.Nil(t, appender.Close())
+
+	assert.Equal(t, "/custom/_bulk", bulk.path)
+	documents := bulk.received()
+	assert.Len(t, documents, 1)
+	assert.Equal(t, "create", documents[0].action)
+	assert.Equal(t, "logs-default-default", documents[0].index)
+	assert.Len(t, documents[0].document, 1)
+}
+
+func TestElasticsearchAppender_Index(t *testing.T) {
+	appender := &ElasticsearchAppender{index: "app-{date}", dateLayout: "2006.01"}
+	// the date is in UTC
+	event := HTTPEvent{Time: time.Date(2018, 6, 1, 5, 0, 0, 0, time.FixedZone("JST", 9*60*60))}
+	assert.Equal(t, "app-2018.05", appender.indexName(event))
+}
+
+func TestElasticsearchAppender_Retry(t *testing.T) {
+	bulk := newFakeBulk(t)
+	defer bulk.Close()
+
+	attempts := map[string]int{}
+	bulk.status = func(document fakeBulkDocument) int {
+		message := document.document["message"].(string)
+		attempts[message]++
+		switch {
+		case message == "retry" && attempts[message] == 1:
+			return http.StatusTooManyRequests
+		case message == "invalid":
+			return http.StatusBadRequest
+		default:
+			return http.StatusCreated
+		}
+	}
+
+	appender, err := NewElasticsearchAppender(bulk.URL,
+		WithElasticsearchHTTPOptions(WithHTTPRetry(3, time.Millisecond, time.Millisecond)))
+	assert.Nil(t, err)
+
+	appender.Write([]byte("ok"))
+	appender.Write([]byte("retry"))
+	appender.Write([]byte("invalid"))
+	appender.Flush()
+	assert.Nil(t, appender.Close())
+
+	// only the document rejected with 429 is retried
+	assert.Equal(t, 2, bulk.requests)
+	assert.Equal(t, map[string]int{"ok": 1, "retry": 2, "invalid": 1}, attempts)
+
+	var messages []string
+	for _, document := range bulk.received() {
+		messages = append(messages, document.document["message"].(string))
+	}
+	assert.Equal(t, []string{"ok", "retry"}, messages)
+}
+
+func TestHandleElasticsearchBulkResponse(t *testing.T) {
+	batch := []HTTPEvent{{Data: []byte("a")}, {Data: []byte("b")}}
+
+	t.Run("no errors", func(t *testing.T) {
+		retryEvents, err := handleElasticsearchBulkResponse(batch, []byte(`{"errors":false,"items":[]}`))
+		assert.Nil(t, retryEvents)
+		assert.Nil(t, err)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		retryEvents, err := handleElasticsearchBulkResponse(batch,
+			[]byte(`{"errors":true,"items":[{"index":{"status":503}},{"index":{"status":201}}]}`))
+		assert.Equal(t, batch[:1], retryEvents)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		retryEvents, err := handleElasticsearchBulkResponse(batch, []byte(`{"errors":true,"items":[]}`))
+		assert.Nil(t, retryEvents)
+		assert.NotNil(t, err)
+	})
+}
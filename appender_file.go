@@ -19,34 +19,29 @@ type FileAppender struct {
 
 // NewFileAppender returns new FileAppender
 func NewFileAppender(fileName string) (asyncFileAppender *FileAppender, err error) {
-	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		return nil, err
-	}
-
-	return &FileAppender{
-		file:           file,
-		bufferedWriter: newBufferedWriter(file, withBufferSize(defaultBufferSize)),
-		mu:             new(sync.Mutex),
-		activated:      true,
-	}, nil
+	return openFileAppender(fileName, defaultBufferSize)
 }
 
 // NewFileAppender returns new FileAppender
 func NewFileAppenderWithBufferSize(fileName string, bufferSize int) (asyncFileAppender *FileAppender, err error) {
-	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		return nil, err
-	}
-
 	size := defaultBufferSize
 	if bufferSize > 0 {
 		size = bufferSize
 	}
 
+	return openFileAppender(fileName, size)
+}
+
+// openFileAppender opens fileName with O_APPEND
+func openFileAppender(fileName string, bufferSize int) (*FileAppender, error) {
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+
 	return &FileAppender{
 		file:           file,
-		bufferedWriter: newBufferedWriter(file, withBufferSize(size)),
+		bufferedWriter: newBufferedWriter(file, withBufferSize(bufferSize)),
 		mu:             new(sync.Mutex),
 		activated:      true,
 	}, nil
@@ -64,8 +59,8 @@ func (appender *FileAppender) Write(data []byte) (n int, err error) {
 func (appender *FileAppender) Close() error {
 	appender.mu.Lock()
 	defer func() {
-		appender.mu.Unlock()
 		appender.activated = false
+		appender.mu.Unlock()
 	}()
 
 	if appender.activated {
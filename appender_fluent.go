@@ -1,13 +1,458 @@
 package golog
 
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultFluentBufferSize
+const defaultFluentBufferSize = 8 * 1024
+
+// defaultFluentFlushInterval
+const defaultFluentFlushInterval = time.Second
+
+// defaultFluentTimeout
+const defaultFluentTimeout = 3 * time.Second
+
+// maxFluentBufferFactor limits buffered events while the server is unreachable
+const maxFluentBufferFactor = 4
+
+// defaultFluentRecordKey
+const defaultFluentRecordKey = "message"
+
+// ErrFluentAckMismatch is returned when the server acknowledges an unexpected chunk
+var ErrFluentAckMismatch = errors.New("golog: fluent ack does not match chunk id")
+
+// FluentAppender sends events to fluentd / fluent-bit by the Forward protocol.
+//
+// Events are buffered and sent as PackedForward messages
+// ([tag, entries, option]) when the buffer is full, every flush interval or on Close.
+// Buffered events are sent on a background goroutine, so Write does not wait for the server.
+// While the server is unreachable, buffered events are dropped when they exceed
+// maxFluentBufferFactor times the buffer size.
+//
+// If the buffer size is zero, each event is sent immediately
+// as a Message mode entry ([tag, time, record, option]), and Write blocks until it is sent.
 type FluentAppender struct {
+	tag       string
+	network   string
+	address   string
+	recordKey string
+
+	bufferSize    int
+	flushInterval time.Duration
+	timeout       time.Duration
+	requireAck bool
+	dial       func(network, address string, timeout time.Duration) (net.Conn, error)
+
+	// conn, reader and packet are guarded by sendMu
+	conn   net.Conn
+	reader *bufio.Reader
+	packet []byte
+	sendMu *sync.Mutex
+
+	// entries, count and spare are guarded by mu
+	entries []byte
+	count   int
+	spare   []byte
+	dropped uint64
+
+	flushCh chan struct{}
+	done    chan struct{}
+	wg      *sync.WaitGroup
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// FluentOption
+type FluentOption func(appender *FluentAppender)
+
+// WithFluentBufferSize sets the number of bytes buffered before sending.
+// Zero disables buffering.
+func WithFluentBufferSize(size int) FluentOption {
+	return func(appender *FluentAppender) {
+		if size < 0 {
+			size = defaultFluentBufferSize
+		}
+		appender.bufferSize = size
+	}
+}
+
+// WithFluentFlushInterval sets the interval at which buffered events are sent.
+// Zero disables the time based flush.
+func WithFluentFlushInterval(interval time.Duration) FluentOption {
+	return func(appender *FluentAppender) {
+		if interval >= 0 {
+			appender.flushInterval = interval
+		}
+	}
+}
+
+// WithFluentTimeout sets dial, write and ack timeout
+func WithFluentTimeout(timeout time.Duration) FluentOption {
+	return func(appender *FluentAppender) {
+		if timeout > 0 {
+			appender.timeout = timeout
+		}
+	}
+}
+
+// WithFluentAck requests an ack response for each chunk (at-least-once delivery)
+func WithFluentAck() FluentOption {
+	return func(appender *FluentAppender) {
+		appender.requireAck = true
+	}
+}
+
+// WithFluentRecordKey sets the record key which holds the encoded event.
+// The default key is "message".
+func WithFluentRecordKey(key string) FluentOption {
+	return func(appender *FluentAppender) {
+		if key != "" {
+			appender.recordKey = key
+		}
+	}
+}
+
+// NewFluentAppender returns new FluentAppender.
+//
+// address is "host:port" or "tcp://host:port" for TCP,
+// "unix:///path/to/socket" for Unix domain socket.
+// The connection is established lazily and re-established after errors.
+func NewFluentAppender(tag string, address string, options ...FluentOption) (*FluentAppender, error) {
+	if tag == "" {
+		return nil, errors.New("golog: fluent tag is empty")
+	}
+
+	network, addr, err := parseFluentAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	appender := &FluentAppender{
+		tag:        tag,
+		network:    network,
+		address:    addr,
+		recordKey:  defaultFluentRecordKey,
+		bufferSize:    defaultFluentBufferSize,
+		flushInterval: defaultFluentFlushInterval,
+		timeout:       defaultFluentTimeout,
+		dial:          net.DialTimeout,
+		sendMu:        new(sync.Mutex),
+		flushCh:       make(chan struct{}, 1),
+		done:          make(chan struct{}),
+		wg:            new(sync.WaitGroup),
+		mu:            new(sync.Mutex),
+		activated:     true,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	if appender.bufferSize > 0 {
+		appender.wg.Add(1)
+		go appender.flushLoop()
+	}
+
+	return appender, nil
+}
+
+// parseFluentAddress
+func parseFluentAddress(address string) (network string, addr string, err error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		network, addr = "tcp", strings.TrimPrefix(address, "tcp://")
+	default:
+		network, addr = "tcp", address
+	}
+
+	if addr == "" {
+		return "", "", fmt.Errorf("golog: invalid fluent address %q", address)
+	}
+	return network, addr, nil
+}
+
+// Write implements io.Writer.
+// In buffered mode the event is only buffered, and errors of sending are reported by warnLogger.
+func (appender *FluentAppender) Write(data []byte) (n int, err error) {
+	if appender.bufferSize == 0 {
+		return appender.writeMessage(data)
+	}
+
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return 0, errors.New("golog: fluent appender is closed")
+	}
+
+	// entry : [time, record]
+	appender.entries = appendMsgpackArrayHeader(appender.entries, 2)
+	appender.entries = appendMsgpackEventTime(appender.entries, time.Now())
+	appender.entries = appender.appendRecord(appender.entries, data)
+	appender.count++
+
+	if len(appender.entries) >= appender.bufferSize {
+		select {
+		case appender.flushCh <- struct{}{}:
+		default:
+		}
+		appender.dropOverflow()
+	}
+
+	return len(data), nil
+}
+
+// writeMessage sends data as Message mode on the caller's goroutine
+func (appender *FluentAppender) writeMessage(data []byte) (int, error) {
+	appender.sendMu.Lock()
+	defer appender.sendMu.Unlock()
+
+	appender.mu.Lock()
+	activated := appender.activated
+	appender.mu.Unlock()
+	if !activated {
+		return 0, errors.New("golog: fluent appender is closed")
+	}
+
+	chunk := ""
+	if appender.requireAck {
+		chunk = newFluentChunkID()
+	}
+	appender.packet = appender.encodeMessage(appender.packet[:0], time.Now(), data, chunk)
+	if err := appender.send(appender.packet, chunk); err != nil {
+		return 0, err
+	}
+	return len(data), nil
 }
 
-func (FluentAppender) Write(data []byte) (n int, err error)  {
-	return 0, nil
+// dropOverflow drops buffered events if they exceed the limit, so that the buffer does not grow forever
+// while the server is unreachable. The caller must hold mu.
+func (appender *FluentAppender) dropOverflow() {
+	if len(appender.entries) < maxFluentBufferFactor*appender.bufferSize {
+		return
+	}
+
+	atomic.AddUint64(&appender.dropped, uint64(appender.count))
+	warnLogger.Warnf("golog: %d fluent events are dropped , buffer is full", appender.count)
+	appender.entries = appender.entries[:0]
+	appender.count = 0
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (appender *FluentAppender) Dropped() uint64 {
+	return atomic.LoadUint64(&appender.dropped)
+}
+
+// Flush sends buffered events
+func (appender *FluentAppender) Flush() error {
+	return appender.flush()
+}
+
+// flushLoop sends buffered events when the buffer is full and every flush interval
+func (appender *FluentAppender) flushLoop() {
+	defer appender.wg.Done()
+
+	var tick <-chan time.Time
+	if appender.flushInterval > 0 {
+		ticker := time.NewTicker(appender.flushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-appender.done:
+			return
+		case <-tick:
+		case <-appender.flushCh:
+		}
+
+		if err := appender.flush(); err != nil {
+			warnLogger.Warnf("golog: flush fluent events is failed , error : %s", err.Error())
+		}
+	}
+}
+
+// Close implements io.Closer
+func (appender *FluentAppender) Close() error {
+	appender.mu.Lock()
+	if !appender.activated {
+		appender.mu.Unlock()
+		return nil
+	}
+	appender.activated = false
+	close(appender.done)
+	appender.mu.Unlock()
+
+	// wait for flushLoop outside of mu
+	appender.wg.Wait()
+
+	err := appender.flush()
+
+	appender.sendMu.Lock()
+	defer appender.sendMu.Unlock()
+	if appender.conn != nil {
+		if closeErr := appender.conn.Close(); err == nil {
+			err = closeErr
+		}
+		appender.conn = nil
+	}
+	return err
+}
+
+// flush sends buffered entries as PackedForward.
+// The entries are taken out of the buffer, so that Write is not blocked while they are sent.
+// If sending fails, they are put back in front of the events written in the meantime.
+func (appender *FluentAppender) flush() error {
+	appender.sendMu.Lock()
+	defer appender.sendMu.Unlock()
+
+	appender.mu.Lock()
+	entries, count := appender.entries, appender.count
+	if count == 0 {
+		appender.mu.Unlock()
+		return nil
+	}
+	appender.entries, appender.count, appender.spare = appender.spare[:0], 0, nil
+	appender.mu.Unlock()
+
+	chunk := ""
+	if appender.requireAck {
+		chunk = newFluentChunkID()
+	}
+
+	// PackedForward : [tag, entries, option]
+	packet := appendMsgpackArrayHeader(appender.packet[:0], 3)
+	packet = appendMsgpackString(packet, appender.tag)
+	packet = appendMsgpackBinHeader(packet, len(entries))
+	packet = append(packet, entries...)
+	packet = appender.appendOption(packet, count, chunk)
+	appender.packet = packet
+
+	err := appender.send(packet, chunk)
+
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if err != nil {
+		written := appender.entries
+		appender.entries = append(entries, written...)
+		appender.count += count
+		appender.spare = written[:0]
+		appender.dropOverflow()
+		return err
+	}
+
+	appender.spare = entries[:0]
+	return nil
+}
+
+// encodeMessage encodes Message mode : [tag, time, record, option]
+func (appender *FluentAppender) encodeMessage(buf []byte, now time.Time, data []byte, chunk string) []byte {
+	buf = appendMsgpackArrayHeader(buf, 4)
+	buf = appendMsgpackString(buf, appender.tag)
+	buf = appendMsgpackEventTime(buf, now)
+	buf = appender.appendRecord(buf, data)
+	return appender.appendOption(buf, 1, chunk)
+}
+
+// appendRecord
+func (appender *FluentAppender) appendRecord(buf []byte, data []byte) []byte {
+	buf = appendMsgpackMapHeader(buf, 1)
+	buf = appendMsgpackString(buf, appender.recordKey)
+	return appendMsgpackStringBytes(buf, data)
+}
+
+// appendOption
+func (appender *FluentAppender) appendOption(buf []byte, size int, chunk string) []byte {
+	if chunk == "" {
+		buf = appendMsgpackMapHeader(buf, 1)
+	} else {
+		buf = appendMsgpackMapHeader(buf, 2)
+		buf = appendMsgpackString(buf, "chunk")
+		buf = appendMsgpackString(buf, chunk)
+	}
+	buf = appendMsgpackString(buf, "size")
+	return appendMsgpackInt(buf, int64(size))
+}
+
+// send writes packet and waits for ack if chunk is specified.
+// On failure the connection is re-established and the packet is retried once.
+// The caller must hold sendMu.
+func (appender *FluentAppender) send(packet []byte, chunk string) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = appender.connect(); err != nil {
+			continue
+		}
+
+		if err = appender.writeAndAck(packet, chunk); err == nil {
+			return nil
+		}
+
+		appender.conn.Close()
+		appender.conn = nil
+	}
+	return err
 }
 
+// connect
+func (appender *FluentAppender) connect() error {
+	if appender.conn != nil {
+		return nil
+	}
+
+	conn, err := appender.dial(appender.network, appender.address, appender.timeout)
+	if err != nil {
+		return err
+	}
 
-func (FluentAppender) Close() error {
+	appender.conn = conn
+	appender.reader = bufio.NewReader(conn)
 	return nil
 }
+
+// writeAndAck
+func (appender *FluentAppender) writeAndAck(packet []byte, chunk string) error {
+	appender.conn.SetDeadline(time.Now().Add(appender.timeout))
+
+	if _, err := appender.conn.Write(packet); err != nil {
+		return err
+	}
+
+	if chunk == "" {
+		return nil
+	}
+
+	response, err := decodeMsgpack(appender.reader)
+	if err != nil {
+		return err
+	}
+
+	if m, ok := response.(map[string]interface{}); ok {
+		if ack, ok := m["ack"].(string); ok && ack == chunk {
+			return nil
+		}
+	}
+	return ErrFluentAckMismatch
+}
+
+// newFluentChunkID returns base64 encoded random 128bit id
+func newFluentChunkID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return base64.StdEncoding.EncodeToString(id)
+}
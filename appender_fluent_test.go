@@ -1 +1,230 @@
 package golog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeForwardServer accepts forward protocol messages and records them
+type fakeForwardServer struct {
+	listener net.Listener
+	messages chan []interface{}
+	ack      bool
+}
+
+func newFakeForwardServer(t *testing.T, network, address string, ack bool) *fakeForwardServer {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &fakeForwardServer{
+		listener: listener,
+		messages: make(chan []interface{}, 100),
+		ack:      ack,
+	}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (server *fakeForwardServer) serve() {
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for {
+				v, err := decodeMsgpack(reader)
+				if err != nil {
+					return
+				}
+				message := v.([]interface{})
+				server.messages <- message
+
+				if server.ack {
+					option := message[len(message)-1].(map[string]interface{})
+					conn.Write(appendMsgpackString(appendMsgpackString(appendMsgpackMapHeader(nil, 1), "ack"), option["chunk"].(string)))
+				}
+			}
+		}(conn)
+	}
+}
+
+func (server *fakeForwardServer) receive(t *testing.T) []interface{} {
+	select {
+	case message := <-server.messages:
+		return message
+	case <-time.After(3 * time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+// decodeEntries decodes PackedForward entries
+func decodeEntries(entries []byte) [][]interface{} {
+	var decoded [][]interface{}
+	reader := bufio.NewReader(bytes.NewReader(entries))
+	for {
+		v, err := decodeMsgpack(reader)
+		if err != nil {
+			return decoded
+		}
+		decoded = append(decoded, v.([]interface{}))
+	}
+}
+
+func TestFluentAppender_Write(t *testing.T) {
+
+	t.Run("buffered events are sent as PackedForward on Close", func(t *testing.T) {
+		server := newFakeForwardServer(t, "tcp", "127.0.0.1:0", false)
+		appender, err := NewFluentAppender("app.access", server.listener.Addr().String(), WithFluentFlushInterval(time.Hour))
+		assert.Nil(t, err)
+
+		appender.Write([]byte("test1"))
+		appender.Write([]byte("test2"))
+		assert.Nil(t, appender.Close())
+
+		message := server.receive(t)
+		assert.Equal(t, "app.access", message[0])
+		entries := decodeEntries(message[1].([]byte))
+		assert.Len(t, entries, 2)
+		assert.Equal(t, int8(msgpackExtEventTime), entries[0][0].(msgpackExt).Type)
+		assert.Equal(t, map[string]interface{}{"message": "test1"}, entries[0][1])
+		assert.Equal(t, map[string]interface{}{"message": "test2"}, entries[1][1])
+		assert.Equal(t, int64(2), message[2].(map[string]interface{})["size"])
+	})
+
+	t.Run("events are flushed when buffer is full", func(t *testing.T) {
+		server := newFakeForwardServer(t, "tcp", "127.0.0.1:0", false)
+		appender, _ := NewFluentAppender("app", "tcp://"+server.listener.Addr().String(), WithFluentBufferSize(10))
+		defer appender.Close()
+
+		appender.Write([]byte("0123456789"))
+		message := server.receive(t)
+		assert.Len(t, decodeEntries(message[1].([]byte)), 1)
+	})
+
+	t.Run("events are flushed every flush interval", func(t *testing.T) {
+		server := newFakeForwardServer(t, "tcp", "127.0.0.1:0", false)
+		appender, _ := NewFluentAppender("app", server.listener.Addr().String(), WithFluentFlushInterval(10*time.Millisecond))
+		defer appender.Close()
+
+		appender.Write([]byte("test1"))
+		message := server.receive(t)
+		entries := decodeEntries(message[1].([]byte))
+		assert.Len(t, entries, 1)
+		assert.Equal(t, map[string]interface{}{"message": "test1"}, entries[0][1])
+	})
+
+	t.Run("unbuffered event is sent as Message mode", func(t *testing.T) {
+		server := newFakeForwardServer(t, "tcp", "127.0.0.1:0", false)
+		appender, _ := NewFluentAppender("app", server.listener.Addr().String(), WithFluentBufferSize(0), WithFluentRecordKey("log"))
+		defer appender.Close()
+
+		n, err := appender.Write([]byte("test"))
+		assert.Nil(t, err)
+		assert.Equal(t, 4, n)
+
+		message := server.receive(t)
+		assert.Len(t, message, 4)
+		assert.Equal(t, "app", message[0])
+		assert.Equal(t, map[string]interface{}{"log": "test"}, message[2])
+	})
+
+	t.Run("ack is awaited for each chunk", func(t *testing.T) {
+		server := newFakeForwardServer(t, "tcp", "127.0.0.1:0", true)
+		appender, _ := NewFluentAppender("app", server.listener.Addr().String(), WithFluentAck())
+
+		appender.Write([]byte("test"))
+		assert.Nil(t, appender.Flush())
+
+		message := server.receive(t)
+		assert.NotEmpty(t, message[2].(map[string]interface{})["chunk"])
+		assert.Nil(t, appender.Close())
+	})
+
+	t.Run("returns error if ack is not received", func(t *testing.T) {
+		server := newFakeForwardServer(t, "tcp", "127.0.0.1:0", false)
+		appender, _ := NewFluentAppender("app", server.listener.Addr().String(), WithFluentAck(), WithFluentTimeout(100*time.Millisecond))
+
+		appender.Write([]byte("test"))
+		assert.NotNil(t, appender.Flush())
+	})
+
+	t.Run("unix socket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "fluent.sock")
+		server := newFakeForwardServer(t, "unix", socket, false)
+		appender, _ := NewFluentAppender("app", "unix://"+socket)
+
+		appender.Write([]byte("test"))
+		assert.Nil(t, appender.Close())
+		assert.Equal(t, "app", server.receive(t)[0])
+	})
+
+	t.Run("reconnects after connection is lost", func(t *testing.T) {
+		server := newFakeForwardServer(t, "tcp", "127.0.0.1:0", false)
+		appender, _ := NewFluentAppender("app", server.listener.Addr().String(), WithFluentBufferSize(0))
+		defer appender.Close()
+
+		appender.Write([]byte("test1"))
+		server.receive(t)
+
+		appender.conn.Close()
+		_, err := appender.Write([]byte("test2"))
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"message": "test2"}, server.receive(t)[2])
+	})
+
+	t.Run("write is not blocked while sending, and overflowed events are dropped", func(t *testing.T) {
+		dialing := make(chan struct{}, 1)
+		release := make(chan struct{})
+		appender, _ := NewFluentAppender("app", "127.0.0.1:0", WithFluentBufferSize(10), WithFluentFlushInterval(0))
+		appender.dial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+			select {
+			case dialing <- struct{}{}:
+			default:
+			}
+			<-release
+			return nil, errors.New("unreachable")
+		}
+
+		// the first event is being sent
+		appender.Write([]byte("0123456789"))
+		<-dialing
+
+		// each entry is 31 bytes, so the second and third entries exceed 4 * 10 bytes
+		_, err := appender.Write([]byte("0123456789"))
+		assert.Nil(t, err)
+		_, err = appender.Write([]byte("0123456789"))
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), appender.Dropped())
+
+		close(release)
+		assert.NotNil(t, appender.Close())
+		assert.Equal(t, uint64(2), appender.Dropped())
+	})
+}
+
+func TestNewFluentAppender(t *testing.T) {
+
+	t.Run("returns error if tag is empty", func(t *testing.T) {
+		_, err := NewFluentAppender("", "127.0.0.1:24224")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("returns error if address is empty", func(t *testing.T) {
+		_, err := NewFluentAppender("app", "unix://")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,300 @@
+package golog
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GelfCompression is the compression of UDP messages
+type GelfCompression int
+
+// GelfCompression Constants
+const (
+	GelfCompression_GZIP GelfCompression = iota
+	GelfCompression_ZLIB
+	GelfCompression_NONE
+)
+
+// defaultGelfChunkSize is the max size of a UDP datagram, which fits in the MTU of most networks
+const defaultGelfChunkSize = 1420
+
+// gelf chunking parameters
+const (
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+)
+
+// defaultGelfTimeout
+const defaultGelfTimeout = 5 * time.Second
+
+// GelfAppender sends events to Graylog as GELF messages encoded by GelfLogEvent.
+//
+// Over UDP, messages are compressed and split into chunks if they are larger than the chunk size.
+// Over TCP and TLS, messages are terminated by a null byte and not compressed.
+// The connection is established lazily and re-established after errors.
+type GelfAppender struct {
+	network     string
+	address     string
+	host        string
+	fields      Fields
+	compression GelfCompression
+	chunkSize   int
+	tlsConfig   *tls.Config
+	timeout     time.Duration
+
+	conn net.Conn
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// GelfOption
+type GelfOption func(appender *GelfAppender)
+
+// WithGelfHost sets the host of messages. The default is the host name.
+func WithGelfHost(host string) GelfOption {
+	return func(appender *GelfAppender) {
+		appender.host = host
+	}
+}
+
+// WithGelfFields sets alternating keys and values of additional fields added to every message
+func WithGelfFields(keysAndValues ...interface{}) GelfOption {
+	return func(appender *GelfAppender) {
+		appender.fields = appender.fields.with(keysAndValues...)
+	}
+}
+
+// WithGelfCompression sets the compression of UDP messages. The default is GelfCompression_GZIP.
+func WithGelfCompression(compression GelfCompression) GelfOption {
+	return func(appender *GelfAppender) {
+		appender.compression = compression
+	}
+}
+
+// WithGelfChunkSize sets the max size of UDP datagrams. The default is 1420.
+func WithGelfChunkSize(size int) GelfOption {
+	return func(appender *GelfAppender) {
+		if size > gelfChunkHeaderSize {
+			appender.chunkSize = size
+		}
+	}
+}
+
+// WithGelfTLSConfig sets the TLS config of "tls://" addresses
+func WithGelfTLSConfig(config *tls.Config) GelfOption {
+	return func(appender *GelfAppender) {
+		appender.tlsConfig = config
+	}
+}
+
+// WithGelfTimeout sets dial and write timeout
+func WithGelfTimeout(timeout time.Duration) GelfOption {
+	return func(appender *GelfAppender) {
+		if timeout > 0 {
+			appender.timeout = timeout
+		}
+	}
+}
+
+// NewGelfAppender returns new GelfAppender.
+//
+// address is "udp://host:port", "tcp://host:port" or "tls://host:port". "host:port" is UDP.
+func NewGelfAppender(address string, options ...GelfOption) (*GelfAppender, error) {
+	network, addr := "udp", address
+	if i := strings.Index(address, "://"); i >= 0 {
+		network, addr = address[:i], address[i+3:]
+	}
+
+	switch network {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("golog: unsupported network %q", network)
+	}
+
+	if addr == "" {
+		return nil, fmt.Errorf("golog: invalid gelf address %q", address)
+	}
+
+	appender := &GelfAppender{
+		network:     network,
+		address:     addr,
+		compression: GelfCompression_GZIP,
+		chunkSize:   defaultGelfChunkSize,
+		timeout:     defaultGelfTimeout,
+		mu:          new(sync.Mutex),
+		activated:   true,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	return appender, nil
+}
+
+// Write implements io.Writer. The event is sent with info level.
+func (appender *GelfAppender) Write(data []byte) (n int, err error) {
+	return appender.WriteLevel(LogLevel_INFO, data)
+}
+
+// WriteLevel implements LevelWriter
+func (appender *GelfAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	event := TextLogEvent{Event: strings.TrimSuffix(string(data), "\n")}
+	if err := appender.WriteEvent(level, nil, event); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteEvent implements EventWriter
+func (appender *GelfAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	message := GelfLogEvent{Event: event, Level: level, Host: appender.host, Fields: appender.fields}.Encode(metadata)
+	return appender.send(message)
+}
+
+// Close implements io.Closer
+func (appender *GelfAppender) Close() error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return nil
+	}
+	appender.activated = false
+
+	if appender.conn != nil {
+		err := appender.conn.Close()
+		appender.conn = nil
+		return err
+	}
+	return nil
+}
+
+// send writes a message.
+// On failure the connection is re-established and the message is retried once.
+func (appender *GelfAppender) send(message []byte) error {
+	var packets [][]byte
+	if appender.network == "udp" {
+		compressed, err := appender.compress(message)
+		if err != nil {
+			return err
+		}
+		if packets, err = appender.chunk(compressed); err != nil {
+			return err
+		}
+	} else {
+		packets = [][]byte{append(message, 0)}
+	}
+
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return errors.New("golog: gelf appender is closed")
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = appender.connect(); err != nil {
+			continue
+		}
+
+		appender.conn.SetWriteDeadline(time.Now().Add(appender.timeout))
+		for _, packet := range packets {
+			if _, err = appender.conn.Write(packet); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			return nil
+		}
+
+		appender.conn.Close()
+		appender.conn = nil
+	}
+	return err
+}
+
+// connect. The caller must hold mu.
+func (appender *GelfAppender) connect() error {
+	if appender.conn != nil {
+		return nil
+	}
+
+	var conn net.Conn
+	var err error
+	if appender.network == "tls" {
+		dialer := &net.Dialer{Timeout: appender.timeout}
+		conn, err = tls.DialWithDialer(dialer, "tcp", appender.address, appender.tlsConfig)
+	} else {
+		conn, err = net.DialTimeout(appender.network, appender.address, appender.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("golog: connect to gelf is failed : %v", err)
+	}
+
+	appender.conn = conn
+	return nil
+}
+
+// compress
+func (appender *GelfAppender) compress(message []byte) ([]byte, error) {
+	switch appender.compression {
+	case GelfCompression_GZIP:
+		return gzipBytes(message)
+	case GelfCompression_ZLIB:
+		var buffer bytes.Buffer
+		writer := zlib.NewWriter(&buffer)
+		if _, err := writer.Write(message); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	default:
+		return message, nil
+	}
+}
+
+// chunk splits message into GELF chunks if it is larger than the chunk size.
+// Each chunk has the magic bytes 0x1e 0x0f, the message id of 8 bytes, the sequence number and the count.
+func (appender *GelfAppender) chunk(message []byte) ([][]byte, error) {
+	if len(message) <= appender.chunkSize {
+		return [][]byte{message}, nil
+	}
+
+	dataSize := appender.chunkSize - gelfChunkHeaderSize
+	count := (len(message) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("golog: gelf message of %d bytes is too large", len(message))
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * dataSize
+		if end > len(message) {
+			end = len(message)
+		}
+		chunk := make([]byte, 0, gelfChunkHeaderSize+end-i*dataSize)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunks = append(chunks, append(chunk, message[i*dataSize:end]...))
+	}
+	return chunks, nil
+}
@@ -0,0 +1,228 @@
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/tls"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveGelfUDP reads datagrams, reassembles chunks and decompresses a message like Graylog
+func receiveGelfUDP(t *testing.T, conn net.PacketConn) map[string]interface{} {
+	chunks := map[byte][]byte{}
+	buf := make([]byte, 65536)
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if !assert.Nil(t, err) {
+			return nil
+		}
+		packet := append([]byte(nil), buf[:n]...)
+
+		if !bytes.HasPrefix(packet, []byte{0x1e, 0x0f}) {
+			return decodeGelf(t, decompressGelf(t, packet))
+		}
+
+		count := packet[11]
+		assert.True(t, n <= 1420)
+		chunks[packet[10]] = packet[12:]
+		if len(chunks) < int(count) {
+			continue
+		}
+
+		var message []byte
+		for i := byte(0); i < count; i++ {
+			message = append(message, chunks[i]...)
+		}
+		return decodeGelf(t, decompressGelf(t, message))
+	}
+}
+
+// decompressGelf detects the compression by the magic bytes
+func decompressGelf(t *testing.T, packet []byte) []byte {
+	var reader io.Reader
+	var err error
+	switch {
+	case bytes.HasPrefix(packet, []byte{0x1f, 0x8b}):
+		reader, err = gzip.NewReader(bytes.NewReader(packet))
+	case packet[0] == 0x78:
+		reader, err = zlib.NewReader(bytes.NewReader(packet))
+	default:
+		return packet
+	}
+	assert.Nil(t, err)
+	decompressed, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	return decompressed
+}
+
+// acceptGelfTCP receives null delimited messages
+func acceptGelfTCP(listener net.Listener, count int) chan string {
+	received := make(chan string, count)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for i := 0; i < count; i++ {
+			message, err := reader.ReadString(0)
+			if err != nil {
+				return
+			}
+			received <- strings.TrimSuffix(message, "\x00")
+		}
+	}()
+	return received
+}
+
+func TestGelfAppender_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	// hardly compressible message which needs several chunks
+	letters := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	source := rand.New(rand.NewSource(1))
+	random := make([]byte, 5000)
+	for i := range random {
+		random[i] = letters[source.Intn(len(letters))]
+	}
+	large := string(random)
+
+	for _, compression := range []GelfCompression{GelfCompression_GZIP, GelfCompression_ZLIB, GelfCompression_NONE} {
+		appender, err := NewGelfAppender(conn.LocalAddr().String(),
+			WithGelfHost("host"), WithGelfFields("env", "test"), WithGelfCompression(compression))
+		assert.Nil(t, err)
+
+		logger := NewLogger("testLogger", LogLevel_INFO, appender)
+		logger.SetMetadataConfig(&MetadataConfig{IsEnabledLoggerName: true})
+		logger.Errorw("small", "key", "value")
+
+		message := receiveGelfUDP(t, conn)
+		assert.Equal(t, "host", message["host"])
+		assert.Equal(t, "small", message["short_message"])
+		assert.Equal(t, float64(3), message["level"])
+		assert.Equal(t, "testLogger", message["_logger"])
+		assert.Equal(t, "test", message["_env"])
+		assert.Equal(t, "value", message["_key"])
+
+		logger.Info(large)
+		message = receiveGelfUDP(t, conn)
+		assert.Equal(t, large, message["short_message"])
+
+		assert.Nil(t, appender.Close())
+	}
+}
+
+func TestGelfAppender_UDP_TooLarge(t *testing.T) {
+	appender, err := NewGelfAppender("udp://127.0.0.1:12201", WithGelfCompression(GelfCompression_NONE), WithGelfChunkSize(100))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	_, err = appender.Write([]byte(strings.Repeat("a", 100*gelfMaxChunks)))
+	assert.NotNil(t, err)
+}
+
+func TestGelfAppender_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	received := acceptGelfTCP(listener, 2)
+
+	appender, err := NewGelfAppender("tcp://" + listener.Addr().String())
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	appender.WriteLevel(LogLevel_WARN, []byte("first\n"))
+	appender.Write([]byte("multi\nline\n"))
+
+	// messages are not compressed
+	message := decodeGelf(t, []byte(<-received))
+	assert.Equal(t, "first", message["short_message"])
+	assert.Equal(t, float64(4), message["level"])
+
+	message = decodeGelf(t, []byte(<-received))
+	assert.Equal(t, "multi", message["short_message"])
+	assert.Equal(t, "multi\nline", message["full_message"])
+	assert.Equal(t, float64(6), message["level"])
+}
+
+func TestGelfAppender_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server.TLS)
+	assert.Nil(t, err)
+	defer listener.Close()
+	received := acceptGelfTCP(listener, 1)
+
+	clientConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	clientConfig.ServerName = "example.com"
+	appender, err := NewGelfAppender("tls://"+listener.Addr().String(), WithGelfTLSConfig(clientConfig))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	appender.Write([]byte("message"))
+
+	assert.Equal(t, "message", decodeGelf(t, []byte(<-received))["short_message"])
+}
+
+func TestGelfAppender_Reconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+	received := acceptGelfTCP(listener, 1)
+
+	appender, err := NewGelfAppender("tcp://" + address)
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	appender.Write([]byte("first"))
+	assert.Equal(t, "first", decodeGelf(t, []byte(<-received))["short_message"])
+	listener.Close()
+
+	listener, err = net.Listen("tcp", address)
+	assert.Nil(t, err)
+	defer listener.Close()
+	received = acceptGelfTCP(listener, 1)
+
+	// the first write may succeed on the closed connection
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		appender.Write([]byte("second"))
+		select {
+		case message := <-received:
+			assert.Equal(t, "second", decodeGelf(t, []byte(message))["short_message"])
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("not reconnected")
+		}
+	}
+}
+
+func TestNewGelfAppender_InvalidAddress(t *testing.T) {
+	_, err := NewGelfAppender("http://localhost:12201")
+	assert.NotNil(t, err)
+
+	_, err = NewGelfAppender("tcp://")
+	assert.NotNil(t, err)
+
+	appender, err := NewGelfAppender("localhost:12201")
+	assert.Nil(t, err)
+	assert.Equal(t, "udp", appender.network)
+}
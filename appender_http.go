@@ -0,0 +1,595 @@
+package golog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultHTTPBatchSize
+const defaultHTTPBatchSize = 500
+
+// defaultHTTPBatchBytes
+const defaultHTTPBatchBytes = 1024 * 1024
+
+// defaultHTTPQueueSize
+const defaultHTTPQueueSize = 4096
+
+// defaultHTTPFlushInterval
+const defaultHTTPFlushInterval = time.Second
+
+// defaultHTTPMaxRetries
+const defaultHTTPMaxRetries = 5
+
+// defaultHTTPRetryInterval is doubled on each retry up to defaultHTTPMaxRetryInterval
+const defaultHTTPRetryInterval = time.Second
+
+// defaultHTTPMaxRetryInterval
+const defaultHTTPMaxRetryInterval = 30 * time.Second
+
+// defaultHTTPTimeout
+const defaultHTTPTimeout = 10 * time.Second
+
+// HTTPEvent is an encoded event in a batch.
+// Time is the time of the event if the appender receives the metadata, e.g. LokiAppender, otherwise the time of Write.
+// LoggerName is set only by appenders which receive the metadata.
+type HTTPEvent struct {
+	Level      LogLevel
+	Time       time.Time
+	LoggerName string
+	Data       []byte
+}
+
+// HTTPBodyBuilder builds the body and the content type of a request from a batch
+type HTTPBodyBuilder interface {
+	Build(events []HTTPEvent) (body []byte, contentType string, err error)
+}
+
+// HTTPBodyBuilderFunc
+type HTTPBodyBuilderFunc func(events []HTTPEvent) (body []byte, contentType string, err error)
+
+// Build implements HTTPBodyBuilder
+func (f HTTPBodyBuilderFunc) Build(events []HTTPEvent) ([]byte, string, error) {
+	return f(events)
+}
+
+// HTTPBodyBuilder Constants
+var (
+	// HTTPBody_NDJSON writes an event per line (application/x-ndjson)
+	HTTPBody_NDJSON HTTPBodyBuilder = HTTPBodyBuilderFunc(buildNDJSONBody)
+	// HTTPBody_JSON_ARRAY writes events as a JSON array. Events which are not JSON are written as strings.
+	HTTPBody_JSON_ARRAY HTTPBodyBuilder = HTTPBodyBuilderFunc(buildJSONArrayBody)
+)
+
+// HTTPAppender posts batches of encoded events to an HTTP endpoint.
+//
+// Events are queued and posted by a background goroutine
+// when the batch reaches the max count or bytes, or at the flush interval.
+// Failed requests are retried with exponential backoff when the endpoint returns 429 or 5xx,
+// or the request fails. Retry-After is honored up to the max retry interval, and the waits are interrupted by Close.
+// Batches which cannot be posted are reported by warnLogger.
+type HTTPAppender struct {
+	url              string
+	method           string
+	bodyBuilder      HTTPBodyBuilder
+	headers          map[string]string
+	username         string
+	password         string
+	gzip             bool
+	batchSize        int
+	batchBytes       int
+	flushInterval    time.Duration
+	maxRetries       int
+	retryInterval    time.Duration
+	maxRetryInterval time.Duration
+	client           *http.Client
+	now              func() time.Time
+
+	// responseHandler inspects the body of a successful response and returns events to retry and an error
+	// if some events are failed, e.g. the bulk API of Elasticsearch.
+	responseHandler func(batch []HTTPEvent, body []byte) (retryEvents []HTTPEvent, err error)
+
+	events  chan HTTPEvent
+	flushes chan chan error
+	stopped chan struct{}
+	closing chan struct{}
+	dropped uint64
+	wg      *sync.WaitGroup
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// HTTPOption
+type HTTPOption func(appender *HTTPAppender)
+
+// WithHTTPMethod sets the method of requests. The default is POST.
+func WithHTTPMethod(method string) HTTPOption {
+	return func(appender *HTTPAppender) {
+		if method != "" {
+			appender.method = method
+		}
+	}
+}
+
+// WithHTTPBodyBuilder sets the builder of request bodies. The default is HTTPBody_NDJSON.
+func WithHTTPBodyBuilder(builder HTTPBodyBuilder) HTTPOption {
+	return func(appender *HTTPAppender) {
+		if builder != nil {
+			appender.bodyBuilder = builder
+		}
+	}
+}
+
+// WithHTTPHeaders sets headers added to every request, e.g. authorization
+func WithHTTPHeaders(headers map[string]string) HTTPOption {
+	return func(appender *HTTPAppender) {
+		appender.headers = headers
+	}
+}
+
+// WithHTTPBasicAuth sets the user name and the password of basic authentication
+func WithHTTPBasicAuth(username string, password string) HTTPOption {
+	return func(appender *HTTPAppender) {
+		appender.username = username
+		appender.password = password
+	}
+}
+
+// WithHTTPGzip compresses request bodies by gzip
+func WithHTTPGzip() HTTPOption {
+	return func(appender *HTTPAppender) {
+		appender.gzip = true
+	}
+}
+
+// WithHTTPBatchSize sets the max number of events posted by a request
+func WithHTTPBatchSize(size int) HTTPOption {
+	return func(appender *HTTPAppender) {
+		if size > 0 {
+			appender.batchSize = size
+		}
+	}
+}
+
+// WithHTTPBatchBytes sets the max total bytes of encoded events posted by a request.
+// An event larger than bytes is posted alone.
+func WithHTTPBatchBytes(bytes int) HTTPOption {
+	return func(appender *HTTPAppender) {
+		if bytes > 0 {
+			appender.batchBytes = bytes
+		}
+	}
+}
+
+// WithHTTPQueueSize sets the number of events waiting for requests.
+// Events are dropped if the queue is full.
+func WithHTTPQueueSize(size int) HTTPOption {
+	return func(appender *HTTPAppender) {
+		if size > 0 {
+			appender.events = make(chan HTTPEvent, size)
+		}
+	}
+}
+
+// WithHTTPFlushInterval sets the interval at which an incomplete batch is posted
+func WithHTTPFlushInterval(interval time.Duration) HTTPOption {
+	return func(appender *HTTPAppender) {
+		if interval > 0 {
+			appender.flushInterval = interval
+		}
+	}
+}
+
+// WithHTTPRetry sets the number of retries, the initial retry interval and the max retry interval
+func WithHTTPRetry(maxRetries int, interval time.Duration, maxInterval time.Duration) HTTPOption {
+	return func(appender *HTTPAppender) {
+		if maxRetries >= 0 {
+			appender.maxRetries = maxRetries
+		}
+		if interval > 0 {
+			appender.retryInterval = interval
+		}
+		if maxInterval > 0 {
+			appender.maxRetryInterval = maxInterval
+		}
+	}
+}
+
+// WithHTTPClient sets the http client used for requests
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(appender *HTTPAppender) {
+		if client != nil {
+			appender.client = client
+		}
+	}
+}
+
+// NewHTTPAppender returns new HTTPAppender
+func NewHTTPAppender(endpoint string, options ...HTTPOption) (*HTTPAppender, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+		return nil, fmt.Errorf("golog: invalid http endpoint %q", endpoint)
+	}
+
+	appender := &HTTPAppender{
+		url:              endpointURL.String(),
+		method:           http.MethodPost,
+		bodyBuilder:      HTTPBody_NDJSON,
+		batchSize:        defaultHTTPBatchSize,
+		batchBytes:       defaultHTTPBatchBytes,
+		flushInterval:    defaultHTTPFlushInterval,
+		maxRetries:       defaultHTTPMaxRetries,
+		retryInterval:    defaultHTTPRetryInterval,
+		maxRetryInterval: defaultHTTPMaxRetryInterval,
+		client:           &http.Client{Timeout: defaultHTTPTimeout},
+		now:              time.Now,
+		events:           make(chan HTTPEvent, defaultHTTPQueueSize),
+		flushes:          make(chan chan error),
+		stopped:          make(chan struct{}),
+		closing:          make(chan struct{}),
+		wg:               new(sync.WaitGroup),
+		mu:               new(sync.Mutex),
+		activated:        true,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	appender.wg.Add(1)
+	go appender.postLoop()
+
+	return appender, nil
+}
+
+// Write implements io.Writer. The event is posted with info level.
+func (appender *HTTPAppender) Write(data []byte) (n int, err error) {
+	return appender.WriteLevel(LogLevel_INFO, data)
+}
+
+// WriteLevel implements LevelWriter
+func (appender *HTTPAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	event := HTTPEvent{Level: level, Time: appender.now(), Data: make([]byte, len(data))}
+	copy(event.Data, data)
+	return len(data), appender.enqueue(event)
+}
+
+// writeEvent encodes event and queues it with the time and the logger name of metadata.
+// It is used by the appenders built on HTTPAppender to implement EventWriter.
+func (appender *HTTPAppender) writeEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	httpEvent := HTTPEvent{Level: level, Time: appender.now(), Data: event.Encode(metadata)}
+	if metadata != nil {
+		httpEvent.LoggerName = metadata.LoggerName
+		if metadata.IsEnabledTime && metadata.UnixTime != 0 {
+			httpEvent.Time = time.Unix(metadata.UnixTime, int64(metadata.Nanosecond))
+		}
+	}
+	return appender.enqueue(httpEvent)
+}
+
+// Flush posts queued events and waits until the requests finish
+func (appender *HTTPAppender) Flush() error {
+	reply := make(chan error, 1)
+	select {
+	case appender.flushes <- reply:
+		return <-reply
+	case <-appender.stopped:
+		return errors.New("golog: http appender is closed")
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (appender *HTTPAppender) Dropped() uint64 {
+	return atomic.LoadUint64(&appender.dropped)
+}
+
+// Close implements io.Closer.
+// Close posts all queued events and waits until the requests finish. Failed requests are not retried after Close.
+func (appender *HTTPAppender) Close() error {
+	appender.mu.Lock()
+	if !appender.activated {
+		appender.mu.Unlock()
+		return nil
+	}
+	appender.activated = false
+	close(appender.events)
+	close(appender.closing)
+	appender.mu.Unlock()
+
+	appender.wg.Wait()
+	return nil
+}
+
+// enqueue
+func (appender *HTTPAppender) enqueue(event HTTPEvent) error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return errors.New("golog: http appender is closed")
+	}
+
+	select {
+	case appender.events <- event:
+	default:
+		atomic.AddUint64(&appender.dropped, 1)
+	}
+	return nil
+}
+
+// postLoop posts batches until the queue is closed
+func (appender *HTTPAppender) postLoop() {
+	defer appender.wg.Done()
+	defer close(appender.stopped)
+
+	ticker := time.NewTicker(appender.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]HTTPEvent, 0, appender.batchSize)
+	batchBytes := 0
+	post := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := appender.postWithRetry(batch)
+		if err != nil {
+			warnLogger.Warnf("golog: post %d events to %s is failed , error : %s", len(batch), appender.url, err.Error())
+		}
+		batch = batch[:0]
+		batchBytes = 0
+		return err
+	}
+	add := func(event HTTPEvent) {
+		if len(batch) > 0 && batchBytes+len(event.Data) > appender.batchBytes {
+			post()
+		}
+		batch = append(batch, event)
+		batchBytes += len(event.Data)
+		if len(batch) >= appender.batchSize || batchBytes >= appender.batchBytes {
+			post()
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-appender.events:
+			if !ok {
+				post()
+				return
+			}
+			add(event)
+		case reply := <-appender.flushes:
+			for drained := false; !drained; {
+				select {
+				case event, ok := <-appender.events:
+					if !ok {
+						drained = true
+						break
+					}
+					add(event)
+				default:
+					drained = true
+				}
+			}
+			reply <- post()
+		case <-ticker.C:
+			post()
+		}
+	}
+}
+
+// httpPostError is an error of a request.
+// If retryEvents is not nil, only the events are retried.
+type httpPostError struct {
+	err         error
+	retryable   bool
+	retryAfter  time.Duration
+	retryEvents []HTTPEvent
+}
+
+// Error implements error
+func (postError *httpPostError) Error() string {
+	return postError.err.Error()
+}
+
+// postWithRetry posts batch. If the response handler returns events to retry, only they are posted again.
+func (appender *HTTPAppender) postWithRetry(batch []HTTPEvent) error {
+	body, contentType, err := appender.buildBody(batch)
+	if err != nil {
+		return err
+	}
+
+	interval := appender.retryInterval
+	var postErr *httpPostError
+	for attempt := 0; attempt <= appender.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait := interval
+			if postErr.retryAfter > 0 {
+				wait = postErr.retryAfter
+			}
+			if wait > appender.maxRetryInterval {
+				wait = appender.maxRetryInterval
+			}
+			if !appender.sleep(wait) {
+				return postErr
+			}
+
+			interval *= 2
+			if interval > appender.maxRetryInterval {
+				interval = appender.maxRetryInterval
+			}
+
+			if postErr.retryEvents != nil {
+				batch = postErr.retryEvents
+				if body, contentType, err = appender.buildBody(batch); err != nil {
+					return err
+				}
+			}
+		}
+
+		if postErr = appender.post(batch, body, contentType); postErr == nil {
+			return nil
+		}
+		if !postErr.retryable {
+			return postErr
+		}
+	}
+	return postErr
+}
+
+// sleep waits for d. It returns false if the appender is closed while waiting.
+func (appender *HTTPAppender) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-appender.closing:
+		return false
+	}
+}
+
+// buildBody builds and compresses the body
+func (appender *HTTPAppender) buildBody(batch []HTTPEvent) ([]byte, string, error) {
+	body, contentType, err := appender.bodyBuilder.Build(batch)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if appender.gzip {
+		if body, err = gzipBytes(body); err != nil {
+			return nil, "", err
+		}
+	}
+	return body, contentType, nil
+}
+
+// post sends a request
+func (appender *HTTPAppender) post(batch []HTTPEvent, body []byte, contentType string) *httpPostError {
+	request, err := http.NewRequest(appender.method, appender.url, bytes.NewReader(body))
+	if err != nil {
+		return &httpPostError{err: err}
+	}
+
+	request.Header.Set("Content-Type", contentType)
+	if appender.gzip {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	if appender.username != "" || appender.password != "" {
+		request.SetBasicAuth(appender.username, appender.password)
+	}
+	for key, value := range appender.headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := appender.client.Do(request)
+	if err != nil {
+		return &httpPostError{err: err, retryable: true}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 == 2 {
+		if appender.responseHandler == nil {
+			io.Copy(io.Discard, response.Body)
+			return nil
+		}
+
+		responseBody, err := io.ReadAll(response.Body)
+		if err != nil {
+			return &httpPostError{err: err, retryable: true}
+		}
+		retryEvents, err := appender.responseHandler(batch, responseBody)
+		if err != nil {
+			return &httpPostError{err: err, retryable: len(retryEvents) > 0, retryEvents: retryEvents}
+		}
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	postError := &httpPostError{
+		err: fmt.Errorf("golog: http request returns %s : %s", response.Status, string(message)),
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests ||
+		(response.StatusCode/100 == 5 && response.StatusCode != http.StatusNotImplemented) {
+		postError.retryable = true
+		postError.retryAfter = parseRetryAfter(response.Header.Get("Retry-After"), appender.now())
+	}
+	return postError
+}
+
+// parseRetryAfter parses Retry-After in seconds or HTTP-date. It returns zero if the header is invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		return 0
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// gzipBytes
+func gzipBytes(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// buildNDJSONBody
+func buildNDJSONBody(events []HTTPEvent) ([]byte, string, error) {
+	var body []byte
+	for _, event := range events {
+		body = append(body, bytes.TrimRight(event.Data, "\n")...)
+		body = append(body, '\n')
+	}
+	return body, "application/x-ndjson", nil
+}
+
+// buildJSONArrayBody
+func buildJSONArrayBody(events []HTTPEvent) ([]byte, string, error) {
+	body := []byte{'['}
+	for i, event := range events {
+		if i > 0 {
+			body = append(body, ',')
+		}
+		data := bytes.TrimRight(event.Data, "\n")
+		if json.Valid(data) {
+			body = append(body, data...)
+			continue
+		}
+		encoded, err := json.Marshal(string(data))
+		if err != nil {
+			return nil, "", err
+		}
+		body = append(body, encoded...)
+	}
+	return append(body, ']'), "application/json", nil
+}
@@ -0,0 +1,291 @@
+package golog
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// httpTestRequest is a request received by httpTestServer
+type httpTestRequest struct {
+	header http.Header
+	body   string
+}
+
+// httpTestServer records requests and responds with statuses in order, then 200
+type httpTestServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []httpTestRequest
+	statuses []int
+}
+
+func newHTTPTestServer(statuses ...int) *httpTestServer {
+	server := &httpTestServer{statuses: statuses}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			reader = gzipReader
+		}
+		body, _ := io.ReadAll(reader)
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		server.requests = append(server.requests, httpTestRequest{header: r.Header, body: string(body)})
+		if len(server.statuses) > 0 {
+			status := server.statuses[0]
+			server.statuses = server.statuses[1:]
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(status)
+		}
+	}))
+	return server
+}
+
+func (server *httpTestServer) received() []httpTestRequest {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return append([]httpTestRequest(nil), server.requests...)
+}
+
+func TestHTTPAppender_NDJSON(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+
+	appender, err := NewHTTPAppender(server.URL,
+		WithHTTPHeaders(map[string]string{"X-Api-Key": "key"}), WithHTTPBasicAuth("user", "pass"))
+	assert.Nil(t, err)
+
+	appender.Write([]byte(`{"message":"first"}` + "\n"))
+	appender.WriteLevel(LogLevel_ERROR, []byte(`{"message":"second"}`))
+	assert.Nil(t, appender.Close())
+
+	requests := server.received()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "{\"message\":\"first\"}\n{\"message\":\"second\"}\n", requests[0].body)
+	assert.Equal(t, "application/x-ndjson", requests[0].header.Get("Content-Type"))
+	assert.Equal(t, "key", requests[0].header.Get("X-Api-Key"))
+	assert.Equal(t, "Basic dXNlcjpwYXNz", requests[0].header.Get("Authorization"))
+}
+
+func TestHTTPAppender_JSONArray_Gzip(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+
+	appender, err := NewHTTPAppender(server.URL, WithHTTPBodyBuilder(HTTPBody_JSON_ARRAY), WithHTTPGzip())
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	appender.Write([]byte(`{"message":"first"}`))
+	appender.Write([]byte("plain \"text\"\n"))
+	assert.Nil(t, appender.Flush())
+
+	requests := server.received()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, `[{"message":"first"},"plain \"text\""]`, requests[0].body)
+	assert.Equal(t, "application/json", requests[0].header.Get("Content-Type"))
+	assert.Equal(t, "gzip", requests[0].header.Get("Content-Encoding"))
+}
+
+func TestHTTPAppender_CustomBodyBuilder(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+
+	builder := HTTPBodyBuilderFunc(func(events []HTTPEvent) ([]byte, string, error) {
+		var body []byte
+		for _, event := range events {
+			body = append(body, event.Level.String()...)
+			body = append(body, event.Data...)
+		}
+		return body, "text/plain", nil
+	})
+	appender, err := NewHTTPAppender(server.URL, WithHTTPBodyBuilder(builder))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	logger := NewLogger("testLogger", LogLevel_INFO, appender)
+	logger.SetMetadataConfig(&MetadataConfig{})
+	logger.Warn("message")
+	assert.Nil(t, appender.Flush())
+
+	requests := server.received()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "[WARN]   () message", requests[0].body)
+	assert.Equal(t, "text/plain", requests[0].header.Get("Content-Type"))
+}
+
+func TestHTTPAppender_Batch(t *testing.T) {
+	t.Run("size", func(t *testing.T) {
+		server := newHTTPTestServer()
+		defer server.Close()
+
+		appender, err := NewHTTPAppender(server.URL, WithHTTPBatchSize(2))
+		assert.Nil(t, err)
+
+		for i := 0; i < 5; i++ {
+			appender.Write([]byte("event"))
+		}
+		assert.Nil(t, appender.Close())
+
+		assert.Len(t, server.received(), 3)
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		server := newHTTPTestServer()
+		defer server.Close()
+
+		appender, err := NewHTTPAppender(server.URL, WithHTTPBatchBytes(10))
+		assert.Nil(t, err)
+
+		appender.Write([]byte("1234"))
+		appender.Write([]byte("5678"))
+		appender.Write([]byte("large event"))
+		appender.Write([]byte("9"))
+		assert.Nil(t, appender.Close())
+
+		requests := server.received()
+		assert.Len(t, requests, 3)
+		assert.Equal(t, "1234\n5678\n", requests[0].body)
+		assert.Equal(t, "large event\n", requests[1].body)
+		assert.Equal(t, "9\n", requests[2].body)
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		server := newHTTPTestServer()
+		defer server.Close()
+
+		appender, err := NewHTTPAppender(server.URL, WithHTTPFlushInterval(10*time.Millisecond))
+		assert.Nil(t, err)
+		defer appender.Close()
+
+		appender.Write([]byte("event"))
+		assert.Eventually(t, func() bool { return len(server.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestHTTPAppender_Retry(t *testing.T) {
+	t.Run("retryable", func(t *testing.T) {
+		server := newHTTPTestServer(http.StatusTooManyRequests, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		appender, err := NewHTTPAppender(server.URL, WithHTTPRetry(2, time.Millisecond, time.Millisecond))
+		assert.Nil(t, err)
+		defer appender.Close()
+
+		appender.Write([]byte("event"))
+		assert.Nil(t, appender.Flush())
+		assert.Len(t, server.received(), 3)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		server := newHTTPTestServer(http.StatusBadRequest)
+		defer server.Close()
+
+		appender, err := NewHTTPAppender(server.URL, WithHTTPRetry(2, time.Millisecond, time.Millisecond))
+		assert.Nil(t, err)
+		defer appender.Close()
+
+		appender.Write([]byte("event"))
+		assert.NotNil(t, appender.Flush())
+		assert.Len(t, server.received(), 1)
+	})
+
+	t.Run("retries exceeded", func(t *testing.T) {
+		server := newHTTPTestServer(http.StatusBadGateway, http.StatusBadGateway)
+		defer server.Close()
+
+		appender, err := NewHTTPAppender(server.URL, WithHTTPRetry(1, time.Millisecond, time.Millisecond))
+		assert.Nil(t, err)
+		defer appender.Close()
+
+		appender.Write([]byte("event"))
+		assert.NotNil(t, appender.Flush())
+		assert.Len(t, server.received(), 2)
+	})
+}
+
+func TestHTTPAppender_RetryWait(t *testing.T) {
+	t.Run("Retry-After is capped by max retry interval", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.Header().Set("Retry-After", "86400")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}))
+		defer server.Close()
+
+		appender, err := NewHTTPAppender(server.URL, WithHTTPRetry(1, time.Millisecond, 10*time.Millisecond))
+		assert.Nil(t, err)
+		defer appender.Close()
+
+		appender.Write([]byte("event"))
+		start := time.Now()
+		assert.Nil(t, appender.Flush())
+		assert.Less(t, time.Since(start), 3*time.Second)
+	})
+
+	t.Run("Close interrupts retries", func(t *testing.T) {
+		server := newHTTPTestServer(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		appender, err := NewHTTPAppender(server.URL, WithHTTPRetry(5, time.Hour, time.Hour))
+		assert.Nil(t, err)
+
+		appender.Write([]byte("event"))
+		assert.Eventually(t, func() bool { return len(server.received()) == 1 }, 3*time.Second, time.Millisecond)
+
+		closed := make(chan struct{})
+		go func() {
+			appender.Close()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(3 * time.Second):
+			t.Fatal("Close is blocked by retries")
+		}
+		assert.Len(t, server.received(), 1)
+	})
+}
+
+func TestHTTPAppender_Closed(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+
+	appender, err := NewHTTPAppender(server.URL)
+	assert.Nil(t, err)
+	assert.Nil(t, appender.Close())
+
+	_, err = appender.Write([]byte("event"))
+	assert.NotNil(t, err)
+	assert.NotNil(t, appender.Flush())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2018, 5, 7, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 10*time.Second, parseRetryAfter("Mon, 07 May 2018 12:00:10 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Mon, 07 May 2018 11:00:00 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("invalid", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+}
+
+func TestNewHTTPAppender_InvalidEndpoint(t *testing.T) {
+	_, err := NewHTTPAppender("localhost:8080")
+	assert.NotNil(t, err)
+}
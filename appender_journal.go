@@ -0,0 +1,261 @@
+package golog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultJournalSocketPath
+const defaultJournalSocketPath = "/run/systemd/journal/socket"
+
+// JournalAppender writes events to systemd-journald by the native journal protocol.
+//
+// The level is mapped to PRIORITY, the metadata to CODE_FILE, CODE_LINE and SYSLOG_IDENTIFIER,
+// and the fields of events and contexts to custom fields, e.g. "requestID" to REQUESTID.
+// Entries too large for a datagram are passed by a sealed memfd (Linux only).
+// The connection is established lazily and re-established after errors.
+type JournalAppender struct {
+	socketPath string
+	identifier string
+	fields     Fields
+
+	conn *net.UnixConn
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// JournalOption
+type JournalOption func(appender *JournalAppender)
+
+// WithJournalSocketPath sets the path of the journal socket. The default is "/run/systemd/journal/socket".
+func WithJournalSocketPath(path string) JournalOption {
+	return func(appender *JournalAppender) {
+		if path != "" {
+			appender.socketPath = path
+		}
+	}
+}
+
+// WithJournalIdentifier sets SYSLOG_IDENTIFIER.
+// If not specified, the logger name is used, or the executable name if the logger name is unknown.
+func WithJournalIdentifier(identifier string) JournalOption {
+	return func(appender *JournalAppender) {
+		appender.identifier = identifier
+	}
+}
+
+// WithJournalFields sets alternating keys and values added to every entry
+func WithJournalFields(keysAndValues ...interface{}) JournalOption {
+	return func(appender *JournalAppender) {
+		appender.fields = appender.fields.with(keysAndValues...)
+	}
+}
+
+// NewJournalAppender returns new JournalAppender
+func NewJournalAppender(options ...JournalOption) *JournalAppender {
+	appender := &JournalAppender{
+		socketPath: defaultJournalSocketPath,
+		mu:         new(sync.Mutex),
+		activated:  true,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	return appender
+}
+
+// IsJournalAvailable reports whether the journal socket exists
+func IsJournalAvailable() bool {
+	info, err := os.Stat(defaultJournalSocketPath)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// Write implements io.Writer. The event is written with notice priority.
+func (appender *JournalAppender) Write(data []byte) (n int, err error) {
+	entry := appender.appendEntry(nil, 5, "", strings.TrimSuffix(string(data), "\n"))
+	if err := appender.send(entry); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteLevel implements LevelWriter
+func (appender *JournalAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	entry := appender.appendEntry(nil, syslogSeverity(level), "", strings.TrimSuffix(string(data), "\n"))
+	if err := appender.send(entry); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteEvent implements EventWriter
+func (appender *JournalAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	if metadata == nil {
+		return appender.send(appender.appendEntry(nil, syslogSeverity(level), "", string(event.Encode(nil))))
+	}
+
+	message, fields := eventMessage(event)
+	entry := appender.appendEntry(nil, syslogSeverity(level), metadata.LoggerName, message)
+
+	if metadata.LoggerName != "" {
+		entry = appendJournalField(entry, "LOGGER", metadata.LoggerName)
+	}
+	if metadata.SourceFile != "" {
+		entry = appendJournalField(entry, "CODE_FILE", metadata.SourceFile)
+	}
+	if metadata.SourceLine != 0 {
+		entry = appendJournalField(entry, "CODE_LINE", strconv.Itoa(metadata.SourceLine))
+	}
+	if traceID := metadata.GetTraceID(); traceID != "" {
+		entry = appendJournalField(entry, "TRACE_ID", traceID)
+		entry = appendJournalField(entry, "SPAN_ID", metadata.GetSpanID())
+		entry = appendJournalField(entry, "TRACE_FLAGS", metadata.GetTraceFlags())
+	}
+	entry = appendJournalFields(entry, metadata.ContextFields)
+	entry = appendJournalFields(entry, fields)
+
+	return appender.send(entry)
+}
+
+// Close implements io.Closer
+func (appender *JournalAppender) Close() error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return nil
+	}
+	appender.activated = false
+
+	if appender.conn != nil {
+		err := appender.conn.Close()
+		appender.conn = nil
+		return err
+	}
+	return nil
+}
+
+// appendEntry appends MESSAGE, PRIORITY, SYSLOG_IDENTIFIER and the fields of the appender
+func (appender *JournalAppender) appendEntry(entry []byte, priority int, loggerName string, message string) []byte {
+	identifier := appender.identifier
+	if identifier == "" {
+		identifier = loggerName
+	}
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	entry = appendJournalField(entry, "MESSAGE", message)
+	entry = appendJournalField(entry, "PRIORITY", strconv.Itoa(priority))
+	entry = appendJournalField(entry, "SYSLOG_IDENTIFIER", identifier)
+	return appendJournalFields(entry, appender.fields)
+}
+
+// send writes an entry as a datagram, or by a file descriptor if it is too large.
+// On failure the connection is re-established and the entry is retried once.
+func (appender *JournalAppender) send(entry []byte) error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return errors.New("golog: journal appender is closed")
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = appender.connect(); err != nil {
+			continue
+		}
+
+		_, err = appender.conn.Write(entry)
+		if err != nil && isJournalEntryTooLarge(err) {
+			err = sendJournalEntryByFile(appender.conn, entry)
+		}
+		if err == nil {
+			return nil
+		}
+
+		appender.conn.Close()
+		appender.conn = nil
+	}
+	return err
+}
+
+// connect. The caller must hold mu.
+func (appender *JournalAppender) connect() error {
+	if appender.conn != nil {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: appender.socketPath, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("golog: connect to journal is failed : %v", err)
+	}
+
+	appender.conn = conn
+	return nil
+}
+
+// appendJournalFields appends fields with sanitized names
+func appendJournalFields(entry []byte, fields Fields) []byte {
+	for _, field := range fields {
+		var value string
+		if err, ok := field.Value.(error); ok {
+			value = err.Error()
+		} else {
+			value = fmt.Sprint(field.Value)
+		}
+		entry = appendJournalField(entry, journalFieldName(field.Key), value)
+	}
+	return entry
+}
+
+// appendJournalField appends "NAME=value\n",
+// or NAME, a newline, the little endian 64 bit length and value if value contains a newline
+func appendJournalField(entry []byte, name string, value string) []byte {
+	entry = append(entry, name...)
+	if !strings.Contains(value, "\n") {
+		entry = append(entry, '=')
+		entry = append(entry, value...)
+		return append(entry, '\n')
+	}
+
+	entry = append(entry, '\n')
+	entry = binary.LittleEndian.AppendUint64(entry, uint64(len(value)))
+	entry = append(entry, value...)
+	return append(entry, '\n')
+}
+
+// journalFieldName converts key to a valid field name:
+// uppercase letters, digits and underscores, not starting with an underscore or a digit, at most 64 characters.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
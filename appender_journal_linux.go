@@ -0,0 +1,116 @@
+//go:build linux
+
+package golog
+
+import (
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// memfdCreateTrap is the number of memfd_create(2), which is not defined by package syscall on all architectures
+var memfdCreateTrap = map[string]uintptr{
+	"386":      356,
+	"amd64":    319,
+	"arm":      385,
+	"arm64":    279,
+	"loong64":  279,
+	"mips":     4354,
+	"mipsle":   4354,
+	"mips64":   5314,
+	"mips64le": 5314,
+	"ppc64":    360,
+	"ppc64le":  360,
+	"riscv64":  279,
+	"s390x":    350,
+}
+
+// memfd and seal flags
+const (
+	mfdCloexec       = 0x1
+	mfdAllowSealing  = 0x2
+	fcntlAddSeals    = 1033
+	fSealSeal        = 0x1
+	fSealShrink      = 0x2
+	fSealGrow        = 0x4
+	fSealWrite       = 0x8
+	journalSealFlags = fSealSeal | fSealShrink | fSealGrow | fSealWrite
+)
+
+// isJournalEntryTooLarge reports whether the entry must be sent by a file descriptor
+func isJournalEntryTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// sendJournalEntryByFile writes entry to a sealed memfd and passes the descriptor to journald.
+// If memfd is not available, an unlinked file in /dev/shm is used as sd_journal_send does.
+func sendJournalEntryByFile(conn *net.UnixConn, entry []byte) error {
+	file, err := newJournalMemfd(entry)
+	if err != nil {
+		if file, err = newJournalTempFile(entry); err != nil {
+			return err
+		}
+	}
+	defer file.Close()
+
+	// WriteMsgUnix cannot be used with a connected datagram socket
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	rights := syscall.UnixRights(int(file.Fd()))
+	var sendErr error
+	err = raw.Write(func(fd uintptr) bool {
+		sendErr = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return sendErr != syscall.EAGAIN
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// newJournalMemfd returns a sealed memfd containing entry
+func newJournalMemfd(entry []byte) (*os.File, error) {
+	trap, ok := memfdCreateTrap[runtime.GOARCH]
+	if !ok {
+		return nil, syscall.ENOSYS
+	}
+
+	name := []byte("golog-journal\x00")
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(&name[0])), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	file := os.NewFile(fd, "golog-journal")
+	if _, err := file.Write(entry); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, fcntlAddSeals, journalSealFlags); errno != 0 {
+		file.Close()
+		return nil, errno
+	}
+	return file, nil
+}
+
+// newJournalTempFile returns an unlinked file in /dev/shm containing entry
+func newJournalTempFile(entry []byte) (*os.File, error) {
+	file, err := os.CreateTemp("/dev/shm", "golog-journal-")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(file.Name())
+
+	if _, err := file.Write(entry); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
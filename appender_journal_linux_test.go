@@ -0,0 +1,46 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalAppender_LargeEntry(t *testing.T) {
+	journal, path := newFakeJournal(t)
+	defer journal.Close()
+
+	appender := NewJournalAppender(WithJournalSocketPath(path), WithJournalIdentifier("app"))
+	defer appender.Close()
+
+	message := strings.Repeat("a", 4*1024*1024)
+	_, err := appender.Write([]byte(message))
+	assert.Nil(t, err)
+
+	buf := make([]byte, 16)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	journal.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, oobn, _, _, err := journal.ReadMsgUnix(buf, oob)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	assert.Nil(t, err)
+	fds, err := syscall.ParseUnixRights(&messages[0])
+	assert.Nil(t, err)
+
+	file := os.NewFile(uintptr(fds[0]), "entry")
+	defer file.Close()
+	file.Seek(0, io.SeekStart)
+	data, err := io.ReadAll(file)
+	assert.Nil(t, err)
+
+	entry := parseJournalEntry(t, data)
+	assert.Equal(t, message, entry["MESSAGE"])
+	assert.Equal(t, "app", entry["SYSLOG_IDENTIFIER"])
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package golog
+
+import (
+	"errors"
+	"net"
+)
+
+// isJournalEntryTooLarge reports whether the entry must be sent by a file descriptor
+func isJournalEntryTooLarge(err error) bool {
+	return false
+}
+
+// sendJournalEntryByFile is supported only on Linux
+func sendJournalEntryByFile(conn *net.UnixConn, entry []byte) error {
+	return errors.New("golog: large journal entries are not supported on this platform")
+}
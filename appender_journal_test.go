@@ -0,0 +1,120 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// parseJournalEntry parses an entry of the native journal protocol
+func parseJournalEntry(t *testing.T, data []byte) map[string]string {
+	entry := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("invalid entry %q", data)
+		}
+		name := string(data[:i])
+
+		if data[i] == '=' {
+			end := bytes.IndexByte(data, '\n')
+			entry[name] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+
+		length := int(binary.LittleEndian.Uint64(data[i+1 : i+9]))
+		entry[name] = string(data[i+9 : i+9+length])
+		data = data[i+9+length+1:]
+	}
+	return entry
+}
+
+// newFakeJournal listens on a unixgram socket in a temporary directory
+func newFakeJournal(t *testing.T) (*net.UnixConn, string) {
+	path := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, path
+}
+
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	buf := make([]byte, 64*1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parseJournalEntry(t, buf[:n])
+}
+
+func TestJournalAppender_WriteEvent(t *testing.T) {
+	journal, path := newFakeJournal(t)
+	defer journal.Close()
+
+	appender := NewJournalAppender(WithJournalSocketPath(path), WithJournalFields("service", "api"))
+	defer appender.Close()
+
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	logger.SetContextExtractor(NewContextValueExtractor("request-id", testContextKey("requestID")))
+	ctx := context.WithValue(context.Background(), testContextKey("requestID"), "abc")
+
+	logger.With("_user", "u1", "2fa", true).ErrorCtx(ctx, "multi\nline")
+
+	entry := readJournalEntry(t, journal)
+	assert.Equal(t, "multi\nline", entry["MESSAGE"])
+	assert.Equal(t, "3", entry["PRIORITY"])
+	assert.Equal(t, "testLogger", entry["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "testLogger", entry["LOGGER"])
+	assert.Equal(t, "appender_journal_test.go", filepath.Base(entry["CODE_FILE"]))
+	assert.NotEmpty(t, entry["CODE_LINE"])
+	assert.Equal(t, "api", entry["SERVICE"])
+	assert.Equal(t, "abc", entry["REQUEST_ID"])
+	assert.Equal(t, "u1", entry["USER"])
+	assert.Equal(t, "true", entry["F_2FA"])
+}
+
+func TestJournalAppender_Write(t *testing.T) {
+	journal, path := newFakeJournal(t)
+	defer journal.Close()
+
+	appender := NewJournalAppender(WithJournalSocketPath(path), WithJournalIdentifier("app"))
+
+	appender.WriteLevel(LogLevel_WARN, []byte("message\n"))
+	entry := readJournalEntry(t, journal)
+	assert.Equal(t, map[string]string{"MESSAGE": "message", "PRIORITY": "4", "SYSLOG_IDENTIFIER": "app"}, entry)
+
+	appender.Write([]byte("message"))
+	entry = readJournalEntry(t, journal)
+	assert.Equal(t, "5", entry["PRIORITY"])
+
+	assert.Nil(t, appender.Close())
+	_, err := appender.Write([]byte("closed"))
+	assert.NotNil(t, err)
+}
+
+func TestJournalAppender_NotAvailable(t *testing.T) {
+	appender := NewJournalAppender(WithJournalSocketPath(filepath.Join(t.TempDir(), "none")))
+	defer appender.Close()
+
+	_, err := appender.Write([]byte("message"))
+	assert.NotNil(t, err)
+}
+
+func TestJournalFieldName(t *testing.T) {
+	assert.Equal(t, "REQUEST_ID", journalFieldName("request_id"))
+	assert.Equal(t, "USER_NAME", journalFieldName("user.name"))
+	assert.Equal(t, "PID", journalFieldName("__pid"))
+	assert.Equal(t, "F_1ST", journalFieldName("1st"))
+	assert.Equal(t, "F_", journalFieldName("_"))
+	assert.Equal(t, 64, len(journalFieldName(strings.Repeat("a", 100))))
+}
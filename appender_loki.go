@@ -0,0 +1,331 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LokiEncoding is the encoding of push requests
+type LokiEncoding int
+
+// LokiEncoding Constants
+const (
+	// LokiEncoding_PROTOBUF is snappy compressed protocol buffers
+	LokiEncoding_PROTOBUF LokiEncoding = iota
+	LokiEncoding_JSON
+)
+
+// defaultLokiPushPath
+const defaultLokiPushPath = "/loki/api/v1/push"
+
+// LokiAppender pushes events to Grafana Loki.
+//
+// Events are grouped into streams by labels, which are the static labels
+// and optionally the level and the logger name, and pushed in batches by HTTPAppender.
+// Loki rejects entries older than the last entry of a stream,
+// so entries are sorted in each stream and the timestamps are adjusted to be strictly increasing.
+type LokiAppender struct {
+	encoding    LokiEncoding
+	labels      Fields
+	levelLabel  string
+	loggerLabel string
+	tenantID    string
+	httpOptions []HTTPOption
+
+	// lastTimestamps is the last timestamp of each stream. It is used only by the goroutine of HTTPAppender.
+	lastTimestamps map[string]int64
+	http           *HTTPAppender
+}
+
+// LokiOption
+type LokiOption func(appender *LokiAppender)
+
+// WithLokiEncoding sets the encoding of push requests. The default is LokiEncoding_PROTOBUF.
+func WithLokiEncoding(encoding LokiEncoding) LokiOption {
+	return func(appender *LokiAppender) {
+		appender.encoding = encoding
+	}
+}
+
+// WithLokiLabels sets alternating names and values of labels added to every stream
+func WithLokiLabels(namesAndValues ...interface{}) LokiOption {
+	return func(appender *LokiAppender) {
+		appender.labels = appender.labels.with(namesAndValues...)
+	}
+}
+
+// WithLokiLevelLabel sets the label name of the level, e.g. "info". The default is "level". Empty disables the label.
+func WithLokiLevelLabel(name string) LokiOption {
+	return func(appender *LokiAppender) {
+		appender.levelLabel = name
+	}
+}
+
+// WithLokiLoggerLabel sets the label name of the logger name. The default is "logger". Empty disables the label.
+func WithLokiLoggerLabel(name string) LokiOption {
+	return func(appender *LokiAppender) {
+		appender.loggerLabel = name
+	}
+}
+
+// WithLokiTenantID sets X-Scope-OrgID of multi-tenant Loki
+func WithLokiTenantID(tenantID string) LokiOption {
+	return func(appender *LokiAppender) {
+		appender.tenantID = tenantID
+	}
+}
+
+// WithLokiHTTPOptions sets options of the underlying HTTPAppender, e.g. batching, retries and authentication
+func WithLokiHTTPOptions(options ...HTTPOption) LokiOption {
+	return func(appender *LokiAppender) {
+		appender.httpOptions = append(appender.httpOptions, options...)
+	}
+}
+
+// NewLokiAppender returns new LokiAppender.
+//
+// endpoint is the url of Loki, e.g. "http://localhost:3100".
+// If the path is empty, "/loki/api/v1/push" is used.
+func NewLokiAppender(endpoint string, options ...LokiOption) (*LokiAppender, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpointURL.Path == "" || endpointURL.Path == "/" {
+		endpointURL.Path = defaultLokiPushPath
+	}
+
+	appender := &LokiAppender{
+		encoding:       LokiEncoding_PROTOBUF,
+		levelLabel:     "level",
+		loggerLabel:    "logger",
+		lastTimestamps: map[string]int64{},
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	httpOptions := append(appender.httpOptions, WithHTTPBodyBuilder(HTTPBodyBuilderFunc(appender.build)))
+	if appender.tenantID != "" {
+		httpOptions = append(httpOptions, func(httpAppender *HTTPAppender) {
+			headers := map[string]string{"X-Scope-OrgID": appender.tenantID}
+			for key, value := range httpAppender.headers {
+				headers[key] = value
+			}
+			httpAppender.headers = headers
+		})
+	}
+
+	if appender.http, err = NewHTTPAppender(endpointURL.String(), httpOptions...); err != nil {
+		return nil, fmt.Errorf("golog: invalid loki endpoint %q", endpoint)
+	}
+	return appender, nil
+}
+
+// Write implements io.Writer. The event is pushed with info level.
+func (appender *LokiAppender) Write(data []byte) (n int, err error) {
+	return appender.http.WriteLevel(LogLevel_INFO, data)
+}
+
+// WriteLevel implements LevelWriter
+func (appender *LokiAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	return appender.http.WriteLevel(level, data)
+}
+
+// WriteEvent implements EventWriter. The event is encoded as the line.
+func (appender *LokiAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	return appender.http.writeEvent(level, metadata, event)
+}
+
+// Flush pushes queued events and waits until the requests finish
+func (appender *LokiAppender) Flush() error {
+	return appender.http.Flush()
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (appender *LokiAppender) Dropped() uint64 {
+	return appender.http.Dropped()
+}
+
+// Close implements io.Closer
+func (appender *LokiAppender) Close() error {
+	return appender.http.Close()
+}
+
+// lokiStream is a stream of a push request
+type lokiStream struct {
+	labels  map[string]string
+	key     string
+	entries []lokiEntry
+}
+
+// lokiEntry
+type lokiEntry struct {
+	timestamp int64
+	line      string
+}
+
+// build implements HTTPBodyBuilder
+func (appender *LokiAppender) build(events []HTTPEvent) ([]byte, string, error) {
+	streams := appender.groupStreams(events)
+
+	if appender.encoding == LokiEncoding_JSON {
+		body, err := encodeLokiJSON(streams)
+		return body, "application/json", err
+	}
+	return encodeSnappy(encodeLokiProtobuf(streams)), "application/x-protobuf", nil
+}
+
+// groupStreams groups events into streams, sorts entries and adjusts timestamps
+func (appender *LokiAppender) groupStreams(events []HTTPEvent) []*lokiStream {
+	var streams []*lokiStream
+	index := map[string]*lokiStream{}
+
+	for _, event := range events {
+		labels := appender.streamLabels(event)
+		key := formatLokiLabels(labels)
+		stream, ok := index[key]
+		if !ok {
+			stream = &lokiStream{labels: labels, key: key}
+			index[key] = stream
+			streams = append(streams, stream)
+		}
+		stream.entries = append(stream.entries, lokiEntry{
+			timestamp: event.Time.UnixNano(),
+			line:      strings.TrimSuffix(string(event.Data), "\n"),
+		})
+	}
+
+	for _, stream := range streams {
+		sort.SliceStable(stream.entries, func(i, j int) bool {
+			return stream.entries[i].timestamp < stream.entries[j].timestamp
+		})
+
+		last := appender.lastTimestamps[stream.key]
+		for i := range stream.entries {
+			if stream.entries[i].timestamp <= last {
+				stream.entries[i].timestamp = last + 1
+			}
+			last = stream.entries[i].timestamp
+		}
+		appender.lastTimestamps[stream.key] = last
+	}
+	return streams
+}
+
+// streamLabels returns the labels of event
+func (appender *LokiAppender) streamLabels(event HTTPEvent) map[string]string {
+	labels := make(map[string]string, len(appender.labels)+2)
+	for _, label := range appender.labels {
+		labels[lokiLabelName(label.Key)] = fmt.Sprint(label.Value)
+	}
+	if appender.levelLabel != "" {
+		labels[appender.levelLabel] = strings.ToLower(otlpSeverityText(event.Level))
+	}
+	if appender.loggerLabel != "" && event.LoggerName != "" {
+		labels[appender.loggerLabel] = event.LoggerName
+	}
+	if len(labels) == 0 {
+		// Loki requires at least one label
+		labels["job"] = filepath.Base(os.Args[0])
+	}
+	return labels
+}
+
+// formatLokiLabels formats labels as a selector, e.g. {job="app", level="info"}
+func formatLokiLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(name)
+		builder.WriteByte('=')
+		builder.WriteString(strconv.Quote(labels[name]))
+	}
+	builder.WriteByte('}')
+	return builder.String()
+}
+
+// lokiLabelName replaces characters other than letters, digits and underscores
+func lokiLabelName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// encodeLokiProtobuf encodes PushRequest
+func encodeLokiProtobuf(streams []*lokiStream) []byte {
+	var b []byte
+	for _, stream := range streams {
+		// PushRequest.streams
+		b = appendProtoMessageField(b, 1, func(b []byte) []byte {
+			// StreamAdapter.labels
+			b = appendProtoStringField(b, 1, stream.key)
+			// StreamAdapter.entries
+			for _, entry := range stream.entries {
+				b = appendProtoMessageField(b, 2, func(b []byte) []byte {
+					// EntryAdapter.timestamp
+					b = appendProtoMessageField(b, 1, func(b []byte) []byte {
+						b = appendProtoInt64Field(b, 1, entry.timestamp/int64(time.Second))
+						return appendProtoInt64Field(b, 2, entry.timestamp%int64(time.Second))
+					})
+					// EntryAdapter.line
+					return appendProtoStringField(b, 2, entry.line)
+				})
+			}
+			return b
+		})
+	}
+	return b
+}
+
+// lokiJSONPush is a push request of JSON
+type lokiJSONPush struct {
+	Streams []lokiJSONStream `json:"streams"`
+}
+
+// lokiJSONStream
+type lokiJSONStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// encodeLokiJSON
+func encodeLokiJSON(streams []*lokiStream) ([]byte, error) {
+	push := lokiJSONPush{Streams: make([]lokiJSONStream, 0, len(streams))}
+	for _, stream := range streams {
+		jsonStream := lokiJSONStream{Stream: stream.labels, Values: make([][2]string, 0, len(stream.entries))}
+		for _, entry := range stream.entries {
+			jsonStream.Values = append(jsonStream.Values, [2]string{strconv.FormatInt(entry.timestamp, 10), entry.line})
+		}
+		push.Streams = append(push.Streams, jsonStream)
+	}
+	return json.Marshal(push)
+}
@@ -0,0 +1,216 @@
+package golog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLokiEntry is an entry received by fakeLoki
+type fakeLokiEntry struct {
+	timestamp int64
+	line      string
+}
+
+// fakeLoki decodes push requests of protobuf and JSON
+type fakeLoki struct {
+	*httptest.Server
+	mu      sync.Mutex
+	path    string
+	header  http.Header
+	streams map[string][]fakeLokiEntry
+}
+
+func newFakeLoki(t *testing.T) *fakeLoki {
+	loki := &fakeLoki{streams: map[string][]fakeLokiEntry{}}
+	loki.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		loki.mu.Lock()
+		defer loki.mu.Unlock()
+		loki.path = r.URL.Path
+		loki.header = r.Header
+
+		switch r.Header.Get("Content-Type") {
+		case "application/x-protobuf":
+			decoded, err := decodeSnappy(body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			request := decodeProto(t, decoded)
+			for i := range request[1] {
+				stream := request.message(t, 1, i)
+				labels := stream.string(1)
+				for j := range stream[2] {
+					entry := stream.message(t, 2, j)
+					timestamp := entry.message(t, 1, 0)
+					var nanos int64
+					if len(timestamp[2]) > 0 {
+						nanos = int64(timestamp[2][0].(uint64))
+					}
+					loki.streams[labels] = append(loki.streams[labels], fakeLokiEntry{
+						timestamp: int64(timestamp[1][0].(uint64))*int64(time.Second) + nanos,
+						line:      entry.string(2),
+					})
+				}
+			}
+		case "application/json":
+			var request lokiJSONPush
+			if err := json.Unmarshal(body, &request); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for _, stream := range request.Streams {
+				labels := formatLokiLabels(stream.Stream)
+				for _, value := range stream.Values {
+					timestamp, _ := strconv.ParseInt(value[0], 10, 64)
+					loki.streams[labels] = append(loki.streams[labels], fakeLokiEntry{timestamp: timestamp, line: value[1]})
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return loki
+}
+
+func (loki *fakeLoki) received() map[string][]fakeLokiEntry {
+	loki.mu.Lock()
+	defer loki.mu.Unlock()
+	streams := map[string][]fakeLokiEntry{}
+	for labels, entries := range loki.streams {
+		streams[labels] = append([]fakeLokiEntry(nil), entries...)
+	}
+	return streams
+}
+
+func TestLokiAppender(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		encoding LokiEncoding
+	}{
+		{"protobuf", LokiEncoding_PROTOBUF},
+		{"json", LokiEncoding_JSON},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			loki := newFakeLoki(t)
+			defer loki.Close()
+
+			appender, err := NewLokiAppender(loki.URL,
+				WithLokiEncoding(tt.encoding), WithLokiLabels("app", "sample", "env.name", "test"), WithLokiTenantID("tenant"))
+			assert.Nil(t, err)
+
+			logger := NewLogger("testLogger", LogLevel_INFO, appender)
+			logger.SetMetadataConfig(&MetadataConfig{IsEnabledLoggerName: true})
+			logger.Info("first")
+			logger.Error("second")
+			logger.Info("third")
+			assert.Nil(t, appender.Close())
+
+			assert.Equal(t, "/loki/api/v1/push", loki.path)
+			assert.Equal(t, "tenant", loki.header.Get("X-Scope-OrgID"))
+
+			streams := loki.received()
+			assert.Len(t, streams, 2)
+
+			info := streams[`{app="sample", env_name="test", level="info", logger="testLogger"}`]
+			assert.Len(t, info, 2)
+			assert.Equal(t, "  testLogger () first", info[0].line)
+			assert.Equal(t, "  testLogger () third", info[1].line)
+			assert.Less(t, info[0].timestamp, info[1].timestamp)
+
+			errorEntries := streams[`{app="sample", env_name="test", level="error", logger="testLogger"}`]
+			assert.Len(t, errorEntries, 1)
+			assert.Equal(t, "  testLogger () second", errorEntries[0].line)
+		})
+	}
+}
+
+func TestLokiAppender_Labels(t *testing.T) {
+	loki := newFakeLoki(t)
+	defer loki.Close()
+
+	appender, err := NewLokiAppender(loki.URL+"/custom/push",
+		WithLokiLevelLabel("severity"), WithLokiLoggerLabel(""), WithLokiLabels("job", "sample"))
+	assert.Nil(t, err)
+
+	appender.WriteLevel(LogLevel_WARN, []byte("message\n"))
+	assert.Nil(t, appender.Close())
+
+	assert.Equal(t, "/custom/push", loki.path)
+	assert.Equal(t, []string{`{job="sample", severity="warn"}`}, keys(loki.received()))
+}
+
+func TestLokiAppender_OutOfOrder(t *testing.T) {
+	loki := newFakeLoki(t)
+	defer loki.Close()
+
+	appender, err := NewLokiAppender(loki.URL, WithLokiLevelLabel(""), WithLokiLabels("job", "sample"))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	base := time.Date(2018, 5, 7, 12, 0, 0, 0, time.UTC)
+	times := []time.Time{base.Add(2 * time.Second), base, base}
+	appender.http.now = func() time.Time {
+		t := times[0]
+		times = times[1:]
+		return t
+	}
+
+	appender.Write([]byte("late"))
+	appender.Write([]byte("early"))
+	assert.Nil(t, appender.Flush())
+
+	// older than the last entry of the previous push
+	appender.Write([]byte("older"))
+	assert.Nil(t, appender.Flush())
+
+	entries := loki.received()[`{job="sample"}`]
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "early", entries[0].line)
+	assert.Equal(t, base.UnixNano(), entries[0].timestamp)
+	assert.Equal(t, "late", entries[1].line)
+	assert.Equal(t, base.Add(2*time.Second).UnixNano(), entries[1].timestamp)
+	assert.Equal(t, "older", entries[2].line)
+	assert.Equal(t, base.Add(2*time.Second).UnixNano()+1, entries[2].timestamp)
+}
+
+func TestLokiAppender_EventTime(t *testing.T) {
+	loki := newFakeLoki(t)
+	defer loki.Close()
+
+	appender, err := NewLokiAppender(loki.URL, WithLokiLevelLabel(""), WithLokiLabels("job", "sample"))
+	assert.Nil(t, err)
+	appender.http.now = func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	assert.Nil(t, appender.WriteEvent(LogLevel_INFO, newPatternTestMetadata(), TextLogEvent{Event: "event"}))
+	assert.Nil(t, appender.Close())
+
+	entries := loki.received()[`{job="sample", logger="app.db.pool"}`]
+	assert.Len(t, entries, 1)
+	assert.Equal(t, time.Unix(1525662860, 123456789).UnixNano(), entries[0].timestamp)
+}
+
+func TestNewLokiAppender_InvalidEndpoint(t *testing.T) {
+	_, err := NewLokiAppender("localhost:3100")
+	assert.NotNil(t, err)
+}
+
+// keys returns the keys of streams
+func keys(streams map[string][]fakeLokiEntry) []string {
+	var keys []string
+	for key := range streams {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,519 @@
+package golog
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Framing is how events are delimited on stream transports
+type Framing int
+
+// Framing Constants
+const (
+	// Framing_NEWLINE terminates each event by a newline. A trailing newline of the event is replaced.
+	Framing_NEWLINE Framing = iota
+	// Framing_LENGTH_PREFIXED prefixes each event by its length as 4 bytes big endian
+	Framing_LENGTH_PREFIXED
+	// Framing_OCTET_COUNTING prefixes each event by its length in decimal and a space (RFC 6587)
+	Framing_OCTET_COUNTING
+)
+
+// defaultNetworkBufferSize is the number of events buffered while disconnected
+const defaultNetworkBufferSize = 1024
+
+// defaultNetworkTimeout
+const defaultNetworkTimeout = 5 * time.Second
+
+// defaultNetworkInitialBackoff is doubled on each failed connection up to defaultNetworkMaxBackoff
+const defaultNetworkInitialBackoff = 100 * time.Millisecond
+
+// defaultNetworkMaxBackoff
+const defaultNetworkMaxBackoff = 30 * time.Second
+
+// NetworkAppender streams encoded events to a collector over TCP, UDP, TLS or Unix domain sockets.
+//
+// Events are buffered in memory and written by a background goroutine,
+// which reconnects with exponential backoff after errors.
+// While disconnected, the oldest events are dropped when the buffer is full.
+// If a spool directory is specified, events which do not fit in the buffer are saved to disk instead,
+// and sent before newer events once connected, also after the application restarts.
+type NetworkAppender struct {
+	network        string
+	address        string
+	framing        Framing
+	tlsConfig      *tls.Config
+	timeout        time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	bufferSize     int
+	spool          *networkSpool
+
+	queue   [][]byte
+	notify  chan struct{}
+	dropped uint64
+	conn    net.Conn
+	done    chan struct{}
+	wg      *sync.WaitGroup
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// NetworkOption
+type NetworkOption func(appender *NetworkAppender)
+
+// WithNetworkFraming sets the framing of stream transports. The default is Framing_NEWLINE.
+// Datagram transports send an event per datagram without framing.
+func WithNetworkFraming(framing Framing) NetworkOption {
+	return func(appender *NetworkAppender) {
+		appender.framing = framing
+	}
+}
+
+// WithNetworkTLSConfig sets the TLS config of "tls://" addresses
+func WithNetworkTLSConfig(config *tls.Config) NetworkOption {
+	return func(appender *NetworkAppender) {
+		appender.tlsConfig = config
+	}
+}
+
+// WithNetworkTimeout sets dial and write timeout
+func WithNetworkTimeout(timeout time.Duration) NetworkOption {
+	return func(appender *NetworkAppender) {
+		if timeout > 0 {
+			appender.timeout = timeout
+		}
+	}
+}
+
+// WithNetworkBackoff sets the initial and the max interval of reconnection
+func WithNetworkBackoff(initial time.Duration, max time.Duration) NetworkOption {
+	return func(appender *NetworkAppender) {
+		if initial > 0 {
+			appender.initialBackoff = initial
+		}
+		if max > 0 {
+			appender.maxBackoff = max
+		}
+	}
+}
+
+// WithNetworkBufferSize sets the number of events buffered in memory
+func WithNetworkBufferSize(size int) NetworkOption {
+	return func(appender *NetworkAppender) {
+		if size > 0 {
+			appender.bufferSize = size
+		}
+	}
+}
+
+// WithNetworkSpool saves events which do not fit in the buffer to a file in dir.
+// maxSize limits the size of the file, and events are dropped when it is exceeded. Zero means no limit.
+func WithNetworkSpool(dir string, maxSize int64) NetworkOption {
+	return func(appender *NetworkAppender) {
+		appender.spool = &networkSpool{dir: dir, maxSize: maxSize}
+	}
+}
+
+// NewNetworkAppender returns new NetworkAppender.
+//
+// address is "tcp://host:port", "udp://host:port", "tls://host:port",
+// "unix:///path/to/socket" or "unixgram:///path/to/socket". "host:port" is TCP.
+func NewNetworkAppender(address string, options ...NetworkOption) (*NetworkAppender, error) {
+	network, addr, err := parseNetworkAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	appender := &NetworkAppender{
+		network:        network,
+		address:        addr,
+		framing:        Framing_NEWLINE,
+		timeout:        defaultNetworkTimeout,
+		initialBackoff: defaultNetworkInitialBackoff,
+		maxBackoff:     defaultNetworkMaxBackoff,
+		bufferSize:     defaultNetworkBufferSize,
+		notify:         make(chan struct{}, 1),
+		done:           make(chan struct{}),
+		wg:             new(sync.WaitGroup),
+		mu:             new(sync.Mutex),
+		activated:      true,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	if appender.spool != nil {
+		if err := appender.spool.open(network + "-" + addr); err != nil {
+			return nil, err
+		}
+		// send events spooled by the previous run
+		appender.notify <- struct{}{}
+	}
+
+	appender.wg.Add(1)
+	go appender.sendLoop()
+
+	return appender, nil
+}
+
+// parseNetworkAddress
+func parseNetworkAddress(address string) (network string, addr string, err error) {
+	network, addr = "tcp", address
+	if i := strings.Index(address, "://"); i >= 0 {
+		network, addr = address[:i], address[i+3:]
+	}
+
+	switch network {
+	case "tcp", "udp", "tls", "unix", "unixgram":
+	default:
+		return "", "", fmt.Errorf("golog: unsupported network %q", network)
+	}
+
+	if addr == "" {
+		return "", "", fmt.Errorf("golog: invalid network address %q", address)
+	}
+	return network, addr, nil
+}
+
+// Write implements io.Writer. The event is sent in background.
+func (appender *NetworkAppender) Write(data []byte) (n int, err error) {
+	payload := make([]byte, len(data))
+	copy(payload, data)
+
+	appender.mu.Lock()
+	if !appender.activated {
+		appender.mu.Unlock()
+		return 0, errors.New("golog: network appender is closed")
+	}
+	appender.push(payload)
+	appender.mu.Unlock()
+
+	select {
+	case appender.notify <- struct{}{}:
+	default:
+	}
+	return len(data), nil
+}
+
+// Dropped returns the number of events dropped because the buffer or the spool was full
+func (appender *NetworkAppender) Dropped() uint64 {
+	return atomic.LoadUint64(&appender.dropped)
+}
+
+// Close implements io.Closer.
+// Buffered events are sent if connected, otherwise they are saved to the spool or dropped.
+func (appender *NetworkAppender) Close() error {
+	appender.mu.Lock()
+	if !appender.activated {
+		appender.mu.Unlock()
+		return nil
+	}
+	appender.activated = false
+	close(appender.done)
+	appender.mu.Unlock()
+
+	appender.wg.Wait()
+	return nil
+}
+
+// push appends payload to the queue.
+// If the queue is full, it is moved to the spool, or the oldest event is dropped. The caller must hold mu.
+func (appender *NetworkAppender) push(payload []byte) {
+	if len(appender.queue) < appender.bufferSize {
+		appender.queue = append(appender.queue, payload)
+		return
+	}
+
+	if appender.spool != nil {
+		// the spool keeps events older than the queue so that the order is preserved
+		dropped := appender.spool.append(append(appender.queue, payload))
+		atomic.AddUint64(&appender.dropped, uint64(dropped))
+		appender.queue = nil
+		return
+	}
+
+	atomic.AddUint64(&appender.dropped, 1)
+	copy(appender.queue, appender.queue[1:])
+	appender.queue[len(appender.queue)-1] = payload
+}
+
+// sendLoop connects and sends queued events until Close
+func (appender *NetworkAppender) sendLoop() {
+	defer appender.wg.Done()
+
+	backoff := appender.initialBackoff
+	var retry <-chan time.Time
+	connected := true
+
+	for {
+		select {
+		case <-appender.done:
+			appender.shutdown()
+			return
+		case <-appender.notify:
+		case <-retry:
+		}
+		retry = nil
+
+		if err := appender.flush(); err != nil {
+			if connected {
+				warnLogger.Warnf("golog: send to %s://%s is failed , error : %s", appender.network, appender.address, err.Error())
+			}
+			connected = false
+
+			retry = time.After(backoff)
+			backoff *= 2
+			if backoff > appender.maxBackoff {
+				backoff = appender.maxBackoff
+			}
+			continue
+		}
+
+		connected = true
+		backoff = appender.initialBackoff
+	}
+}
+
+// flush connects if necessary and sends the spool and the queue
+func (appender *NetworkAppender) flush() error {
+	if err := appender.connect(); err != nil {
+		return err
+	}
+
+	if appender.spool != nil {
+		appender.mu.Lock()
+		path, ok := appender.spool.takeReplay()
+		appender.mu.Unlock()
+
+		if ok {
+			if err := replayNetworkSpool(path, appender.send); err != nil {
+				appender.disconnect()
+				return err
+			}
+		}
+	}
+
+	for {
+		appender.mu.Lock()
+		queue := appender.queue
+		appender.queue = nil
+		appender.mu.Unlock()
+
+		if len(queue) == 0 {
+			return nil
+		}
+
+		for i, payload := range queue {
+			if err := appender.send(payload); err != nil {
+				appender.requeue(queue[i:])
+				appender.disconnect()
+				return err
+			}
+		}
+	}
+}
+
+// requeue puts unsent events back in front of the queue
+func (appender *NetworkAppender) requeue(unsent [][]byte) {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	queue := appender.queue
+	appender.queue = append(make([][]byte, 0, len(unsent)+len(queue)), unsent...)
+	for _, payload := range queue {
+		appender.push(payload)
+	}
+}
+
+// shutdown sends the remaining events once, and saves unsent events to the spool
+func (appender *NetworkAppender) shutdown() {
+	appender.flush()
+
+	appender.mu.Lock()
+	if len(appender.queue) > 0 {
+		if appender.spool != nil {
+			atomic.AddUint64(&appender.dropped, uint64(appender.spool.append(appender.queue)))
+		} else {
+			atomic.AddUint64(&appender.dropped, uint64(len(appender.queue)))
+		}
+		appender.queue = nil
+	}
+	appender.mu.Unlock()
+
+	appender.disconnect()
+}
+
+// connect. It is called only by the send loop.
+func (appender *NetworkAppender) connect() error {
+	if appender.conn != nil {
+		return nil
+	}
+
+	var conn net.Conn
+	var err error
+	if appender.network == "tls" {
+		dialer := &net.Dialer{Timeout: appender.timeout}
+		conn, err = tls.DialWithDialer(dialer, "tcp", appender.address, appender.tlsConfig)
+	} else {
+		conn, err = net.DialTimeout(appender.network, appender.address, appender.timeout)
+	}
+	if err != nil {
+		return err
+	}
+
+	appender.conn = conn
+	return nil
+}
+
+// disconnect
+func (appender *NetworkAppender) disconnect() {
+	if appender.conn != nil {
+		appender.conn.Close()
+		appender.conn = nil
+	}
+}
+
+// send writes a framed event
+func (appender *NetworkAppender) send(payload []byte) error {
+	appender.conn.SetWriteDeadline(time.Now().Add(appender.timeout))
+	_, err := appender.conn.Write(appender.frame(payload))
+	return err
+}
+
+// frame adds framing of stream transports.
+// Payloads are written as is except by newline framing, so that binary encodings are kept.
+func (appender *NetworkAppender) frame(payload []byte) []byte {
+	switch appender.network {
+	case "udp", "unixgram":
+		return payload
+	}
+
+	switch appender.framing {
+	case Framing_LENGTH_PREFIXED:
+		framed := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(payload)), uint32(len(payload)))
+		return append(framed, payload...)
+	case Framing_OCTET_COUNTING:
+		framed := strconv.AppendInt(make([]byte, 0, 8+len(payload)), int64(len(payload)), 10)
+		framed = append(framed, ' ')
+		return append(framed, payload...)
+	default:
+		return append([]byte(strings.TrimSuffix(string(payload), "\n")), '\n')
+	}
+}
+
+// networkSpool saves events to "<dir>/golog-<address>.spool" as records of the 4 bytes big endian length and the event.
+// Before sending, the spool file is renamed to ".replay" so that events can be spooled while replaying.
+type networkSpool struct {
+	dir     string
+	maxSize int64
+	path    string
+	size    int64
+}
+
+// open prepares the directory and the path of the spool
+func (spool *networkSpool) open(name string) error {
+	if err := os.MkdirAll(spool.dir, 0755); err != nil {
+		return fmt.Errorf("golog: create spool directory is failed : %v", err)
+	}
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	spool.path = filepath.Join(spool.dir, "golog-"+name+".spool")
+
+	if info, err := os.Stat(spool.path); err == nil {
+		spool.size = info.Size()
+	}
+	return nil
+}
+
+// append saves payloads and returns the number of dropped events. The caller must hold the mutex of the appender.
+func (spool *networkSpool) append(payloads [][]byte) (dropped int) {
+	file, err := os.OpenFile(spool.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		warnLogger.Warnf("golog: open spool is failed , error : %s", err.Error())
+		return len(payloads)
+	}
+	defer file.Close()
+
+	buffer := make([]byte, 0, 4096)
+	for i, payload := range payloads {
+		if spool.maxSize > 0 && spool.size+int64(len(buffer)+4+len(payload)) > spool.maxSize {
+			dropped = len(payloads) - i
+			break
+		}
+		buffer = binary.BigEndian.AppendUint32(buffer, uint32(len(payload)))
+		buffer = append(buffer, payload...)
+	}
+
+	if _, err := file.Write(buffer); err != nil {
+		warnLogger.Warnf("golog: write spool is failed , error : %s", err.Error())
+		return len(payloads)
+	}
+	spool.size += int64(len(buffer))
+	return dropped
+}
+
+// takeReplay returns the path of the file to replay. The caller must hold the mutex of the appender.
+func (spool *networkSpool) takeReplay() (string, bool) {
+	replay := spool.path + ".replay"
+	if _, err := os.Stat(replay); err == nil {
+		return replay, true
+	}
+
+	if spool.size == 0 {
+		return "", false
+	}
+	if err := os.Rename(spool.path, replay); err != nil {
+		warnLogger.Warnf("golog: rename spool is failed , error : %s", err.Error())
+		return "", false
+	}
+	spool.size = 0
+	return replay, true
+}
+
+// replayNetworkSpool sends the events in path and removes it.
+// If sending fails, the unsent events are kept in path.
+func replayNetworkSpool(path string, send func(payload []byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	for offset+4 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		if offset+4+length > len(data) {
+			break
+		}
+		if err := send(data[offset+4 : offset+4+length]); err != nil {
+			if writeErr := os.WriteFile(path, data[offset:], 0644); writeErr != nil {
+				warnLogger.Warnf("golog: write spool is failed , error : %s", writeErr.Error())
+			}
+			return err
+		}
+		offset += 4 + length
+	}
+
+	if offset != len(data) {
+		warnLogger.Warnf("golog: spool %s is truncated", path)
+	}
+	return os.Remove(path)
+}
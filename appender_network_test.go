@@ -0,0 +1,248 @@
+package golog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// acceptNetworkEvents accepts a connection and sends events read by read
+func acceptNetworkEvents(listener net.Listener, read func(reader *bufio.Reader) (string, error)) <-chan string {
+	received := make(chan string, 16)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			event, err := read(reader)
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- event
+		}
+	}()
+	return received
+}
+
+// readNewline
+func readNewline(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	return line[:max(len(line)-1, 0)], err
+}
+
+// readLengthPrefixed
+func readLengthPrefixed(reader *bufio.Reader) (string, error) {
+	var length uint32
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+	event := make([]byte, length)
+	_, err := io.ReadFull(reader, event)
+	return string(event), err
+}
+
+// receiveNetworkEvent
+func receiveNetworkEvent(t *testing.T, received <-chan string) string {
+	select {
+	case event := <-received:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+		return ""
+	}
+}
+
+func TestParseNetworkAddress(t *testing.T) {
+	t.Run("tcp by default", func(t *testing.T) {
+		network, addr, err := parseNetworkAddress("localhost:24224")
+		assert.Nil(t, err)
+		assert.Equal(t, "tcp", network)
+		assert.Equal(t, "localhost:24224", addr)
+	})
+
+	t.Run("unix", func(t *testing.T) {
+		network, addr, err := parseNetworkAddress("unix:///var/run/log.sock")
+		assert.Nil(t, err)
+		assert.Equal(t, "unix", network)
+		assert.Equal(t, "/var/run/log.sock", addr)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, _, err := parseNetworkAddress("http://localhost")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestNetworkAppender_Framing(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		framing Framing
+		read    func(reader *bufio.Reader) (string, error)
+	}{
+		{"newline", Framing_NEWLINE, readNewline},
+		{"length prefixed", Framing_LENGTH_PREFIXED, readLengthPrefixed},
+		{"octet counting", Framing_OCTET_COUNTING, func(reader *bufio.Reader) (string, error) {
+			return readOctetCounted(reader), nil
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.Nil(t, err)
+			defer listener.Close()
+			received := acceptNetworkEvents(listener, tt.read)
+
+			appender, err := NewNetworkAppender("tcp://"+listener.Addr().String(), WithNetworkFraming(tt.framing))
+			assert.Nil(t, err)
+			defer appender.Close()
+
+			appender.Write([]byte(`{"message":"first"}` + "\n"))
+			appender.Write([]byte("second\nline"))
+
+			if tt.framing == Framing_NEWLINE {
+				assert.Equal(t, `{"message":"first"}`, receiveNetworkEvent(t, received))
+				assert.Equal(t, "second", receiveNetworkEvent(t, received))
+			} else {
+				// payloads are written as is
+				assert.Equal(t, `{"message":"first"}`+"\n", receiveNetworkEvent(t, received))
+				assert.Equal(t, "second\nline", receiveNetworkEvent(t, received))
+			}
+		})
+	}
+}
+
+func TestNetworkAppender_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	appender, err := NewNetworkAppender("udp://" + conn.LocalAddr().String())
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	appender.Write([]byte("message\n"))
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+	// datagrams are written as is
+	assert.Equal(t, "message\n", string(buf[:n]))
+}
+
+func TestNetworkAppender_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server.TLS)
+	assert.Nil(t, err)
+	defer listener.Close()
+	received := acceptNetworkEvents(listener, readNewline)
+
+	clientConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	clientConfig.ServerName = "example.com"
+	appender, err := NewNetworkAppender("tls://"+listener.Addr().String(), WithNetworkTLSConfig(clientConfig))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	appender.Write([]byte("message"))
+
+	assert.Equal(t, "message", receiveNetworkEvent(t, received))
+}
+
+func TestNetworkAppender_Reconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	appender, err := NewNetworkAppender("tcp://"+address,
+		WithNetworkBufferSize(2), WithNetworkBackoff(10*time.Millisecond, 50*time.Millisecond))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	// the oldest event is dropped while disconnected
+	appender.Write([]byte("first"))
+	appender.Write([]byte("second"))
+	appender.Write([]byte("third"))
+	assert.Equal(t, uint64(1), appender.Dropped())
+
+	listener, err = net.Listen("tcp", address)
+	assert.Nil(t, err)
+	defer listener.Close()
+	received := acceptNetworkEvents(listener, readNewline)
+
+	assert.Equal(t, "second", receiveNetworkEvent(t, received))
+	assert.Equal(t, "third", receiveNetworkEvent(t, received))
+
+	appender.Write([]byte("fourth"))
+	assert.Equal(t, "fourth", receiveNetworkEvent(t, received))
+}
+
+func TestNetworkAppender_Spool(t *testing.T) {
+	dir := t.TempDir()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	t.Run("spool while disconnected", func(t *testing.T) {
+		appender, err := NewNetworkAppender("tcp://"+address,
+			WithNetworkBufferSize(1), WithNetworkSpool(dir, 0), WithNetworkTimeout(100*time.Millisecond))
+		assert.Nil(t, err)
+
+		appender.Write([]byte("first"))
+		appender.Write([]byte("second"))
+		appender.Write([]byte("third"))
+		assert.Nil(t, appender.Close())
+		assert.Equal(t, uint64(0), appender.Dropped())
+
+		files, _ := filepath.Glob(filepath.Join(dir, "*.spool"))
+		assert.Len(t, files, 1)
+	})
+
+	t.Run("replay after restart", func(t *testing.T) {
+		listener, err := net.Listen("tcp", address)
+		assert.Nil(t, err)
+		defer listener.Close()
+		received := acceptNetworkEvents(listener, readNewline)
+
+		appender, err := NewNetworkAppender("tcp://"+address, WithNetworkSpool(dir, 0))
+		assert.Nil(t, err)
+		defer appender.Close()
+
+		assert.Equal(t, "first", receiveNetworkEvent(t, received))
+		assert.Equal(t, "second", receiveNetworkEvent(t, received))
+		assert.Equal(t, "third", receiveNetworkEvent(t, received))
+
+		appender.Write([]byte("fourth"))
+		assert.Equal(t, "fourth", receiveNetworkEvent(t, received))
+
+		files, _ := filepath.Glob(filepath.Join(dir, "*"))
+		assert.Empty(t, files)
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		appender, err := NewNetworkAppender("tcp://"+address,
+			WithNetworkBufferSize(1), WithNetworkSpool(t.TempDir(), 10), WithNetworkTimeout(100*time.Millisecond))
+		assert.Nil(t, err)
+
+		// 4 bytes length + 5 bytes event fits only once
+		appender.Write([]byte("first"))
+		appender.Write([]byte("again"))
+		assert.Nil(t, appender.Close())
+		assert.Equal(t, uint64(1), appender.Dropped())
+	})
+}
@@ -0,0 +1,717 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OTLPEncoding is the encoding of OTLP/HTTP requests
+type OTLPEncoding int
+
+// OTLPEncoding Constants
+const (
+	OTLPEncoding_PROTOBUF OTLPEncoding = iota
+	OTLPEncoding_JSON
+)
+
+// defaultOTLPBatchSize
+const defaultOTLPBatchSize = 512
+
+// defaultOTLPQueueSize
+const defaultOTLPQueueSize = 2048
+
+// defaultOTLPFlushInterval
+const defaultOTLPFlushInterval = time.Second
+
+// defaultOTLPMaxRetries
+const defaultOTLPMaxRetries = 5
+
+// defaultOTLPRetryInterval is doubled on each retry up to defaultOTLPMaxRetryInterval
+const defaultOTLPRetryInterval = time.Second
+
+// defaultOTLPMaxRetryInterval
+const defaultOTLPMaxRetryInterval = 30 * time.Second
+
+// defaultOTLPTimeout
+const defaultOTLPTimeout = 10 * time.Second
+
+// defaultOTLPScopeName is used for events without logger name
+const defaultOTLPScopeName = "golog"
+
+// OTLPAppender exports events as OTLP LogRecords to an OpenTelemetry collector over OTLP/HTTP.
+//
+// Events are queued and exported in batches by a background goroutine.
+// The level is mapped to SeverityNumber, the metadata to attributes, trace id and span id,
+// and the logger name to the instrumentation scope.
+// Failed exports are retried with exponential backoff when the collector returns
+// 429, 502, 503 or 504, or the request fails.
+type OTLPAppender struct {
+	endpoint           string
+	encoding           OTLPEncoding
+	headers            map[string]string
+	resourceAttributes Fields
+	batchSize          int
+	flushInterval      time.Duration
+	maxRetries         int
+	retryInterval      time.Duration
+	maxRetryInterval   time.Duration
+	client             *http.Client
+	now                func() time.Time
+
+	records chan otlpLogRecord
+	dropped uint64
+	wg      *sync.WaitGroup
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// otlpLogRecord is a LogRecord waiting for export
+type otlpLogRecord struct {
+	scope                string
+	timeUnixNano         uint64
+	observedTimeUnixNano uint64
+	severityNumber       int32
+	severityText         string
+	body                 string
+	attributes           Fields
+	traceContext         TraceContext
+}
+
+// OTLPOption
+type OTLPOption func(appender *OTLPAppender)
+
+// WithOTLPEncoding sets the encoding of requests. The default is OTLPEncoding_PROTOBUF.
+func WithOTLPEncoding(encoding OTLPEncoding) OTLPOption {
+	return func(appender *OTLPAppender) {
+		appender.encoding = encoding
+	}
+}
+
+// WithOTLPHeaders sets headers added to every request, e.g. authorization
+func WithOTLPHeaders(headers map[string]string) OTLPOption {
+	return func(appender *OTLPAppender) {
+		appender.headers = headers
+	}
+}
+
+// WithOTLPResourceAttributes sets alternating keys and values of the resource, e.g. "service.name".
+// If "service.name" is not specified, "unknown_service:<executable name>" is used.
+func WithOTLPResourceAttributes(keysAndValues ...interface{}) OTLPOption {
+	return func(appender *OTLPAppender) {
+		appender.resourceAttributes = appender.resourceAttributes.with(keysAndValues...)
+	}
+}
+
+// WithOTLPBatchSize sets the max number of LogRecords exported by a request
+func WithOTLPBatchSize(size int) OTLPOption {
+	return func(appender *OTLPAppender) {
+		if size > 0 {
+			appender.batchSize = size
+		}
+	}
+}
+
+// WithOTLPQueueSize sets the number of LogRecords waiting for export.
+// Events are dropped if the queue is full.
+func WithOTLPQueueSize(size int) OTLPOption {
+	return func(appender *OTLPAppender) {
+		if size > 0 {
+			appender.records = make(chan otlpLogRecord, size)
+		}
+	}
+}
+
+// WithOTLPFlushInterval sets the interval at which an incomplete batch is exported
+func WithOTLPFlushInterval(interval time.Duration) OTLPOption {
+	return func(appender *OTLPAppender) {
+		if interval > 0 {
+			appender.flushInterval = interval
+		}
+	}
+}
+
+// WithOTLPRetry sets the number of retries, the initial retry interval and the max retry interval
+func WithOTLPRetry(maxRetries int, interval time.Duration, maxInterval time.Duration) OTLPOption {
+	return func(appender *OTLPAppender) {
+		if maxRetries >= 0 {
+			appender.maxRetries = maxRetries
+		}
+		if interval > 0 {
+			appender.retryInterval = interval
+		}
+		if maxInterval > 0 {
+			appender.maxRetryInterval = maxInterval
+		}
+	}
+}
+
+// WithOTLPHTTPClient sets the http client used for exports
+func WithOTLPHTTPClient(client *http.Client) OTLPOption {
+	return func(appender *OTLPAppender) {
+		if client != nil {
+			appender.client = client
+		}
+	}
+}
+
+// NewOTLPAppender returns new OTLPAppender.
+//
+// endpoint is the url of the logs endpoint, e.g. "http://localhost:4318/v1/logs".
+// If the path is empty, "/v1/logs" is used.
+func NewOTLPAppender(endpoint string, options ...OTLPOption) (*OTLPAppender, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpointURL.Scheme == "" || endpointURL.Host == "" {
+		return nil, fmt.Errorf("golog: invalid otlp endpoint %q", endpoint)
+	}
+
+	if endpointURL.Path == "" || endpointURL.Path == "/" {
+		endpointURL.Path = "/v1/logs"
+	}
+
+	appender := &OTLPAppender{
+		endpoint:         endpointURL.String(),
+		encoding:         OTLPEncoding_PROTOBUF,
+		batchSize:        defaultOTLPBatchSize,
+		flushInterval:    defaultOTLPFlushInterval,
+		maxRetries:       defaultOTLPMaxRetries,
+		retryInterval:    defaultOTLPRetryInterval,
+		maxRetryInterval: defaultOTLPMaxRetryInterval,
+		client:           &http.Client{Timeout: defaultOTLPTimeout},
+		now:              time.Now,
+		records:          make(chan otlpLogRecord, defaultOTLPQueueSize),
+		wg:               new(sync.WaitGroup),
+		mu:               new(sync.Mutex),
+		activated:        true,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	if !appender.resourceAttributes.has("service.name") {
+		appender.resourceAttributes = append(Fields{{Key: "service.name", Value: "unknown_service:" + filepath.Base(os.Args[0])}}, appender.resourceAttributes...)
+	}
+
+	appender.wg.Add(1)
+	go appender.exportLoop()
+
+	return appender, nil
+}
+
+// Write implements io.Writer. The event is exported with unspecified severity.
+func (appender *OTLPAppender) Write(data []byte) (n int, err error) {
+	now := appender.now()
+	return len(data), appender.enqueue(otlpLogRecord{
+		scope:                defaultOTLPScopeName,
+		observedTimeUnixNano: uint64(now.UnixNano()),
+		body:                 strings.TrimSuffix(string(data), "\n"),
+	})
+}
+
+// WriteLevel implements LevelWriter
+func (appender *OTLPAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	now := appender.now()
+	return len(data), appender.enqueue(otlpLogRecord{
+		scope:                defaultOTLPScopeName,
+		observedTimeUnixNano: uint64(now.UnixNano()),
+		severityNumber:       otlpSeverityNumber(level),
+		severityText:         otlpSeverityText(level),
+		body:                 strings.TrimSuffix(string(data), "\n"),
+	})
+}
+
+// WriteEvent implements EventWriter
+func (appender *OTLPAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	record := NewRecord(level, metadata, event)
+	return appender.enqueue(newOTLPLogRecord(&record, appender.now()))
+}
+
+// newOTLPLogRecord converts record to LogRecord
+func newOTLPLogRecord(record *Record, observedTime time.Time) otlpLogRecord {
+	logRecord := otlpLogRecord{
+		scope:                defaultOTLPScopeName,
+		observedTimeUnixNano: uint64(observedTime.UnixNano()),
+		severityNumber:       otlpSeverityNumber(record.Level),
+		severityText:         otlpSeverityText(record.Level),
+		body:                 record.Message,
+	}
+
+	if metadata := record.Metadata; metadata != nil {
+		if metadata.LoggerName != "" {
+			logRecord.scope = metadata.LoggerName
+		}
+		if metadata.UnixTime != 0 {
+			logRecord.timeUnixNano = uint64(metadata.UnixTime)*uint64(time.Second) + uint64(metadata.Nanosecond)
+		}
+		if metadata.SourceFile != "" {
+			logRecord.attributes = append(logRecord.attributes, Field{Key: "code.filepath", Value: metadata.SourceFile})
+		}
+		if metadata.SourceLine != 0 {
+			logRecord.attributes = append(logRecord.attributes, Field{Key: "code.lineno", Value: metadata.SourceLine})
+		}
+		logRecord.attributes = append(logRecord.attributes, metadata.ContextFields...)
+		if metadata.IsEnabledTrace {
+			logRecord.traceContext = metadata.TraceContext
+		}
+	}
+	logRecord.attributes = append(logRecord.attributes, record.Fields...)
+
+	return logRecord
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (appender *OTLPAppender) Dropped() uint64 {
+	return atomic.LoadUint64(&appender.dropped)
+}
+
+// Close implements io.Closer.
+// Close exports all queued events and waits until the exports finish.
+func (appender *OTLPAppender) Close() error {
+	appender.mu.Lock()
+	if !appender.activated {
+		appender.mu.Unlock()
+		return nil
+	}
+	appender.activated = false
+	close(appender.records)
+	appender.mu.Unlock()
+
+	appender.wg.Wait()
+	return nil
+}
+
+// enqueue
+func (appender *OTLPAppender) enqueue(record otlpLogRecord) error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return errors.New("golog: otlp appender is closed")
+	}
+
+	select {
+	case appender.records <- record:
+	default:
+		atomic.AddUint64(&appender.dropped, 1)
+	}
+	return nil
+}
+
+// exportLoop exports batches until the queue is closed
+func (appender *OTLPAppender) exportLoop() {
+	defer appender.wg.Done()
+
+	ticker := time.NewTicker(appender.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]otlpLogRecord, 0, appender.batchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := appender.exportWithRetry(batch); err != nil {
+			warnLogger.Warnf("golog: export %d otlp log records is failed , error : %s", len(batch), err.Error())
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case record, ok := <-appender.records:
+			if !ok {
+				export()
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= appender.batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		}
+	}
+}
+
+// otlpExportError is an error of an export
+type otlpExportError struct {
+	err        error
+	retryable  bool
+	retryAfter time.Duration
+}
+
+// Error implements error
+func (exportError *otlpExportError) Error() string {
+	return exportError.err.Error()
+}
+
+// exportWithRetry
+func (appender *OTLPAppender) exportWithRetry(batch []otlpLogRecord) error {
+	body, contentType := appender.encode(batch)
+	interval := appender.retryInterval
+
+	var err *otlpExportError
+	for attempt := 0; attempt <= appender.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait := interval
+			if err.retryAfter > 0 {
+				wait = err.retryAfter
+			}
+			time.Sleep(wait)
+
+			interval *= 2
+			if interval > appender.maxRetryInterval {
+				interval = appender.maxRetryInterval
+			}
+		}
+
+		if err = appender.export(body, contentType); err == nil {
+			return nil
+		}
+		if !err.retryable {
+			return err
+		}
+	}
+	return err
+}
+
+// export sends a request
+func (appender *OTLPAppender) export(body []byte, contentType string) *otlpExportError {
+	request, err := http.NewRequest(http.MethodPost, appender.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return &otlpExportError{err: err}
+	}
+
+	request.Header.Set("Content-Type", contentType)
+	for key, value := range appender.headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := appender.client.Do(request)
+	if err != nil {
+		return &otlpExportError{err: err, retryable: true}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 == 2 {
+		io.Copy(io.Discard, response.Body)
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	exportError := &otlpExportError{
+		err: fmt.Errorf("golog: otlp export returns %s : %s", response.Status, string(message)),
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		exportError.retryable = true
+		exportError.retryAfter = parseRetryAfter(response.Header.Get("Retry-After"), appender.now())
+	}
+	return exportError
+}
+
+// encode returns the body and the content type of an export request
+func (appender *OTLPAppender) encode(batch []otlpLogRecord) ([]byte, string) {
+	if appender.encoding == OTLPEncoding_JSON {
+		return encodeOTLPJSON(appender.resourceAttributes, batch), "application/json"
+	}
+	return encodeOTLPProtobuf(appender.resourceAttributes, batch), "application/x-protobuf"
+}
+
+// otlpSeverityNumber maps LogLevel to the first SeverityNumber of the range
+func otlpSeverityNumber(level LogLevel) int32 {
+	switch level {
+	case LogLevel_TRACE:
+		return 1
+	case LogLevel_DEBUG:
+		return 5
+	case LogLevel_INFO:
+		return 9
+	case LogLevel_WARN:
+		return 13
+	case LogLevel_ERROR:
+		return 17
+	case LogLevel_FATAL:
+		return 21
+	default:
+		return 0
+	}
+}
+
+// otlpSeverityText returns the level without brackets, e.g. "INFO"
+func otlpSeverityText(level LogLevel) string {
+	return strings.Trim(level.String(), "[]")
+}
+
+// otlpGroupByScope groups records by scope preserving the order of first appearance
+func otlpGroupByScope(batch []otlpLogRecord) [][]otlpLogRecord {
+	var groups [][]otlpLogRecord
+	index := map[string]int{}
+	for _, record := range batch {
+		i, ok := index[record.scope]
+		if !ok {
+			i = len(groups)
+			index[record.scope] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], record)
+	}
+	return groups
+}
+
+// otlpValue normalizes value to string, bool, int64, float64 or []byte
+func otlpValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string, bool, int64, float64, []byte:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// encodeOTLPProtobuf encodes ExportLogsServiceRequest
+func encodeOTLPProtobuf(resourceAttributes Fields, batch []otlpLogRecord) []byte {
+	// ExportLogsServiceRequest.resource_logs
+	return appendProtoMessageField(nil, 1, func(b []byte) []byte {
+		// ResourceLogs.resource
+		b = appendProtoMessageField(b, 1, func(b []byte) []byte {
+			return appendOTLPProtoAttributes(b, 1, resourceAttributes)
+		})
+
+		// ResourceLogs.scope_logs
+		for _, group := range otlpGroupByScope(batch) {
+			b = appendProtoMessageField(b, 2, func(b []byte) []byte {
+				// ScopeLogs.scope
+				b = appendProtoMessageField(b, 1, func(b []byte) []byte {
+					return appendProtoStringField(b, 1, group[0].scope)
+				})
+				// ScopeLogs.log_records
+				for _, record := range group {
+					b = appendProtoMessageField(b, 2, func(b []byte) []byte {
+						return appendOTLPProtoLogRecord(b, record)
+					})
+				}
+				return b
+			})
+		}
+		return b
+	})
+}
+
+// appendOTLPProtoLogRecord appends the fields of LogRecord
+func appendOTLPProtoLogRecord(b []byte, record otlpLogRecord) []byte {
+	b = appendProtoFixed64Field(b, 1, record.timeUnixNano)
+	b = appendProtoInt64Field(b, 2, int64(record.severityNumber))
+	b = appendProtoStringField(b, 3, record.severityText)
+	b = appendProtoMessageField(b, 5, func(b []byte) []byte {
+		return appendOTLPProtoAnyValue(b, record.body)
+	})
+	b = appendOTLPProtoAttributes(b, 6, record.attributes)
+	if record.traceContext.IsValid() {
+		b = appendProtoFixed32Field(b, 8, uint32(record.traceContext.TraceFlags))
+		b = appendProtoBytesField(b, 9, record.traceContext.TraceID[:])
+		b = appendProtoBytesField(b, 10, record.traceContext.SpanID[:])
+	}
+	b = appendProtoFixed64Field(b, 11, record.observedTimeUnixNano)
+	return b
+}
+
+// appendOTLPProtoAttributes appends repeated KeyValue
+func appendOTLPProtoAttributes(b []byte, field int, attributes Fields) []byte {
+	for _, attribute := range attributes {
+		b = appendProtoMessageField(b, field, func(b []byte) []byte {
+			b = appendProtoStringField(b, 1, attribute.Key)
+			return appendProtoMessageField(b, 2, func(b []byte) []byte {
+				return appendOTLPProtoAnyValue(b, attribute.Value)
+			})
+		})
+	}
+	return b
+}
+
+// appendOTLPProtoAnyValue appends the fields of AnyValue.
+// Zero values are written explicitly because the value is a member of oneof.
+func appendOTLPProtoAnyValue(b []byte, value interface{}) []byte {
+	switch v := otlpValue(value).(type) {
+	case string:
+		b = appendProtoTag(b, 1, protoWireBytes)
+		b = appendProtoVarint(b, uint64(len(v)))
+		return append(b, v...)
+	case bool:
+		b = appendProtoTag(b, 2, protoWireVarint)
+		if v {
+			return appendProtoVarint(b, 1)
+		}
+		return appendProtoVarint(b, 0)
+	case int64:
+		b = appendProtoTag(b, 3, protoWireVarint)
+		return appendProtoVarint(b, uint64(v))
+	case float64:
+		b = appendProtoTag(b, 4, protoWireFixed64)
+		return appendProtoDouble(b, v)
+	case []byte:
+		b = appendProtoTag(b, 7, protoWireBytes)
+		b = appendProtoVarint(b, uint64(len(v)))
+		return append(b, v...)
+	}
+	return b
+}
+
+// otlpJSONKeyValue is KeyValue of OTLP/JSON
+type otlpJSONKeyValue struct {
+	Key   string           `json:"key"`
+	Value otlpJSONAnyValue `json:"value"`
+}
+
+// otlpJSONAnyValue is AnyValue of OTLP/JSON. 64 bit integers are encoded as strings.
+type otlpJSONAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BytesValue  []byte   `json:"bytesValue,omitempty"`
+}
+
+// otlpJSONLogRecord is LogRecord of OTLP/JSON. Trace id and span id are hex encoded.
+type otlpJSONLogRecord struct {
+	TimeUnixNano         string             `json:"timeUnixNano,omitempty"`
+	ObservedTimeUnixNano string             `json:"observedTimeUnixNano,omitempty"`
+	SeverityNumber       int32              `json:"severityNumber,omitempty"`
+	SeverityText         string             `json:"severityText,omitempty"`
+	Body                 otlpJSONAnyValue   `json:"body"`
+	Attributes           []otlpJSONKeyValue `json:"attributes,omitempty"`
+	Flags                uint32             `json:"flags,omitempty"`
+	TraceID              string             `json:"traceId,omitempty"`
+	SpanID               string             `json:"spanId,omitempty"`
+}
+
+// otlpJSONScope is InstrumentationScope of OTLP/JSON
+type otlpJSONScope struct {
+	Name string `json:"name"`
+}
+
+// otlpJSONScopeLogs is ScopeLogs of OTLP/JSON
+type otlpJSONScopeLogs struct {
+	Scope      otlpJSONScope       `json:"scope"`
+	LogRecords []otlpJSONLogRecord `json:"logRecords"`
+}
+
+// otlpJSONResource is Resource of OTLP/JSON
+type otlpJSONResource struct {
+	Attributes []otlpJSONKeyValue `json:"attributes"`
+}
+
+// otlpJSONResourceLogs is ResourceLogs of OTLP/JSON
+type otlpJSONResourceLogs struct {
+	Resource  otlpJSONResource    `json:"resource"`
+	ScopeLogs []otlpJSONScopeLogs `json:"scopeLogs"`
+}
+
+// otlpJSONRequest is ExportLogsServiceRequest of OTLP/JSON
+type otlpJSONRequest struct {
+	ResourceLogs []otlpJSONResourceLogs `json:"resourceLogs"`
+}
+
+// encodeOTLPJSON encodes ExportLogsServiceRequest
+func encodeOTLPJSON(resourceAttributes Fields, batch []otlpLogRecord) []byte {
+	resourceLogs := otlpJSONResourceLogs{
+		Resource: otlpJSONResource{Attributes: otlpJSONAttributes(resourceAttributes)},
+	}
+
+	for _, group := range otlpGroupByScope(batch) {
+		scopeLogs := otlpJSONScopeLogs{Scope: otlpJSONScope{Name: group[0].scope}}
+		for _, record := range group {
+			jsonRecord := otlpJSONLogRecord{
+				SeverityNumber: record.severityNumber,
+				SeverityText:   record.severityText,
+				Body:           newOTLPJSONAnyValue(record.body),
+				Attributes:     otlpJSONAttributes(record.attributes),
+			}
+			if record.timeUnixNano != 0 {
+				jsonRecord.TimeUnixNano = strconv.FormatUint(record.timeUnixNano, 10)
+			}
+			if record.observedTimeUnixNano != 0 {
+				jsonRecord.ObservedTimeUnixNano = strconv.FormatUint(record.observedTimeUnixNano, 10)
+			}
+			if record.traceContext.IsValid() {
+				jsonRecord.Flags = uint32(record.traceContext.TraceFlags)
+				jsonRecord.TraceID = record.traceContext.TraceID.String()
+				jsonRecord.SpanID = record.traceContext.SpanID.String()
+			}
+			scopeLogs.LogRecords = append(scopeLogs.LogRecords, jsonRecord)
+		}
+		resourceLogs.ScopeLogs = append(resourceLogs.ScopeLogs, scopeLogs)
+	}
+
+	encoded, _ := json.Marshal(otlpJSONRequest{ResourceLogs: []otlpJSONResourceLogs{resourceLogs}})
+	return encoded
+}
+
+// otlpJSONAttributes
+func otlpJSONAttributes(attributes Fields) []otlpJSONKeyValue {
+	keyValues := make([]otlpJSONKeyValue, 0, len(attributes))
+	for _, attribute := range attributes {
+		keyValues = append(keyValues, otlpJSONKeyValue{Key: attribute.Key, Value: newOTLPJSONAnyValue(attribute.Value)})
+	}
+	return keyValues
+}
+
+// newOTLPJSONAnyValue
+func newOTLPJSONAnyValue(value interface{}) otlpJSONAnyValue {
+	var anyValue otlpJSONAnyValue
+	switch v := otlpValue(value).(type) {
+	case string:
+		anyValue.StringValue = &v
+	case bool:
+		anyValue.BoolValue = &v
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		anyValue.IntValue = &s
+	case float64:
+		anyValue.DoubleValue = &v
+	case []byte:
+		anyValue.BytesValue = v
+	}
+	return anyValue
+}
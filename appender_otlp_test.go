@@ -0,0 +1,192 @@
+package golog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOTLPCollector records export requests and responds with the given status codes in order
+type fakeOTLPCollector struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests [][]byte
+	headers  []http.Header
+	statuses []int
+}
+
+func newFakeOTLPCollector(statuses ...int) *fakeOTLPCollector {
+	collector := &fakeOTLPCollector{statuses: statuses}
+	collector.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		collector.mu.Lock()
+		collector.requests = append(collector.requests, body)
+		collector.headers = append(collector.headers, r.Header.Clone())
+		status := http.StatusOK
+		if len(collector.statuses) > 0 {
+			status = collector.statuses[0]
+			collector.statuses = collector.statuses[1:]
+		}
+		collector.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	return collector
+}
+
+func (collector *fakeOTLPCollector) received() [][]byte {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	return append([][]byte(nil), collector.requests...)
+}
+
+func TestOTLPAppender_JSON(t *testing.T) {
+	collector := newFakeOTLPCollector()
+	defer collector.Close()
+
+	appender, err := NewOTLPAppender(collector.URL,
+		WithOTLPEncoding(OTLPEncoding_JSON),
+		WithOTLPHeaders(map[string]string{"Authorization": "Bearer token"}),
+		WithOTLPResourceAttributes("service.name", "testService"))
+	assert.Nil(t, err)
+
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	logger.WithContext(ContextWithTraceContext(context.Background(), traceContext)).Warnw("message", "count", 3, "ok", true)
+	assert.Nil(t, appender.Close())
+
+	requests := collector.received()
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, "application/json", collector.headers[0].Get("Content-Type"))
+	assert.Equal(t, "Bearer token", collector.headers[0].Get("Authorization"))
+
+	var request otlpJSONRequest
+	assert.Nil(t, json.Unmarshal(requests[0], &request))
+
+	resourceLogs := request.ResourceLogs[0]
+	assert.Equal(t, "service.name", resourceLogs.Resource.Attributes[0].Key)
+	assert.Equal(t, "testService", *resourceLogs.Resource.Attributes[0].Value.StringValue)
+	assert.Equal(t, "testLogger", resourceLogs.ScopeLogs[0].Scope.Name)
+
+	record := resourceLogs.ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, int32(13), record.SeverityNumber)
+	assert.Equal(t, "WARN", record.SeverityText)
+	assert.Equal(t, "message", *record.Body.StringValue)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", record.SpanID)
+	assert.Equal(t, uint32(1), record.Flags)
+	assert.NotEmpty(t, record.TimeUnixNano)
+	assert.NotEmpty(t, record.ObservedTimeUnixNano)
+
+	attributes := map[string]otlpJSONAnyValue{}
+	for _, attribute := range record.Attributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+	assert.Equal(t, "appender_otlp_test.go", filepath.Base(*attributes["code.filepath"].StringValue))
+	assert.Equal(t, "3", *attributes["count"].IntValue)
+	assert.Equal(t, true, *attributes["ok"].BoolValue)
+}
+
+func TestOTLPAppender_Protobuf(t *testing.T) {
+	collector := newFakeOTLPCollector()
+	defer collector.Close()
+
+	appender, err := NewOTLPAppender(collector.URL + "/custom/logs")
+	assert.Nil(t, err)
+
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	logger.ErrorCtx(ContextWithTraceContext(context.Background(), traceContext), "message")
+	assert.Nil(t, appender.Close())
+
+	requests := collector.received()
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, "application/x-protobuf", collector.headers[0].Get("Content-Type"))
+
+	resourceLogs := decodeProto(t, requests[0]).message(t, 1, 0)
+	resourceAttribute := resourceLogs.message(t, 1, 0).message(t, 1, 0)
+	assert.Equal(t, "service.name", resourceAttribute.string(1))
+
+	scopeLogs := resourceLogs.message(t, 2, 0)
+	assert.Equal(t, "testLogger", scopeLogs.message(t, 1, 0).string(1))
+
+	record := scopeLogs.message(t, 2, 0)
+	assert.Equal(t, uint64(17), record[2][0])
+	assert.Equal(t, "ERROR", record.string(3))
+	assert.Equal(t, "message", record.message(t, 5, 0).string(1))
+	assert.Equal(t, uint32(1), record[8][0])
+	assert.Equal(t, traceContext.TraceID[:], record[9][0])
+	assert.Equal(t, traceContext.SpanID[:], record[10][0])
+	assert.NotNil(t, record[11])
+
+	lineno := record.message(t, 6, 1)
+	assert.Equal(t, "code.lineno", lineno.string(1))
+	assert.Equal(t, uint64(108), lineno.message(t, 2, 0)[3][0])
+}
+
+func TestOTLPAppender_Batch(t *testing.T) {
+	collector := newFakeOTLPCollector()
+	defer collector.Close()
+
+	appender, err := NewOTLPAppender(collector.URL, WithOTLPBatchSize(2), WithOTLPFlushInterval(time.Hour))
+	assert.Nil(t, err)
+
+	for i := 0; i < 5; i++ {
+		appender.WriteLevel(LogLevel_INFO, []byte("message\n"))
+	}
+	assert.Nil(t, appender.Close())
+
+	assert.Equal(t, 3, len(collector.received()))
+
+	_, err = appender.Write([]byte("closed"))
+	assert.NotNil(t, err)
+}
+
+func TestOTLPAppender_Retry(t *testing.T) {
+
+	t.Run("retryable status is retried", func(t *testing.T) {
+		collector := newFakeOTLPCollector(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		defer collector.Close()
+
+		appender, err := NewOTLPAppender(collector.URL, WithOTLPRetry(3, time.Millisecond, 2*time.Millisecond))
+		assert.Nil(t, err)
+
+		appender.Write([]byte("message"))
+		assert.Nil(t, appender.Close())
+
+		requests := collector.received()
+		assert.Equal(t, 3, len(requests))
+		assert.Equal(t, requests[0], requests[2])
+	})
+
+	t.Run("other status is not retried", func(t *testing.T) {
+		collector := newFakeOTLPCollector(http.StatusBadRequest)
+		defer collector.Close()
+
+		appender, err := NewOTLPAppender(collector.URL, WithOTLPRetry(3, time.Millisecond, 2*time.Millisecond))
+		assert.Nil(t, err)
+
+		appender.Write([]byte("message"))
+		assert.Nil(t, appender.Close())
+
+		assert.Equal(t, 1, len(collector.received()))
+	})
+}
+
+func TestOTLPSeverityNumber(t *testing.T) {
+	assert.Equal(t, int32(1), otlpSeverityNumber(LogLevel_TRACE))
+	assert.Equal(t, int32(5), otlpSeverityNumber(LogLevel_DEBUG))
+	assert.Equal(t, int32(9), otlpSeverityNumber(LogLevel_INFO))
+	assert.Equal(t, int32(13), otlpSeverityNumber(LogLevel_WARN))
+	assert.Equal(t, int32(17), otlpSeverityNumber(LogLevel_ERROR))
+	assert.Equal(t, int32(21), otlpSeverityNumber(LogLevel_FATAL))
+}
@@ -0,0 +1,416 @@
+package golog
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RollingPeriod
+type RollingPeriod int
+
+// RollingPeriod Constants
+const (
+	RollingPeriod_NONE   RollingPeriod = 0
+	RollingPeriod_HOURLY RollingPeriod = 1
+	RollingPeriod_DAILY  RollingPeriod = 2
+)
+
+// layout returns the time layout used in backup file names
+func (period RollingPeriod) layout() string {
+	if period == RollingPeriod_HOURLY {
+		return "2006-01-02-15"
+	}
+	return "2006-01-02"
+}
+
+// truncate returns the start of the period containing t
+func (period RollingPeriod) truncate(t time.Time) time.Time {
+	if period == RollingPeriod_HOURLY {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// compressedSuffix
+const compressedSuffix = ".gz"
+
+// RollingFileAppender is a FileAppender which rotates the file
+// when it exceeds the max size and/or when the time period changes.
+//
+// The rotated file is renamed to "<fileName>.<date>.<index>",
+// e.g. "app.log.2026-10-18.1", and optionally gzip compressed in background.
+// Old backups are removed by the max number of backups and the max age.
+type RollingFileAppender struct {
+	fileName   string
+	bufferSize int
+	maxSize    int64
+	period     RollingPeriod
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	now        func() time.Time
+	rename     func(oldName string, newName string) error
+
+	// fileAppender is nil if the file could not be opened by roll. It is opened again by the next write.
+	fileAppender *FileAppender
+	size         int64
+	periodStart  time.Time
+
+	millCh chan struct{}
+	wg     *sync.WaitGroup
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// RollingOption
+type RollingOption func(appender *RollingFileAppender)
+
+// WithRollingMaxSize rotates the file when it exceeds size bytes
+func WithRollingMaxSize(size int64) RollingOption {
+	return func(appender *RollingFileAppender) {
+		appender.maxSize = size
+	}
+}
+
+// WithRollingPeriod rotates the file at every period boundary
+func WithRollingPeriod(period RollingPeriod) RollingOption {
+	return func(appender *RollingFileAppender) {
+		appender.period = period
+	}
+}
+
+// WithRollingMaxBackups keeps at most n backups. Zero keeps all.
+func WithRollingMaxBackups(n int) RollingOption {
+	return func(appender *RollingFileAppender) {
+		appender.maxBackups = n
+	}
+}
+
+// WithRollingMaxAge removes backups older than age. Zero keeps all.
+func WithRollingMaxAge(age time.Duration) RollingOption {
+	return func(appender *RollingFileAppender) {
+		appender.maxAge = age
+	}
+}
+
+// WithRollingCompress compresses backups by gzip in background
+func WithRollingCompress() RollingOption {
+	return func(appender *RollingFileAppender) {
+		appender.compress = true
+	}
+}
+
+// WithRollingBufferSize sets the buffer size of the underlying FileAppender
+func WithRollingBufferSize(size int) RollingOption {
+	return func(appender *RollingFileAppender) {
+		if size > 0 {
+			appender.bufferSize = size
+		}
+	}
+}
+
+// NewRollingFileAppender returns new RollingFileAppender
+func NewRollingFileAppender(fileName string, options ...RollingOption) (*RollingFileAppender, error) {
+	appender := &RollingFileAppender{
+		fileName:   fileName,
+		bufferSize: defaultBufferSize,
+		period:     RollingPeriod_NONE,
+		now:        time.Now,
+		rename:     os.Rename,
+		millCh:     make(chan struct{}, 1),
+		wg:         new(sync.WaitGroup),
+		mu:         new(sync.Mutex),
+		activated:  true,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	if err := appender.open(); err != nil {
+		return nil, err
+	}
+
+	appender.wg.Add(1)
+	go appender.millLoop()
+
+	return appender, nil
+}
+
+// open opens the file and restores its size and period
+func (appender *RollingFileAppender) open() error {
+	fileAppender, err := openFileAppender(appender.fileName, appender.bufferSize)
+	if err != nil {
+		return err
+	}
+
+	appender.fileAppender = fileAppender
+	appender.size = 0
+	appender.periodStart = appender.period.truncate(appender.now())
+
+	if info, err := fileAppender.file.Stat(); err == nil && info.Size() > 0 {
+		appender.size = info.Size()
+		appender.periodStart = appender.period.truncate(info.ModTime())
+	}
+	return nil
+}
+
+// Write implements io.Writer
+func (appender *RollingFileAppender) Write(data []byte) (n int, err error) {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return 0, errors.New("golog: rolling file appender is closed")
+	}
+
+	if appender.fileAppender == nil {
+		if err := appender.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	length := int64(len(data) + 1)
+	now := appender.now()
+
+	if appender.shouldRoll(now, length) {
+		if err := appender.roll(now); err != nil {
+			if appender.fileAppender == nil {
+				return 0, err
+			}
+			// the current file is kept, and rolled again by the next write
+			warnLogger.Warnf("golog: roll %s is failed , error : %s", appender.fileName, err.Error())
+		}
+	}
+
+	n, err = appender.fileAppender.Write(data)
+	appender.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer.
+// Close waits until background compression and removal is finished.
+func (appender *RollingFileAppender) Close() error {
+	appender.mu.Lock()
+	if !appender.activated {
+		appender.mu.Unlock()
+		return nil
+	}
+	appender.activated = false
+	var err error
+	if appender.fileAppender != nil {
+		err = appender.fileAppender.Close()
+	}
+	close(appender.millCh)
+	appender.mu.Unlock()
+
+	appender.wg.Wait()
+	return err
+}
+
+// shouldRoll
+func (appender *RollingFileAppender) shouldRoll(now time.Time, length int64) bool {
+	if appender.period != RollingPeriod_NONE && !appender.period.truncate(now).Equal(appender.periodStart) {
+		return true
+	}
+	return appender.maxSize > 0 && appender.size > 0 && appender.size+length > appender.maxSize
+}
+
+// roll renames the current file to the backup name and opens new file. The caller must hold mu.
+// If the file cannot be renamed, the current file is opened again.
+// If the file cannot be opened, fileAppender is nil and the file is opened by the next write.
+func (appender *RollingFileAppender) roll(now time.Time) error {
+	err := appender.fileAppender.Close()
+	appender.fileAppender = nil
+	if err != nil {
+		appender.open()
+		return err
+	}
+
+	stamp := appender.periodStart.Format(appender.period.layout())
+	backupName := appender.fileName + "." + stamp + "." + strconv.Itoa(appender.nextBackupIndex(stamp))
+	if err := appender.rename(appender.fileName, backupName); err != nil {
+		appender.open()
+		return err
+	}
+
+	if err := appender.open(); err != nil {
+		return err
+	}
+	appender.periodStart = appender.period.truncate(now)
+
+	select {
+	case appender.millCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// nextBackupIndex returns the next index of backups for stamp
+func (appender *RollingFileAppender) nextBackupIndex(stamp string) int {
+	index := 0
+	for _, backup := range appender.backups() {
+		if backup.stamp == stamp && backup.index > index {
+			index = backup.index
+		}
+	}
+	return index + 1
+}
+
+// rollingBackup
+type rollingBackup struct {
+	path    string
+	stamp   string
+	index   int
+	modTime time.Time
+}
+
+// backups lists backups of the file
+func (appender *RollingFileAppender) backups() []rollingBackup {
+	dir, base := filepath.Split(appender.fileName)
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var backups []rollingBackup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(name, base+"."), compressedSuffix), ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		index, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+
+		if _, err := time.Parse(appender.period.layout(), parts[0]); err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, rollingBackup{
+			path:    filepath.Join(dir, name),
+			stamp:   parts[0],
+			index:   index,
+			modTime: info.ModTime(),
+		})
+	}
+	return backups
+}
+
+// millLoop compresses and removes backups in background
+func (appender *RollingFileAppender) millLoop() {
+	defer appender.wg.Done()
+
+	for range appender.millCh {
+		appender.mill()
+	}
+}
+
+// mill
+func (appender *RollingFileAppender) mill() {
+	if appender.compress {
+		for _, backup := range appender.backups() {
+			if strings.HasSuffix(backup.path, compressedSuffix) {
+				continue
+			}
+			if err := compressFile(backup.path); err != nil {
+				warnLogger.Warnf("compress %s is failed , error : %s", backup.path, err.Error())
+			}
+		}
+	}
+
+	if appender.maxBackups <= 0 && appender.maxAge <= 0 {
+		return
+	}
+
+	backups := appender.backups()
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].stamp != backups[j].stamp {
+			return backups[i].stamp > backups[j].stamp
+		}
+		return backups[i].index > backups[j].index
+	})
+
+	cutoff := appender.now().Add(-appender.maxAge)
+	for i, backup := range backups {
+		if (appender.maxBackups > 0 && i >= appender.maxBackups) ||
+			(appender.maxAge > 0 && backup.modTime.Before(cutoff)) {
+			if err := os.Remove(backup.path); err != nil {
+				warnLogger.Warnf("remove %s is failed , error : %s", backup.path, err.Error())
+			}
+		}
+	}
+}
+
+// compressFile compresses src to src.gz and removes src
+func compressFile(src string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst := src + compressedSuffix
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(out)
+	if _, err = io.Copy(gzipWriter, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err = gzipWriter.Close(); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err = out.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+
+	in.Close()
+	return os.Remove(src)
+}
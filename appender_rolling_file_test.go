@@ -0,0 +1,181 @@
+package golog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRollingFileAppender_Write(t *testing.T) {
+
+	today := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+
+	t.Run("file is rotated when it exceeds max size", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		appender, err := NewRollingFileAppender(fileName, WithRollingMaxSize(12), WithRollingBufferSize(1))
+		assert.Nil(t, err)
+		appender.now = func() time.Time { return today }
+		appender.periodStart = today
+
+		appender.Write([]byte("test1"))
+		appender.Write([]byte("test2"))
+		appender.Write([]byte("test3"))
+		assert.Nil(t, appender.Close())
+
+		assert.Equal(t, "test1\ntest2\n", readFile(t, fileName+".2026-10-18.1"))
+		assert.Equal(t, "test3\n", readFile(t, fileName))
+	})
+
+	t.Run("file is rotated at period boundary", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		appender, _ := NewRollingFileAppender(fileName, WithRollingPeriod(RollingPeriod_HOURLY))
+		now := today
+		appender.now = func() time.Time { return now }
+		appender.periodStart = RollingPeriod_HOURLY.truncate(today)
+
+		appender.Write([]byte("test1"))
+		now = today.Add(time.Hour)
+		appender.Write([]byte("test2"))
+		assert.Nil(t, appender.Close())
+
+		assert.Equal(t, "test1\n", readFile(t, fileName+".2026-10-18-12.1"))
+		assert.Equal(t, "test2\n", readFile(t, fileName))
+	})
+
+	t.Run("index is incremented for the same date", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		appender, _ := NewRollingFileAppender(fileName, WithRollingMaxSize(6), WithRollingBufferSize(1))
+		appender.now = func() time.Time { return today }
+		appender.periodStart = today
+
+		appender.Write([]byte("test1"))
+		appender.Write([]byte("test2"))
+		appender.Write([]byte("test3"))
+		assert.Nil(t, appender.Close())
+
+		assert.Equal(t, "test1\n", readFile(t, fileName+".2026-10-18.1"))
+		assert.Equal(t, "test2\n", readFile(t, fileName+".2026-10-18.2"))
+	})
+
+	t.Run("backups are compressed and removed by max backups", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		appender, _ := NewRollingFileAppender(fileName,
+			WithRollingMaxSize(6),
+			WithRollingBufferSize(1),
+			WithRollingMaxBackups(2),
+			WithRollingCompress())
+		appender.now = func() time.Time { return today }
+		appender.periodStart = today
+
+		for _, data := range []string{"test1", "test2", "test3", "test4"} {
+			appender.Write([]byte(data))
+		}
+		assert.Nil(t, appender.Close())
+
+		_, err := os.Stat(fileName + ".2026-10-18.1.gz")
+		assert.True(t, os.IsNotExist(err))
+
+		file, err := os.Open(fileName + ".2026-10-18.3.gz")
+		assert.Nil(t, err)
+		defer file.Close()
+		reader, err := gzip.NewReader(file)
+		assert.Nil(t, err)
+		data, _ := io.ReadAll(reader)
+		assert.Equal(t, "test3\n", string(data))
+	})
+
+	t.Run("backups older than max age are removed", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		old := fileName + ".2026-10-01.1"
+		os.WriteFile(old, []byte("old"), 0666)
+		os.Chtimes(old, today.AddDate(0, 0, -17), today.AddDate(0, 0, -17))
+
+		appender, _ := NewRollingFileAppender(fileName, WithRollingMaxSize(6), WithRollingMaxAge(7*24*time.Hour))
+		appender.now = func() time.Time { return today }
+		appender.periodStart = today
+
+		appender.Write([]byte("test1"))
+		appender.Write([]byte("test2"))
+		assert.Nil(t, appender.Close())
+
+		_, err := os.Stat(old)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(fileName + ".2026-10-18.1")
+		assert.Nil(t, err)
+	})
+
+	t.Run("current file is kept if it cannot be renamed", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		appender, err := NewRollingFileAppender(fileName, WithRollingMaxSize(12), WithRollingBufferSize(1))
+		assert.Nil(t, err)
+		appender.now = func() time.Time { return today }
+		appender.periodStart = today
+		appender.rename = func(oldName string, newName string) error {
+			return &os.LinkError{Op: "rename", Old: oldName, New: newName, Err: syscall.EXDEV}
+		}
+
+		appender.Write([]byte("test1"))
+		appender.Write([]byte("test2"))
+		_, err = appender.Write([]byte("test3"))
+		assert.Nil(t, err)
+
+		appender.rename = os.Rename
+		appender.Write([]byte("test4"))
+		assert.Nil(t, appender.Close())
+
+		assert.Equal(t, "test1\ntest2\ntest3\n", readFile(t, fileName+".2026-10-18.1"))
+		assert.Equal(t, "test4\n", readFile(t, fileName))
+	})
+
+	t.Run("file is opened by the next write if it cannot be opened", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		appender, err := NewRollingFileAppender(fileName, WithRollingMaxSize(12), WithRollingBufferSize(1))
+		assert.Nil(t, err)
+		appender.now = func() time.Time { return today }
+		appender.periodStart = today
+		appender.rename = func(oldName string, newName string) error {
+			if err := os.Rename(oldName, newName); err != nil {
+				return err
+			}
+			// a directory of the same name makes opening the file fail
+			return os.Mkdir(oldName, 0755)
+		}
+
+		appender.Write([]byte("test1"))
+		appender.Write([]byte("test2"))
+		_, err = appender.Write([]byte("test3"))
+		assert.NotNil(t, err)
+		_, err = appender.Write([]byte("test4"))
+		assert.NotNil(t, err)
+
+		assert.Nil(t, os.Remove(fileName))
+		appender.rename = os.Rename
+		_, err = appender.Write([]byte("test5"))
+		assert.Nil(t, err)
+		assert.Nil(t, appender.Close())
+
+		assert.Equal(t, "test1\ntest2\n", readFile(t, fileName+".2026-10-18.1"))
+		assert.Equal(t, "test5\n", readFile(t, fileName))
+	})
+
+	t.Run("returns error after Close", func(t *testing.T) {
+		appender, _ := NewRollingFileAppender(filepath.Join(t.TempDir(), "app.log"))
+		appender.Close()
+		_, err := appender.Write([]byte("test"))
+		assert.NotNil(t, err)
+	})
+}
@@ -1 +1,587 @@
 package golog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultS3KeyTemplate
+// {start} and {id} keep the keys of a restarted process from overwriting the objects of the previous one.
+const defaultS3KeyTemplate = "{loggerName}/{yyyy}/{MM}/{dd}/{host}-{start}-{id}-{seq}.log.gz"
+
+// defaultS3MaxObjectSize is the uncompressed size of an object
+const defaultS3MaxObjectSize = 16 * 1024 * 1024
+
+// defaultS3MaxObjectAge
+const defaultS3MaxObjectAge = 5 * time.Minute
+
+// defaultS3MaxRetries
+const defaultS3MaxRetries = 3
+
+// defaultS3RetryInterval is doubled on each retry up to defaultS3MaxRetryInterval
+const defaultS3RetryInterval = 500 * time.Millisecond
+
+// defaultS3MaxRetryInterval
+const defaultS3MaxRetryInterval = 30 * time.Second
+
+// s3UploadQueueSize
+const s3UploadQueueSize = 16
+
+// S3Appender accumulates events into gzip compressed objects
+// and uploads them to an S3 compatible storage by PutObject.
+//
+// An object is rolled when the uncompressed size exceeds the max object size
+// or when it becomes older than the max object age.
+// Uploads are done in background and retried on failure.
+type S3Appender struct {
+	endpoint        *url.URL
+	bucket          string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	virtualHosted   bool
+
+	keyTemplate      string
+	loggerName       string
+	hostName         string
+	startedAt        time.Time
+	instanceID       string
+	maxObjectSize    int
+	maxObjectAge     time.Duration
+	maxRetries       int
+	retryInterval    time.Duration
+	maxRetryInterval time.Duration
+	client           *http.Client
+	now              func() time.Time
+
+	buffer     *bytes.Buffer
+	gzipWriter *gzip.Writer
+	size       int
+	createdAt  time.Time
+	seq        int
+
+	uploads chan s3Object
+	done    chan struct{}
+	wg      *sync.WaitGroup
+
+	// enqueueing counts rolled objects being sent to uploads without mu
+	enqueueing *sync.WaitGroup
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// s3Object is a rolled object waiting for upload
+type s3Object struct {
+	key  string
+	body []byte
+}
+
+// S3Option
+type S3Option func(appender *S3Appender)
+
+// WithS3Region sets the region used for signing. The default is "us-east-1".
+func WithS3Region(region string) S3Option {
+	return func(appender *S3Appender) {
+		appender.region = region
+	}
+}
+
+// WithS3Credentials sets the credentials used for signing.
+// If not specified, the values of AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_SESSION_TOKEN are used. Requests are unsigned if no credentials are found.
+func WithS3Credentials(accessKeyID, secretAccessKey, sessionToken string) S3Option {
+	return func(appender *S3Appender) {
+		appender.accessKeyID = accessKeyID
+		appender.secretAccessKey = secretAccessKey
+		appender.sessionToken = sessionToken
+	}
+}
+
+// WithS3VirtualHostedStyle addresses the bucket as a subdomain of the endpoint
+// instead of the first path segment.
+func WithS3VirtualHostedStyle() S3Option {
+	return func(appender *S3Appender) {
+		appender.virtualHosted = true
+	}
+}
+
+// WithS3KeyTemplate sets the object key template.
+//
+// Supported placeholders are {loggerName}, {host}, {seq},
+// {yyyy}, {MM}, {dd}, {HH}, {mm} and {ss} (UTC time of the first event in the object),
+// {start} (UTC time at which the appender is created, e.g. 20261018T010203Z) and {id} (random id of the appender).
+// {seq} restarts from 1 in each process, so that the template should contain {start} or {id} with {seq}.
+func WithS3KeyTemplate(template string) S3Option {
+	return func(appender *S3Appender) {
+		if template != "" {
+			appender.keyTemplate = template
+		}
+	}
+}
+
+// WithS3LoggerName sets the value of {loggerName}
+func WithS3LoggerName(loggerName string) S3Option {
+	return func(appender *S3Appender) {
+		appender.loggerName = loggerName
+	}
+}
+
+// WithS3MaxObjectSize sets the uncompressed size at which an object is rolled
+func WithS3MaxObjectSize(size int) S3Option {
+	return func(appender *S3Appender) {
+		if size > 0 {
+			appender.maxObjectSize = size
+		}
+	}
+}
+
+// WithS3MaxObjectAge sets the age at which an object is rolled
+func WithS3MaxObjectAge(age time.Duration) S3Option {
+	return func(appender *S3Appender) {
+		if age > 0 {
+			appender.maxObjectAge = age
+		}
+	}
+}
+
+// WithS3Retry sets the number of retries, the initial retry interval and the max retry interval
+func WithS3Retry(maxRetries int, interval time.Duration, maxInterval time.Duration) S3Option {
+	return func(appender *S3Appender) {
+		if maxRetries >= 0 {
+			appender.maxRetries = maxRetries
+		}
+		if interval > 0 {
+			appender.retryInterval = interval
+		}
+		if maxInterval > 0 {
+			appender.maxRetryInterval = maxInterval
+		}
+	}
+}
+
+// WithS3HTTPClient sets the http client used for uploads
+func WithS3HTTPClient(client *http.Client) S3Option {
+	return func(appender *S3Appender) {
+		if client != nil {
+			appender.client = client
+		}
+	}
+}
+
+// NewS3Appender returns new S3Appender.
+//
+// endpoint is the base url of the storage, e.g. "https://s3.ap-northeast-1.amazonaws.com"
+// or "http://localhost:9000" for MinIO.
+func NewS3Appender(endpoint string, bucket string, options ...S3Option) (*S3Appender, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpointURL.Scheme == "" || endpointURL.Host == "" {
+		return nil, fmt.Errorf("golog: invalid s3 endpoint %q", endpoint)
+	}
+
+	if bucket == "" {
+		return nil, errors.New("golog: s3 bucket is empty")
+	}
+
+	hostName, _ := os.Hostname()
+
+	appender := &S3Appender{
+		endpoint:         endpointURL,
+		bucket:           bucket,
+		region:           "us-east-1",
+		accessKeyID:      os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey:  os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:     os.Getenv("AWS_SESSION_TOKEN"),
+		keyTemplate:      defaultS3KeyTemplate,
+		loggerName:       "defaultLogger",
+		hostName:         hostName,
+		startedAt:        time.Now(),
+		instanceID:       newS3InstanceID(),
+		maxObjectSize:    defaultS3MaxObjectSize,
+		maxObjectAge:     defaultS3MaxObjectAge,
+		maxRetries:       defaultS3MaxRetries,
+		retryInterval:    defaultS3RetryInterval,
+		maxRetryInterval: defaultS3MaxRetryInterval,
+		client:           http.DefaultClient,
+		now:              time.Now,
+		buffer:           new(bytes.Buffer),
+		uploads:          make(chan s3Object, s3UploadQueueSize),
+		done:             make(chan struct{}),
+		wg:               new(sync.WaitGroup),
+		enqueueing:       new(sync.WaitGroup),
+		mu:               new(sync.Mutex),
+		activated:        true,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	appender.gzipWriter = gzip.NewWriter(appender.buffer)
+
+	appender.wg.Add(2)
+	go appender.uploadLoop()
+	go appender.rollLoop()
+
+	return appender, nil
+}
+
+// Write implements io.Writer
+func (appender *S3Appender) Write(data []byte) (n int, err error) {
+	appender.mu.Lock()
+
+	if !appender.activated {
+		appender.mu.Unlock()
+		return 0, errors.New("golog: s3 appender is closed")
+	}
+
+	if appender.size == 0 {
+		appender.createdAt = appender.now()
+	}
+
+	if _, err := appender.gzipWriter.Write(data); err != nil {
+		appender.mu.Unlock()
+		return 0, err
+	}
+	if _, err := appender.gzipWriter.Write([]byte{'\n'}); err != nil {
+		appender.mu.Unlock()
+		return 0, err
+	}
+	appender.size += len(data) + 1
+
+	var object *s3Object
+	if appender.size >= appender.maxObjectSize {
+		object = appender.roll()
+	}
+	appender.mu.Unlock()
+
+	// the upload queue may be full while uploads are retried, so that it is not sent holding mu
+	appender.enqueue(object)
+	return len(data), nil
+}
+
+// Close implements io.Closer.
+// The current object is rolled and Close waits until all objects are uploaded.
+// Failed uploads are retried without waiting for the retry interval after Close.
+func (appender *S3Appender) Close() error {
+	appender.mu.Lock()
+	if !appender.activated {
+		appender.mu.Unlock()
+		return nil
+	}
+	appender.activated = false
+	object := appender.roll()
+	appender.mu.Unlock()
+
+	appender.enqueue(object)
+	appender.enqueueing.Wait()
+	close(appender.done)
+	close(appender.uploads)
+
+	appender.wg.Wait()
+	return nil
+}
+
+// roll closes the current object and returns it, or nil if the object is empty.
+// The caller must hold mu, and pass the object to enqueue after releasing mu.
+func (appender *S3Appender) roll() *s3Object {
+	if appender.size == 0 {
+		return nil
+	}
+
+	if err := appender.gzipWriter.Close(); err != nil {
+		warnLogger.Warnf("golog: compress s3 object is failed , error : %s", err.Error())
+	}
+
+	body := make([]byte, appender.buffer.Len())
+	copy(body, appender.buffer.Bytes())
+
+	appender.seq++
+	object := &s3Object{
+		key:  appender.objectKey(appender.createdAt, appender.seq),
+		body: body,
+	}
+
+	appender.buffer.Reset()
+	appender.gzipWriter.Reset(appender.buffer)
+	appender.size = 0
+
+	// Close waits for the object to be enqueued before closing uploads
+	appender.enqueueing.Add(1)
+	return object
+}
+
+// enqueue queues the rolled object for upload. It blocks while the upload queue is full.
+func (appender *S3Appender) enqueue(object *s3Object) {
+	if object == nil {
+		return
+	}
+	appender.uploads <- *object
+	appender.enqueueing.Done()
+}
+
+// objectKey expands the key template
+func (appender *S3Appender) objectKey(createdAt time.Time, seq int) string {
+	t := createdAt.UTC()
+	return strings.NewReplacer(
+		"{loggerName}", appender.loggerName,
+		"{host}", appender.hostName,
+		"{seq}", strconv.Itoa(seq),
+		"{start}", appender.startedAt.UTC().Format("20060102T150405Z"),
+		"{id}", appender.instanceID,
+		"{yyyy}", fmt.Sprintf("%04d", t.Year()),
+		"{MM}", fmt.Sprintf("%02d", t.Month()),
+		"{dd}", fmt.Sprintf("%02d", t.Day()),
+		"{HH}", fmt.Sprintf("%02d", t.Hour()),
+		"{mm}", fmt.Sprintf("%02d", t.Minute()),
+		"{ss}", fmt.Sprintf("%02d", t.Second()),
+	).Replace(appender.keyTemplate)
+}
+
+// rollLoop rolls the current object when it becomes older than max object age
+func (appender *S3Appender) rollLoop() {
+	defer appender.wg.Done()
+
+	ticker := time.NewTicker(appender.maxObjectAge / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-appender.done:
+			return
+		case <-ticker.C:
+			var object *s3Object
+			appender.mu.Lock()
+			if appender.activated && appender.size > 0 && appender.now().Sub(appender.createdAt) >= appender.maxObjectAge {
+				object = appender.roll()
+			}
+			appender.mu.Unlock()
+			appender.enqueue(object)
+		}
+	}
+}
+
+// uploadLoop
+func (appender *S3Appender) uploadLoop() {
+	defer appender.wg.Done()
+
+	for object := range appender.uploads {
+		if err := appender.putObjectWithRetry(object); err != nil {
+			warnLogger.Warnf("golog: upload s3 object %s is failed , error : %s", object.key, err.Error())
+		}
+	}
+}
+
+// putObjectWithRetry
+func (appender *S3Appender) putObjectWithRetry(object s3Object) error {
+	interval := appender.retryInterval
+
+	var err error
+	for attempt := 0; attempt <= appender.maxRetries; attempt++ {
+		if attempt > 0 {
+			appender.sleep(interval)
+			interval *= 2
+			if interval > appender.maxRetryInterval {
+				interval = appender.maxRetryInterval
+			}
+		}
+
+		if err = appender.putObject(object); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// sleep waits for d. It returns early if the appender is closed, so that Close does not wait for the retry interval.
+func (appender *S3Appender) sleep(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-appender.done:
+	}
+}
+
+// putObject
+func (appender *S3Appender) putObject(object s3Object) error {
+	request, err := http.NewRequest(http.MethodPut, appender.objectURL(object.key), bytes.NewReader(object.body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/gzip")
+
+	if appender.accessKeyID != "" {
+		signS3Request(request, sha256Hex(object.body), appender.region,
+			appender.accessKeyID, appender.secretAccessKey, appender.sessionToken, appender.now())
+	}
+
+	response, err := appender.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("golog: s3 put object returns %s : %s", response.Status, string(message))
+	}
+
+	io.Copy(io.Discard, response.Body)
+	return nil
+}
+
+// objectURL
+func (appender *S3Appender) objectURL(key string) string {
+	u := *appender.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+
+	if appender.virtualHosted {
+		u.Host = appender.bucket + "." + u.Host
+		u.Path = base + "/" + key
+	} else {
+		u.Path = base + "/" + appender.bucket + "/" + key
+	}
+	u.RawPath = s3EscapePath(u.Path)
+	return u.String()
+}
+
+// signS3Request signs request by AWS Signature Version 4.
+// The host and all headers set in request are signed.
+func signS3Request(request *http.Request, payloadHash, region, accessKeyID, secretAccessKey, sessionToken string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	host := request.Host
+	if host == "" {
+		host = request.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for key, values := range request.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		s3EscapePath(request.URL.Path),
+		canonicalS3Query(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// canonicalS3Query
+func canonicalS3Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3EscapePath escapes path by RFC 3986 keeping '/'
+func s3EscapePath(path string) string {
+	return s3Escape(path, false)
+}
+
+// s3Escape escapes s by RFC 3986
+func s3Escape(s string, escapeSlash bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			builder.WriteByte(c)
+		case c == '/' && !escapeSlash:
+			builder.WriteByte(c)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", c)
+		}
+	}
+	return builder.String()
+}
+
+// sha256Hex
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// newS3InstanceID returns 32bit random id in hex
+func newS3InstanceID() string {
+	id := make([]byte, 4)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
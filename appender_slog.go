@@ -0,0 +1,98 @@
+package golog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogAppender writes events to a slog.Handler.
+//
+// Each event becomes a slog.Record of the mapped level. The message of the event is the message of the record,
+// and the fields, the logger name and the trace context are added as attributes.
+// The time and the source of the metadata are used as the time and the pc of the record,
+// so that the handler prints them in its own format.
+type SlogAppender struct {
+	handler slog.Handler
+}
+
+// NewSlogAppender returns new SlogAppender
+func NewSlogAppender(handler slog.Handler) *SlogAppender {
+	return &SlogAppender{
+		handler: handler,
+	}
+}
+
+// Write implements io.Writer. The encoded event is written as the message at slog.LevelInfo.
+func (appender *SlogAppender) Write(data []byte) (n int, err error) {
+	return appender.WriteLevel(LogLevel_INFO, data)
+}
+
+// WriteLevel implements LevelWriter. The encoded event is written as the message.
+func (appender *SlogAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	ctx := context.Background()
+	slogLevel := slogLevelFromLogLevel(level)
+
+	if !appender.handler.Enabled(ctx, slogLevel) {
+		return len(data), nil
+	}
+
+	if err := appender.handler.Handle(ctx, slog.NewRecord(time.Now(), slogLevel, string(data), 0)); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteEvent implements EventWriter
+func (appender *SlogAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	record := NewRecord(level, metadata, event)
+	return appender.WriteRecord(&record)
+}
+
+// WriteRecord implements RecordWriter
+func (appender *SlogAppender) WriteRecord(record *Record) error {
+	ctx := context.Background()
+	slogLevel := slogLevelFromLogLevel(record.Level)
+
+	if !appender.handler.Enabled(ctx, slogLevel) {
+		return nil
+	}
+
+	t := time.Now()
+	var pc uintptr
+	metadata := record.Metadata
+	if metadata != nil {
+		if metadata.IsEnabledTime {
+			t = time.Unix(metadata.UnixTime, int64(metadata.Nanosecond))
+		}
+		if metadata.IsEnabledSourceFile || metadata.IsEnabledSourceLine {
+			pc = metadata.PC
+		}
+	}
+
+	slogRecord := slog.NewRecord(t, slogLevel, record.Message, pc)
+	if metadata != nil {
+		if loggerName := metadata.GetLoggerName(); loggerName != "" {
+			slogRecord.AddAttrs(slog.String("logger", loggerName))
+		}
+		if traceID := metadata.GetTraceID(); traceID != "" {
+			slogRecord.AddAttrs(
+				slog.String("trace_id", traceID),
+				slog.String("span_id", metadata.GetSpanID()),
+				slog.String("trace_flags", metadata.GetTraceFlags()))
+		}
+		for _, field := range metadata.ContextFields {
+			slogRecord.AddAttrs(slog.Any(field.Key, field.Value))
+		}
+	}
+	for _, field := range record.Fields {
+		slogRecord.AddAttrs(slog.Any(field.Key, field.Value))
+	}
+
+	return appender.handler.Handle(ctx, slogRecord)
+}
+
+// Close implements io.Closer
+func (appender *SlogAppender) Close() error {
+	return nil
+}
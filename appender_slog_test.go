@@ -0,0 +1,86 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSlogTestHandler(buffer *bytes.Buffer) slog.Handler {
+	return slog.NewTextHandler(buffer, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})
+}
+
+func TestSlogAppender_Write(t *testing.T) {
+
+	t.Run("events are written to the handler with the mapped level", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := NewLogger("testLogger", LogLevel_TRACE, NewSlogAppender(newSlogTestHandler(buffer)))
+		logger.DisableLogEventMetadata()
+
+		logger.Debug("debug")
+		logger.Warnw("warn", "key", "value")
+
+		assert.Equal(t, "level=WARN msg=warn key=value\n", buffer.String())
+	})
+
+	t.Run("fields and metadata are attributes", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		logger := NewLogger("testLogger", LogLevel_TRACE, NewSlogAppender(newSlogTestHandler(buffer)))
+		logger.SetContextExtractor(ContextExtractorFunc(func(ctx context.Context) Fields {
+			return Fields{{Key: "requestID", Value: "abc"}}
+		}))
+		logger.SetTraceContextExtractor(func(ctx context.Context) (TraceContext, bool) {
+			return traceContext, true
+		})
+
+		logger.With("count", 1).ErrorCtx(context.Background(), "message")
+		assert.Equal(t, "level=ERROR msg=message logger=testLogger trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 trace_flags=01 requestID=abc count=1\n", buffer.String())
+	})
+
+	t.Run("time and source of the event are used", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		handler := slog.NewJSONHandler(buffer, &slog.HandlerOptions{AddSource: true})
+		logger := NewLogger("testLogger", LogLevel_TRACE, NewSlogAppender(handler))
+		logger.SetMetadataConfig(&MetadataConfig{IsEnabledTime: true, IsEnabledSourceFile: true, IsEnabledSourceLine: true})
+
+		before := time.Now()
+		_, file, line, _ := runtime.Caller(0)
+		logger.Info("message")
+
+		var decoded struct {
+			Time   time.Time `json:"time"`
+			Source struct {
+				File string `json:"file"`
+				Line int    `json:"line"`
+			} `json:"source"`
+			Msg string `json:"msg"`
+		}
+		assert.Nil(t, json.Unmarshal(buffer.Bytes(), &decoded))
+		assert.Equal(t, "message", decoded.Msg)
+		assert.Equal(t, file, decoded.Source.File)
+		assert.Equal(t, line+1, decoded.Source.Line)
+		assert.False(t, decoded.Time.Before(before.Truncate(time.Microsecond)))
+		assert.NotZero(t, decoded.Time.Nanosecond())
+	})
+
+	t.Run("encoded bytes are the message of Write", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		appender := NewSlogAppender(newSlogTestHandler(buffer))
+		appender.Write([]byte("encoded"))
+		assert.Equal(t, "level=INFO msg=encoded\n", buffer.String())
+	})
+}
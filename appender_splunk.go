@@ -0,0 +1,484 @@
+package golog
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSplunkEventPath
+const defaultSplunkEventPath = "/services/collector/event"
+
+// defaultSplunkAckPollInterval
+const defaultSplunkAckPollInterval = time.Second
+
+// defaultSplunkAckTimeout
+const defaultSplunkAckTimeout = time.Minute
+
+// SplunkHECAppender sends events to Splunk HTTP Event Collector.
+//
+// Each event is wrapped in the HEC envelope with time, host, source, sourcetype and index.
+// JSON events are sent as objects and other events as strings.
+// The level, the logger name and the static fields are sent as indexed fields.
+// If the indexer acknowledgement is enabled, the acknowledgements of the sent batches are polled in background,
+// and batches which are not acknowledged in time are sent again up to the max retries of HTTPAppender.
+// Batching and retries are done by HTTPAppender.
+type SplunkHECAppender struct {
+	endpoint        string
+	token           string
+	host            string
+	source          string
+	sourceType      string
+	index           string
+	fields          Fields
+	ack             bool
+	ackPollInterval time.Duration
+	ackTimeout      time.Duration
+	channel         string
+	httpOptions     []HTTPOption
+
+	http *HTTPAppender
+
+	// pendingAcks are the batches waiting for the acknowledgement by ackId
+	pendingAcks map[int64]*splunkPendingAck
+	// resending counts the resends of the batches being sent again by the first event
+	resending map[*HTTPEvent]int
+	done      chan struct{}
+	wg        *sync.WaitGroup
+	mu        *sync.Mutex
+}
+
+// splunkPendingAck is a batch waiting for the acknowledgement
+type splunkPendingAck struct {
+	batch    []HTTPEvent
+	deadline time.Time
+	resends  int
+}
+
+// SplunkHECOption
+type SplunkHECOption func(appender *SplunkHECAppender)
+
+// WithSplunkHost sets host. The default is the host name.
+func WithSplunkHost(host string) SplunkHECOption {
+	return func(appender *SplunkHECAppender) {
+		appender.host = host
+	}
+}
+
+// WithSplunkSource sets source. If not specified, the logger name is used.
+func WithSplunkSource(source string) SplunkHECOption {
+	return func(appender *SplunkHECAppender) {
+		appender.source = source
+	}
+}
+
+// WithSplunkSourceType sets sourcetype, e.g. "_json"
+func WithSplunkSourceType(sourceType string) SplunkHECOption {
+	return func(appender *SplunkHECAppender) {
+		appender.sourceType = sourceType
+	}
+}
+
+// WithSplunkIndex sets index. If not specified, the default index of the token is used.
+func WithSplunkIndex(index string) SplunkHECOption {
+	return func(appender *SplunkHECAppender) {
+		appender.index = index
+	}
+}
+
+// WithSplunkFields sets alternating names and values of indexed fields added to every event
+func WithSplunkFields(namesAndValues ...interface{}) SplunkHECOption {
+	return func(appender *SplunkHECAppender) {
+		appender.fields = appender.fields.with(namesAndValues...)
+	}
+}
+
+// WithSplunkAck enables the indexer acknowledgement.
+// The acknowledgement is polled at pollInterval, and the batch is sent again if it is not acknowledged within timeout.
+func WithSplunkAck(pollInterval time.Duration, timeout time.Duration) SplunkHECOption {
+	return func(appender *SplunkHECAppender) {
+		appender.ack = true
+		if pollInterval > 0 {
+			appender.ackPollInterval = pollInterval
+		}
+		if timeout > 0 {
+			appender.ackTimeout = timeout
+		}
+	}
+}
+
+// WithSplunkChannel sets the channel of the indexer acknowledgement. The default is a random UUID.
+func WithSplunkChannel(channel string) SplunkHECOption {
+	return func(appender *SplunkHECAppender) {
+		appender.channel = channel
+	}
+}
+
+// WithSplunkHTTPOptions sets options of the underlying HTTPAppender, e.g. batching and retries
+func WithSplunkHTTPOptions(options ...HTTPOption) SplunkHECOption {
+	return func(appender *SplunkHECAppender) {
+		appender.httpOptions = append(appender.httpOptions, options...)
+	}
+}
+
+// NewSplunkHECAppender returns new SplunkHECAppender.
+//
+// endpoint is the url of HEC, e.g. "https://localhost:8088".
+// If the path is empty, "/services/collector/event" is used.
+func NewSplunkHECAppender(endpoint string, token string, options ...SplunkHECOption) (*SplunkHECAppender, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpointURL.Path == "" || endpointURL.Path == "/" {
+		endpointURL.Path = defaultSplunkEventPath
+	}
+
+	hostname, _ := os.Hostname()
+	appender := &SplunkHECAppender{
+		endpoint:        endpointURL.String(),
+		token:           token,
+		host:            hostname,
+		ackPollInterval: defaultSplunkAckPollInterval,
+		ackTimeout:      defaultSplunkAckTimeout,
+		pendingAcks:     map[int64]*splunkPendingAck{},
+		resending:       map[*HTTPEvent]int{},
+		done:            make(chan struct{}),
+		wg:              new(sync.WaitGroup),
+		mu:              new(sync.Mutex),
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+
+	if appender.ack && appender.channel == "" {
+		if appender.channel, err = newSplunkChannel(); err != nil {
+			return nil, err
+		}
+	}
+
+	httpOptions := append(appender.httpOptions,
+		WithHTTPBodyBuilder(HTTPBodyBuilderFunc(appender.build)),
+		func(httpAppender *HTTPAppender) {
+			headers := map[string]string{}
+			for key, value := range httpAppender.headers {
+				headers[key] = value
+			}
+			for key, value := range appender.headers() {
+				headers[key] = value
+			}
+			httpAppender.headers = headers
+
+			if appender.ack {
+				httpAppender.responseHandler = appender.trackAck
+			}
+		})
+
+	if appender.http, err = NewHTTPAppender(appender.endpoint, httpOptions...); err != nil {
+		return nil, fmt.Errorf("golog: invalid splunk endpoint %q", endpoint)
+	}
+
+	if appender.ack {
+		appender.wg.Add(1)
+		go appender.ackLoop()
+	}
+	return appender, nil
+}
+
+// Write implements io.Writer. The event is sent with info level.
+func (appender *SplunkHECAppender) Write(data []byte) (n int, err error) {
+	return appender.http.WriteLevel(LogLevel_INFO, data)
+}
+
+// WriteLevel implements LevelWriter
+func (appender *SplunkHECAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	return appender.http.WriteLevel(level, data)
+}
+
+// WriteEvent implements EventWriter. The event is encoded as the event of the envelope.
+func (appender *SplunkHECAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	return appender.http.writeEvent(level, metadata, event)
+}
+
+// Flush sends queued events and waits until the requests finish
+func (appender *SplunkHECAppender) Flush() error {
+	return appender.http.Flush()
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (appender *SplunkHECAppender) Dropped() uint64 {
+	return appender.http.Dropped()
+}
+
+// Close implements io.Closer.
+// Queued events are sent, and the pending acknowledgements are polled once.
+// Batches which are not acknowledged are reported by warnLogger without being sent again.
+func (appender *SplunkHECAppender) Close() error {
+	err := appender.http.Close()
+	if appender.ack {
+		appender.mu.Lock()
+		select {
+		case <-appender.done:
+		default:
+			close(appender.done)
+		}
+		appender.mu.Unlock()
+		appender.wg.Wait()
+	}
+	return err
+}
+
+// headers returns the headers of authentication and the channel
+func (appender *SplunkHECAppender) headers() map[string]string {
+	headers := map[string]string{"Authorization": "Splunk " + appender.token}
+	if appender.channel != "" {
+		headers["X-Splunk-Request-Channel"] = appender.channel
+	}
+	return headers
+}
+
+// splunkEnvelope is the envelope of an event
+type splunkEnvelope struct {
+	Time       json.Number       `json:"time"`
+	Host       string            `json:"host,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	SourceType string            `json:"sourcetype,omitempty"`
+	Index      string            `json:"index,omitempty"`
+	Event      json.RawMessage   `json:"event"`
+	Fields     map[string]string `json:"fields,omitempty"`
+}
+
+// build implements HTTPBodyBuilder. Envelopes are concatenated.
+func (appender *SplunkHECAppender) build(events []HTTPEvent) ([]byte, string, error) {
+	var body []byte
+	for _, event := range events {
+		envelope, err := appender.envelope(event)
+		if err != nil {
+			return nil, "", err
+		}
+		encoded, err := json.Marshal(envelope)
+		if err != nil {
+			return nil, "", err
+		}
+		body = append(body, encoded...)
+		body = append(body, '\n')
+	}
+	return body, "application/json", nil
+}
+
+// envelope wraps event
+func (appender *SplunkHECAppender) envelope(event HTTPEvent) (splunkEnvelope, error) {
+	envelope := splunkEnvelope{
+		Time:       json.Number(strconv.FormatFloat(float64(event.Time.UnixMilli())/1000, 'f', 3, 64)),
+		Host:       appender.host,
+		Source:     appender.source,
+		SourceType: appender.sourceType,
+		Index:      appender.index,
+		Fields:     map[string]string{"level": strings.ToLower(otlpSeverityText(event.Level))},
+	}
+	if envelope.Source == "" {
+		envelope.Source = event.LoggerName
+	}
+	if event.LoggerName != "" {
+		envelope.Fields["logger"] = event.LoggerName
+	}
+	for _, field := range appender.fields {
+		envelope.Fields[field.Key] = fmt.Sprint(field.Value)
+	}
+
+	data := bytes.TrimSpace(event.Data)
+	if len(data) > 0 && data[0] == '{' && json.Valid(data) {
+		envelope.Event = data
+		return envelope, nil
+	}
+
+	encoded, err := json.Marshal(string(data))
+	if err != nil {
+		return envelope, err
+	}
+	envelope.Event = encoded
+	return envelope, nil
+}
+
+// splunkResponse is the response of the event endpoint
+type splunkResponse struct {
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+	AckID *int64 `json:"ackId"`
+}
+
+// trackAck implements the response handler of HTTPAppender.
+// The batch is tracked by the ackId of the response, and the acknowledgement is polled by ackLoop.
+func (appender *SplunkHECAppender) trackAck(batch []HTTPEvent, body []byte) ([]HTTPEvent, error) {
+	var response splunkResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("golog: invalid hec response : %v", err)
+	}
+	if response.AckID == nil {
+		return nil, errors.New("golog: hec response has no ackId, the indexer acknowledgement is disabled for the token")
+	}
+
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	// batch is reused by HTTPAppender after the handler returns
+	pending := &splunkPendingAck{
+		batch:    append([]HTTPEvent(nil), batch...),
+		deadline: time.Now().Add(appender.ackTimeout),
+	}
+	if len(batch) > 0 {
+		pending.resends = appender.resending[&batch[0]]
+	}
+	appender.pendingAcks[*response.AckID] = pending
+	return nil, nil
+}
+
+// ackLoop polls the pending acknowledgements at the poll interval until Close
+func (appender *SplunkHECAppender) ackLoop() {
+	defer appender.wg.Done()
+
+	ticker := time.NewTicker(appender.ackPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-appender.done:
+			for ackID, pending := range appender.pollPendingAcks(false) {
+				warnLogger.Warnf("golog: ack %d of %d events is not received before close", ackID, len(pending.batch))
+			}
+			return
+		case <-ticker.C:
+			for ackID, pending := range appender.pollPendingAcks(true) {
+				appender.resend(ackID, pending)
+			}
+		}
+	}
+}
+
+// pollPendingAcks polls the pending acknowledgements and removes the acknowledged batches.
+// It returns the batches which are timed out, or all of the remaining batches if onlyTimedOut is false.
+func (appender *SplunkHECAppender) pollPendingAcks(onlyTimedOut bool) map[int64]*splunkPendingAck {
+	appender.mu.Lock()
+	ackIDs := make([]int64, 0, len(appender.pendingAcks))
+	for ackID := range appender.pendingAcks {
+		ackIDs = append(ackIDs, ackID)
+	}
+	appender.mu.Unlock()
+
+	if len(ackIDs) == 0 {
+		return nil
+	}
+
+	acks, err := appender.pollAcks(ackIDs)
+	if err != nil {
+		warnLogger.Warnf("golog: poll %d hec acks is failed , error : %s", len(ackIDs), err.Error())
+	}
+
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	now := time.Now()
+	expired := map[int64]*splunkPendingAck{}
+	for _, ackID := range ackIDs {
+		pending := appender.pendingAcks[ackID]
+		if !acks[ackID] {
+			if onlyTimedOut && now.Before(pending.deadline) {
+				continue
+			}
+			expired[ackID] = pending
+		}
+		delete(appender.pendingAcks, ackID)
+	}
+	return expired
+}
+
+// resend sends the batch which is not acknowledged in time again, up to the max retries of HTTPAppender
+func (appender *SplunkHECAppender) resend(ackID int64, pending *splunkPendingAck) {
+	if pending.resends >= appender.http.maxRetries || len(pending.batch) == 0 {
+		warnLogger.Warnf("golog: ack %d of %d events is not received : timeout", ackID, len(pending.batch))
+		return
+	}
+
+	appender.mu.Lock()
+	appender.resending[&pending.batch[0]] = pending.resends + 1
+	appender.mu.Unlock()
+
+	if err := appender.http.postWithRetry(pending.batch); err != nil {
+		warnLogger.Warnf("golog: resend %d events to %s is failed , error : %s", len(pending.batch), appender.endpoint, err.Error())
+	}
+
+	appender.mu.Lock()
+	delete(appender.resending, &pending.batch[0])
+	appender.mu.Unlock()
+}
+
+// pollAcks queries the status of ackIDs
+func (appender *SplunkHECAppender) pollAcks(ackIDs []int64) (map[int64]bool, error) {
+	ackURL, err := url.Parse(appender.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	ackURL.Path = "/services/collector/ack"
+	ackURL.RawQuery = url.Values{"channel": {appender.channel}}.Encode()
+
+	body, err := json.Marshal(map[string][]int64{"acks": ackIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, ackURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range appender.headers() {
+		request.Header.Set(key, value)
+	}
+
+	response, err := appender.http.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return nil, fmt.Errorf("golog: hec ack returns %s : %s", response.Status, string(message))
+	}
+
+	var acks struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&acks); err != nil {
+		return nil, err
+	}
+
+	acked := map[int64]bool{}
+	for _, ackID := range ackIDs {
+		acked[ackID] = acks.Acks[strconv.FormatInt(ackID, 10)]
+	}
+	return acked, nil
+}
+
+// newSplunkChannel returns a random UUID
+func newSplunkChannel() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]), nil
+}
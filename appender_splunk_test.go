@@ -0,0 +1,223 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHEC is an HTTP Event Collector. An ack is acknowledged after ackPolls polls, or never if ackPolls is negative.
+type fakeHEC struct {
+	*httptest.Server
+	mu        sync.Mutex
+	header    http.Header
+	envelopes []map[string]interface{}
+	requests  int
+	ack       bool
+	ackPolls  int
+	polls     map[int64]int
+	channels  []string
+}
+
+func newFakeHEC(ack bool, ackPolls int) *fakeHEC {
+	hec := &fakeHEC{ack: ack, ackPolls: ackPolls, polls: map[int64]int{}}
+	hec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hec.mu.Lock()
+		defer hec.mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Splunk token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"text":"Invalid authorization","code":3}`)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/services/collector/event":
+			hec.header = r.Header
+			hec.requests++
+			decoder := json.NewDecoder(r.Body)
+			for decoder.More() {
+				var envelope map[string]interface{}
+				if err := decoder.Decode(&envelope); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					fmt.Fprint(w, `{"text":"Invalid data format","code":6}`)
+					return
+				}
+				hec.envelopes = append(hec.envelopes, envelope)
+			}
+			if hec.ack {
+				fmt.Fprintf(w, `{"text":"Success","code":0,"ackId":%d}`, hec.requests-1)
+			} else {
+				fmt.Fprint(w, `{"text":"Success","code":0}`)
+			}
+		case "/services/collector/ack":
+			hec.channels = append(hec.channels, r.URL.Query().Get("channel"))
+			var request struct {
+				Acks []int64 `json:"acks"`
+			}
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &request)
+
+			acks := map[string]bool{}
+			for _, id := range request.Acks {
+				hec.polls[id]++
+				acks[strconv.FormatInt(id, 10)] = hec.ackPolls >= 0 && hec.polls[id] >= hec.ackPolls
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"acks": acks})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return hec
+}
+
+func (hec *fakeHEC) received() []map[string]interface{} {
+	hec.mu.Lock()
+	defer hec.mu.Unlock()
+	return append([]map[string]interface{}(nil), hec.envelopes...)
+}
+
+func TestSplunkHECAppender(t *testing.T) {
+	hec := newFakeHEC(false, 0)
+	defer hec.Close()
+
+	appender, err := NewSplunkHECAppender(hec.URL, "token",
+		WithSplunkHost("host"), WithSplunkSourceType("_json"), WithSplunkIndex("main"), WithSplunkFields("env", "test"))
+	assert.Nil(t, err)
+	appender.http.now = func() time.Time { return time.Unix(1525662860, 123456789) }
+
+	logger := NewLogger("testLogger", LogLevel_INFO, appender)
+	logger.SetMetadataConfig(&MetadataConfig{IsEnabledLoggerName: true})
+	logger.Infoj(map[string]string{"message": "json"})
+	appender.WriteLevel(LogLevel_ERROR, []byte("text\n"))
+	assert.Nil(t, appender.Close())
+
+	assert.Equal(t, "", hec.header.Get("X-Splunk-Request-Channel"))
+	envelopes := hec.received()
+	assert.Len(t, envelopes, 2)
+
+	assert.Equal(t, 1525662860.123, envelopes[0]["time"])
+	assert.Equal(t, "host", envelopes[0]["host"])
+	assert.Equal(t, "testLogger", envelopes[0]["source"])
+	assert.Equal(t, "_json", envelopes[0]["sourcetype"])
+	assert.Equal(t, "main", envelopes[0]["index"])
+	assert.Equal(t, map[string]interface{}{"message": "json"}, envelopes[0]["event"].(map[string]interface{})["EventData"])
+	assert.Equal(t, map[string]interface{}{"level": "info", "logger": "testLogger", "env": "test"}, envelopes[0]["fields"])
+
+	assert.Nil(t, envelopes[1]["source"])
+	assert.Equal(t, "text", envelopes[1]["event"])
+	assert.Equal(t, map[string]interface{}{"level": "error", "env": "test"}, envelopes[1]["fields"])
+}
+
+func TestSplunkHECAppender_EventTime(t *testing.T) {
+	hec := newFakeHEC(false, 0)
+	defer hec.Close()
+
+	appender, err := NewSplunkHECAppender(hec.URL, "token")
+	assert.Nil(t, err)
+	appender.http.now = func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	assert.Nil(t, appender.WriteEvent(LogLevel_INFO, newPatternTestMetadata(), TextLogEvent{Event: "event"}))
+	assert.Nil(t, appender.Close())
+
+	envelopes := hec.received()
+	assert.Len(t, envelopes, 1)
+	assert.Equal(t, 1525662860.123, envelopes[0]["time"])
+}
+
+func TestSplunkHECAppender_Ack(t *testing.T) {
+	t.Run("acknowledged", func(t *testing.T) {
+		hec := newFakeHEC(true, 2)
+		defer hec.Close()
+
+		appender, err := NewSplunkHECAppender(hec.URL, "token",
+			WithSplunkAck(time.Millisecond, time.Second), WithSplunkChannel("channel"))
+		assert.Nil(t, err)
+
+		appender.Write([]byte("message"))
+		assert.Nil(t, appender.Flush())
+		assert.Eventually(t, func() bool {
+			appender.mu.Lock()
+			defer appender.mu.Unlock()
+			return len(appender.pendingAcks) == 0
+		}, time.Second, time.Millisecond)
+		assert.Nil(t, appender.Close())
+
+		hec.mu.Lock()
+		defer hec.mu.Unlock()
+		assert.Equal(t, "channel", hec.header.Get("X-Splunk-Request-Channel"))
+		assert.Equal(t, []string{"channel", "channel"}, hec.channels)
+		assert.Equal(t, 1, hec.requests)
+	})
+
+	t.Run("not acknowledged", func(t *testing.T) {
+		hec := newFakeHEC(true, -1)
+		defer hec.Close()
+
+		appender, err := NewSplunkHECAppender(hec.URL, "token",
+			WithSplunkAck(time.Millisecond, 5*time.Millisecond),
+			WithSplunkHTTPOptions(WithHTTPRetry(1, time.Millisecond, time.Millisecond)))
+		assert.Nil(t, err)
+
+		appender.Write([]byte("message"))
+		assert.Nil(t, appender.Flush())
+
+		// the batch is sent again once, and dropped after the second timeout
+		assert.Eventually(t, func() bool {
+			appender.mu.Lock()
+			defer appender.mu.Unlock()
+			hec.mu.Lock()
+			defer hec.mu.Unlock()
+			return hec.requests == 2 && len(appender.pendingAcks) == 0 && len(appender.resending) == 0
+		}, time.Second, time.Millisecond)
+		assert.Nil(t, appender.Close())
+
+		hec.mu.Lock()
+		defer hec.mu.Unlock()
+		assert.Equal(t, 2, hec.requests)
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, hec.header.Get("X-Splunk-Request-Channel"))
+	})
+
+	t.Run("pending acks do not block sending", func(t *testing.T) {
+		hec := newFakeHEC(true, -1)
+		defer hec.Close()
+
+		appender, err := NewSplunkHECAppender(hec.URL, "token", WithSplunkAck(time.Millisecond, time.Hour))
+		assert.Nil(t, err)
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			appender.Write([]byte("message"))
+			assert.Nil(t, appender.Flush())
+		}
+		assert.Nil(t, appender.Close())
+		assert.True(t, time.Since(start) < time.Second)
+
+		hec.mu.Lock()
+		defer hec.mu.Unlock()
+		assert.Equal(t, 3, hec.requests)
+	})
+}
+
+func TestSplunkHECAppender_InvalidToken(t *testing.T) {
+	hec := newFakeHEC(false, 0)
+	defer hec.Close()
+
+	appender, err := NewSplunkHECAppender(hec.URL, "invalid")
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	appender.Write([]byte("message"))
+	err = appender.Flush()
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "401"))
+}
@@ -0,0 +1,550 @@
+package golog
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat is the format of syslog messages
+type SyslogFormat int
+
+// SyslogFormat Constants
+const (
+	SyslogFormat_RFC5424 SyslogFormat = iota
+	SyslogFormat_RFC3164
+)
+
+// SyslogFacility
+type SyslogFacility int
+
+// SyslogFacility Constants
+const (
+	SyslogFacility_KERN     SyslogFacility = 0
+	SyslogFacility_USER     SyslogFacility = 1
+	SyslogFacility_MAIL     SyslogFacility = 2
+	SyslogFacility_DAEMON   SyslogFacility = 3
+	SyslogFacility_AUTH     SyslogFacility = 4
+	SyslogFacility_SYSLOG   SyslogFacility = 5
+	SyslogFacility_LPR      SyslogFacility = 6
+	SyslogFacility_NEWS     SyslogFacility = 7
+	SyslogFacility_UUCP     SyslogFacility = 8
+	SyslogFacility_CRON     SyslogFacility = 9
+	SyslogFacility_AUTHPRIV SyslogFacility = 10
+	SyslogFacility_FTP      SyslogFacility = 11
+	SyslogFacility_LOCAL0   SyslogFacility = 16
+	SyslogFacility_LOCAL1   SyslogFacility = 17
+	SyslogFacility_LOCAL2   SyslogFacility = 18
+	SyslogFacility_LOCAL3   SyslogFacility = 19
+	SyslogFacility_LOCAL4   SyslogFacility = 20
+	SyslogFacility_LOCAL5   SyslogFacility = 21
+	SyslogFacility_LOCAL6   SyslogFacility = 22
+	SyslogFacility_LOCAL7   SyslogFacility = 23
+)
+
+// defaultSyslogTimeout
+const defaultSyslogTimeout = 5 * time.Second
+
+// defaultSyslogInitialBackoff is doubled on each failed connection up to defaultSyslogMaxBackoff
+const defaultSyslogInitialBackoff = 100 * time.Millisecond
+
+// defaultSyslogMaxBackoff
+const defaultSyslogMaxBackoff = 30 * time.Second
+
+// defaultSyslogStructuredDataID is the SD-ID of metadata.
+// 32473 is the private enterprise number reserved for documentation (RFC 5612).
+const defaultSyslogStructuredDataID = "golog@32473"
+
+// syslogLocalAddresses are the local syslog sockets tried when the address is empty
+var syslogLocalAddresses = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogAppender sends events to a syslog server.
+//
+// Messages are formatted by RFC 5424 or RFC 3164 and sent over UDP, TCP, TLS or Unix domain sockets.
+// On stream transports, RFC 5424 messages are framed by octet counting (RFC 6587)
+// and RFC 3164 messages are terminated by a newline.
+// The connection is established lazily and re-established after errors.
+// After a connection fails, events fail without connecting until the backoff interval elapses,
+// so that logging is not blocked by a dial for every event while the server is down.
+type SyslogAppender struct {
+	network   string
+	address   string
+	format    SyslogFormat
+	facility  SyslogFacility
+	appName   string
+	hostName  string
+	sdID      string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	pid       int
+	now       func() time.Time
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	backoff        time.Duration
+	retryAt        time.Time
+
+	conn net.Conn
+
+	mu        *sync.Mutex
+	activated bool
+}
+
+// SyslogOption
+type SyslogOption func(appender *SyslogAppender)
+
+// WithSyslogFormat sets the format of messages. The default is SyslogFormat_RFC5424.
+func WithSyslogFormat(format SyslogFormat) SyslogOption {
+	return func(appender *SyslogAppender) {
+		appender.format = format
+	}
+}
+
+// WithSyslogFacility sets the facility. The default is SyslogFacility_USER.
+func WithSyslogFacility(facility SyslogFacility) SyslogOption {
+	return func(appender *SyslogAppender) {
+		appender.facility = facility
+	}
+}
+
+// WithSyslogAppName sets the APP-NAME (RFC 5424) or the TAG (RFC 3164).
+// If not specified, the logger name is used, or the executable name if the logger name is unknown.
+func WithSyslogAppName(appName string) SyslogOption {
+	return func(appender *SyslogAppender) {
+		appender.appName = appName
+	}
+}
+
+// WithSyslogHostName sets the HOSTNAME. The default is os.Hostname.
+func WithSyslogHostName(hostName string) SyslogOption {
+	return func(appender *SyslogAppender) {
+		appender.hostName = hostName
+	}
+}
+
+// WithSyslogStructuredDataID sets the SD-ID of the structured data of metadata.
+// The default is "golog@32473".
+func WithSyslogStructuredDataID(sdID string) SyslogOption {
+	return func(appender *SyslogAppender) {
+		if sdID != "" {
+			appender.sdID = sdID
+		}
+	}
+}
+
+// WithSyslogTLSConfig sets the TLS config of "tls://" addresses
+func WithSyslogTLSConfig(config *tls.Config) SyslogOption {
+	return func(appender *SyslogAppender) {
+		appender.tlsConfig = config
+	}
+}
+
+// WithSyslogTimeout sets dial and write timeout
+func WithSyslogTimeout(timeout time.Duration) SyslogOption {
+	return func(appender *SyslogAppender) {
+		if timeout > 0 {
+			appender.timeout = timeout
+		}
+	}
+}
+
+// WithSyslogBackoff sets the initial and the max interval of reconnection
+func WithSyslogBackoff(initial time.Duration, max time.Duration) SyslogOption {
+	return func(appender *SyslogAppender) {
+		if initial > 0 {
+			appender.initialBackoff = initial
+		}
+		if max > 0 {
+			appender.maxBackoff = max
+		}
+	}
+}
+
+// NewSyslogAppender returns new SyslogAppender.
+//
+// address is "udp://host:port", "tcp://host:port", "tls://host:port",
+// "unix:///path/to/socket" (stream) or "unixgram:///path/to/socket" (datagram).
+// "host:port" is UDP. If address is empty, the local syslog socket, e.g. /dev/log, is used.
+func NewSyslogAppender(address string, options ...SyslogOption) (*SyslogAppender, error) {
+	network, addr, err := parseSyslogAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	hostName, _ := os.Hostname()
+
+	appender := &SyslogAppender{
+		network:   network,
+		address:   addr,
+		format:    SyslogFormat_RFC5424,
+		facility:  SyslogFacility_USER,
+		hostName:  hostName,
+		sdID:      defaultSyslogStructuredDataID,
+		timeout:   defaultSyslogTimeout,
+		pid:       os.Getpid(),
+		now:       time.Now,
+		mu:        new(sync.Mutex),
+		activated: true,
+
+		initialBackoff: defaultSyslogInitialBackoff,
+		maxBackoff:     defaultSyslogMaxBackoff,
+	}
+
+	for _, option := range options {
+		option(appender)
+	}
+	appender.backoff = appender.initialBackoff
+
+	return appender, nil
+}
+
+// parseSyslogAddress
+func parseSyslogAddress(address string) (network string, addr string, err error) {
+	if address == "" {
+		return "local", "", nil
+	}
+
+	network, addr = "udp", address
+	if i := strings.Index(address, "://"); i >= 0 {
+		network, addr = address[:i], address[i+3:]
+	}
+
+	switch network {
+	case "udp", "tcp", "tls", "unix", "unixgram":
+	default:
+		return "", "", fmt.Errorf("golog: unsupported syslog network %q", network)
+	}
+
+	if addr == "" {
+		return "", "", fmt.Errorf("golog: invalid syslog address %q", address)
+	}
+	return network, addr, nil
+}
+
+// Write implements io.Writer. The event is sent with notice severity.
+func (appender *SyslogAppender) Write(data []byte) (n int, err error) {
+	message := strings.TrimSuffix(string(data), "\n")
+	if err := appender.send(5, appender.now(), "", message, nil); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteLevel implements LevelWriter
+func (appender *SyslogAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	message := strings.TrimSuffix(string(data), "\n")
+	if err := appender.send(syslogSeverity(level), appender.now(), "", message, nil); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteEvent implements EventWriter.
+// The message is the event without metadata, and the metadata is sent as structured data (RFC 5424).
+func (appender *SyslogAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	if metadata == nil {
+		return appender.send(syslogSeverity(level), appender.now(), "", string(event.Encode(nil)), nil)
+	}
+
+	t := appender.now()
+	if metadata.UnixTime != 0 {
+		t = time.Unix(metadata.UnixTime, int64(metadata.Nanosecond))
+	}
+
+	if appender.format == SyslogFormat_RFC3164 {
+		return appender.send(syslogSeverity(level), t, metadata.LoggerName, string(event.Encode(nil)), nil)
+	}
+
+	message, fields := eventMessage(event)
+
+	var params Fields
+	if metadata.LoggerName != "" {
+		params = append(params, Field{Key: "logger", Value: metadata.LoggerName})
+	}
+	if metadata.SourceFile != "" {
+		params = append(params, Field{Key: "file", Value: filepath.Base(metadata.SourceFile)})
+	}
+	if metadata.SourceLine != 0 {
+		params = append(params, Field{Key: "line", Value: metadata.SourceLine})
+	}
+	if traceID := metadata.GetTraceID(); traceID != "" {
+		params = append(params,
+			Field{Key: "trace_id", Value: traceID},
+			Field{Key: "span_id", Value: metadata.GetSpanID()},
+			Field{Key: "trace_flags", Value: metadata.GetTraceFlags()})
+	}
+	params = append(params, metadata.ContextFields...)
+	params = append(params, fields...)
+
+	return appender.send(syslogSeverity(level), t, metadata.LoggerName, message, params)
+}
+
+// Close implements io.Closer
+func (appender *SyslogAppender) Close() error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return nil
+	}
+	appender.activated = false
+
+	if appender.conn != nil {
+		err := appender.conn.Close()
+		appender.conn = nil
+		return err
+	}
+	return nil
+}
+
+// send formats and writes a message.
+// On failure the connection is re-established and the message is retried once.
+func (appender *SyslogAppender) send(severity int, t time.Time, loggerName string, message string, params Fields) error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+
+	if !appender.activated {
+		return errors.New("golog: syslog appender is closed")
+	}
+
+	appName := appender.appName
+	if appName == "" {
+		appName = loggerName
+	}
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = appender.connect(); err != nil {
+			continue
+		}
+
+		packet := appender.frame(appender.formatMessage(severity, t, appName, message, params))
+		appender.conn.SetWriteDeadline(time.Now().Add(appender.timeout))
+		if _, err = appender.conn.Write(packet); err == nil {
+			return nil
+		}
+
+		appender.conn.Close()
+		appender.conn = nil
+	}
+	return err
+}
+
+// connect. The caller must hold mu.
+// After a failed dial, it fails without dialing until the backoff interval elapses.
+func (appender *SyslogAppender) connect() error {
+	if appender.conn != nil {
+		return nil
+	}
+
+	if now := appender.now(); now.Before(appender.retryAt) {
+		return fmt.Errorf("golog: syslog server is unreachable , retry after %s", appender.retryAt.Sub(now))
+	}
+
+	var conn net.Conn
+	var err error
+	switch appender.network {
+	case "local":
+		conn, err = appender.dialLocal()
+	case "tls":
+		dialer := &net.Dialer{Timeout: appender.timeout}
+		conn, err = tls.DialWithDialer(dialer, "tcp", appender.address, appender.tlsConfig)
+	default:
+		conn, err = net.DialTimeout(appender.network, appender.address, appender.timeout)
+	}
+	if err != nil {
+		appender.retryAt = appender.now().Add(appender.backoff)
+		appender.backoff *= 2
+		if appender.backoff > appender.maxBackoff {
+			appender.backoff = appender.maxBackoff
+		}
+		return err
+	}
+
+	appender.conn = conn
+	appender.backoff = appender.initialBackoff
+	return nil
+}
+
+// dialLocal connects to the first available local syslog socket
+func (appender *SyslogAppender) dialLocal() (net.Conn, error) {
+	var err error
+	for _, address := range syslogLocalAddresses {
+		for _, network := range []string{"unixgram", "unix"} {
+			var conn net.Conn
+			if conn, err = net.DialTimeout(network, address, appender.timeout); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("golog: local syslog socket is not available : %v", err)
+}
+
+// isStream reports whether the connection is a stream, which requires framing
+func (appender *SyslogAppender) isStream() bool {
+	switch network := appender.conn.LocalAddr().Network(); network {
+	case "tcp", "unix":
+		return true
+	default:
+		return false
+	}
+}
+
+// frame adds framing of stream transports. The caller must hold mu.
+func (appender *SyslogAppender) frame(message []byte) []byte {
+	if !appender.isStream() {
+		return message
+	}
+
+	if appender.format == SyslogFormat_RFC3164 {
+		return append(message, '\n')
+	}
+
+	// octet counting (RFC 6587)
+	framed := strconv.AppendInt(nil, int64(len(message)), 10)
+	framed = append(framed, ' ')
+	return append(framed, message...)
+}
+
+// formatMessage formats a message by the format of the appender
+func (appender *SyslogAppender) formatMessage(severity int, t time.Time, appName string, message string, params Fields) []byte {
+	priority := int(appender.facility)*8 + severity
+
+	if appender.format == SyslogFormat_RFC3164 {
+		return formatRFC3164(priority, t, appender.hostName, appName, appender.pid, message)
+	}
+	return formatRFC5424(priority, t, appender.hostName, appName, appender.pid, appender.sdID, message, params)
+}
+
+// formatRFC5424 formats "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG"
+func formatRFC5424(priority int, t time.Time, hostName, appName string, pid int, sdID string, message string, params Fields) []byte {
+	buf := make([]byte, 0, 128+len(message))
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(priority), 10)
+	buf = append(buf, ">1 "...)
+	buf = t.AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+	buf = append(buf, ' ')
+	buf = append(buf, syslogHeaderValue(hostName, 255)...)
+	buf = append(buf, ' ')
+	buf = append(buf, syslogHeaderValue(appName, 48)...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(pid), 10)
+	buf = append(buf, " - "...)
+
+	if len(params) == 0 {
+		buf = append(buf, '-')
+	} else {
+		buf = append(buf, '[')
+		buf = append(buf, sdID...)
+		for _, param := range params {
+			buf = append(buf, ' ')
+			buf = append(buf, syslogParamName(param.Key)...)
+			buf = append(buf, `="`...)
+			buf = appendSyslogParamValue(buf, fmt.Sprint(param.Value))
+			buf = append(buf, '"')
+		}
+		buf = append(buf, ']')
+	}
+
+	if message != "" {
+		buf = append(buf, ' ')
+		buf = append(buf, message...)
+	}
+	return buf
+}
+
+// formatRFC3164 formats "<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG"
+func formatRFC3164(priority int, t time.Time, hostName, appName string, pid int, message string) []byte {
+	buf := make([]byte, 0, 64+len(message))
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(priority), 10)
+	buf = append(buf, '>')
+	buf = t.AppendFormat(buf, time.Stamp)
+	buf = append(buf, ' ')
+	if hostName != "" {
+		buf = append(buf, hostName...)
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, appName...)
+	buf = append(buf, '[')
+	buf = strconv.AppendInt(buf, int64(pid), 10)
+	buf = append(buf, "]: "...)
+	return append(buf, message...)
+}
+
+// syslogHeaderValue returns value limited to printable US-ASCII and maxLength, or "-" if empty
+func syslogHeaderValue(value string, maxLength int) string {
+	printable := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+
+	if printable == "" {
+		return "-"
+	}
+	if len(printable) > maxLength {
+		return printable[:maxLength]
+	}
+	return printable
+}
+
+// syslogParamName returns PARAM-NAME, which is limited to 32 printable US-ASCII except '=', ' ', ']' and '"'
+func syslogParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if name == "" {
+		return "_"
+	}
+	if len(name) > 32 {
+		return name[:32]
+	}
+	return name
+}
+
+// appendSyslogParamValue appends PARAM-VALUE escaping '"', '\' and ']'
+func appendSyslogParamValue(buf []byte, value string) []byte {
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\', ']':
+			buf = append(buf, '\\', c)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// syslogSeverity maps LogLevel to syslog severity
+func syslogSeverity(level LogLevel) int {
+	switch level {
+	case LogLevel_TRACE, LogLevel_DEBUG:
+		return 7 // debug
+	case LogLevel_INFO:
+		return 6 // informational
+	case LogLevel_WARN:
+		return 4 // warning
+	case LogLevel_ERROR:
+		return 3 // error
+	case LogLevel_FATAL:
+		return 2 // critical
+	default:
+		return 5 // notice
+	}
+}
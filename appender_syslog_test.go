@@ -0,0 +1,290 @@
+package golog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readOctetCounted reads a message framed by octet counting
+func readOctetCounted(reader *bufio.Reader) string {
+	length, err := reader.ReadString(' ')
+	if err != nil {
+		return err.Error()
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		return err.Error()
+	}
+	message := make([]byte, n)
+	if _, err := io.ReadFull(reader, message); err != nil {
+		return err.Error()
+	}
+	return string(message)
+}
+
+func newSyslogTestLogger(appender Appender) Logger {
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	logger.SetMetadataConfig(&MetadataConfig{IsEnabledLoggerName: true, IsEnabledSourceFile: true, IsEnabledSourceLine: true})
+	return logger
+}
+
+func TestSyslogAppender_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	appender, err := NewSyslogAppender(conn.LocalAddr().String(),
+		WithSyslogFacility(SyslogFacility_LOCAL0), WithSyslogHostName("host"))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	logger := newSyslogTestLogger(appender)
+	logger.Warnw("message", "key", `a"b]`)
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+
+	// <16*8+4>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+	pattern := regexp.MustCompile(`^<132>1 \S+ host testLogger \d+ - \[golog@32473 logger="testLogger" file="appender_syslog_test.go" line="\d+" key="a\\"b\\]"\] message$`)
+	assert.Regexp(t, pattern, string(buf[:n]))
+}
+
+func TestSyslogAppender_Timestamp(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	appender, err := NewSyslogAppender(conn.LocalAddr().String(), WithSyslogHostName("host"))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	metadata := newPatternTestMetadata()
+	assert.Nil(t, appender.WriteEvent(LogLevel_INFO, metadata, TextLogEvent{Event: "message"}))
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+
+	timestamp := strings.Fields(string(buf[:n]))[1]
+	parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Unix(1525662860, 123456000), parsed.Local())
+}
+
+func TestSyslogAppender_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		received <- readOctetCounted(reader)
+		received <- readOctetCounted(reader)
+	}()
+
+	appender, err := NewSyslogAppender("tcp://"+listener.Addr().String(), WithSyslogAppName("app"))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	logger := newSyslogTestLogger(appender)
+	logger.Error("multi\nline")
+	logger.DisableLogEventMetadata()
+	logger.Info("no metadata")
+
+	assert.Regexp(t, `^<11>1 \S+ \S+ app \d+ - \[golog@32473 logger="testLogger" file="appender_syslog_test.go" line="\d+"\] multi\nline$`, <-received)
+	assert.Regexp(t, `^<14>1 \S+ \S+ app \d+ - - no metadata$`, <-received)
+}
+
+func TestSyslogAppender_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server.TLS)
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		received <- readOctetCounted(reader)
+	}()
+
+	clientConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	clientConfig.ServerName = "example.com"
+	appender, err := NewSyslogAppender("tls://"+listener.Addr().String(), WithSyslogTLSConfig(clientConfig))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	appender.WriteLevel(LogLevel_FATAL, []byte("message\n"))
+
+	assert.Regexp(t, `^<10>1 .* - - message$`, <-received)
+}
+
+func TestSyslogAppender_Unixgram_RFC3164(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	appender, err := NewSyslogAppender("unixgram://"+path,
+		WithSyslogFormat(SyslogFormat_RFC3164), WithSyslogFacility(SyslogFacility_DAEMON), WithSyslogHostName("host"))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	logger := newSyslogTestLogger(appender)
+	logger.Infow("message", "key", "value")
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+
+	// <3*8+6>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG
+	assert.Regexp(t, `^<30>[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2} host testLogger\[\d+\]: message key=value$`, string(buf[:n]))
+}
+
+func TestSyslogAppender_UnixStream(t *testing.T) {
+	listen := func(t *testing.T) (string, chan *bufio.Reader) {
+		path := filepath.Join(t.TempDir(), "log.sock")
+		listener, err := net.Listen("unix", path)
+		assert.Nil(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		accepted := make(chan *bufio.Reader, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+			accepted <- bufio.NewReader(conn)
+		}()
+		return path, accepted
+	}
+
+	t.Run("local stream socket", func(t *testing.T) {
+		path, accepted := listen(t)
+		defaultAddresses := syslogLocalAddresses
+		syslogLocalAddresses = []string{path}
+		defer func() { syslogLocalAddresses = defaultAddresses }()
+
+		appender, err := NewSyslogAppender("", WithSyslogFormat(SyslogFormat_RFC3164), WithSyslogHostName("host"))
+		assert.Nil(t, err)
+		defer appender.Close()
+
+		appender.Write([]byte("message1\n"))
+		appender.Write([]byte("message2\n"))
+
+		reader := <-accepted
+		line, _ := reader.ReadString('\n')
+		assert.Regexp(t, `^<13>.* host \S+\[\d+\]: message1\n$`, line)
+		line, _ = reader.ReadString('\n')
+		assert.Regexp(t, `^<13>.* host \S+\[\d+\]: message2\n$`, line)
+	})
+
+	t.Run("octet counting", func(t *testing.T) {
+		path, accepted := listen(t)
+		appender, err := NewSyslogAppender("unix://"+path, WithSyslogAppName("app"))
+		assert.Nil(t, err)
+		defer appender.Close()
+
+		appender.Write([]byte("multi\nline\n"))
+
+		assert.Regexp(t, `^<13>1 \S+ \S+ app \d+ - - multi\nline$`, readOctetCounted(<-accepted))
+	})
+}
+
+func TestSyslogAppender_Reconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	appender, err := NewSyslogAppender("tcp://"+address, WithSyslogTimeout(time.Second), WithSyslogBackoff(time.Second, time.Minute))
+	assert.Nil(t, err)
+	defer appender.Close()
+
+	now := time.Now()
+	appender.now = func() time.Time { return now }
+
+	_, err = appender.Write([]byte("lost"))
+	assert.NotNil(t, err)
+
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Skip("port is reused :", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		received <- readOctetCounted(bufio.NewReader(conn))
+	}()
+
+	// the reconnection is backed off
+	_, err = appender.Write([]byte("backed off"))
+	assert.NotNil(t, err)
+
+	now = now.Add(time.Second)
+	_, err = appender.Write([]byte("message"))
+	assert.Nil(t, err)
+	assert.Regexp(t, `^<13>1 .* - - message$`, <-received)
+
+	assert.Nil(t, appender.Close())
+	_, err = appender.Write([]byte("closed"))
+	assert.NotNil(t, err)
+}
+
+func TestParseSyslogAddress(t *testing.T) {
+	for _, test := range []struct {
+		address, network, addr string
+	}{
+		{"", "local", ""},
+		{"localhost:514", "udp", "localhost:514"},
+		{"tcp://localhost:514", "tcp", "localhost:514"},
+		{"tls://localhost:6514", "tls", "localhost:6514"},
+		{"unix:///dev/log", "unix", "/dev/log"},
+		{"unixgram:///dev/log", "unixgram", "/dev/log"},
+	} {
+		network, addr, err := parseSyslogAddress(test.address)
+		assert.Nil(t, err)
+		assert.Equal(t, test.network, network)
+		assert.Equal(t, test.addr, addr)
+	}
+
+	_, _, err := parseSyslogAddress("http://localhost")
+	assert.NotNil(t, err)
+	_, _, err = parseSyslogAddress("tcp://")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,45 @@
+package golog
+
+import (
+	"context"
+)
+
+// ContextExtractor extracts fields from context.Context,
+// e.g. request IDs, tenant IDs and trace IDs stored by middlewares.
+// The fields are added to LogEventMetadata of events logged with the context.
+type ContextExtractor interface {
+	Extract(ctx context.Context) Fields
+}
+
+// ContextExtractorFunc is an adapter to use an ordinary function as ContextExtractor
+type ContextExtractorFunc func(ctx context.Context) Fields
+
+// Extract implements ContextExtractor
+func (fn ContextExtractorFunc) Extract(ctx context.Context) Fields {
+	return fn(ctx)
+}
+
+// NewContextValueExtractor returns ContextExtractor which adds ctx.Value(contextKey) as the field of key.
+// If the context does not have the value, no field is added.
+func NewContextValueExtractor(key string, contextKey interface{}) ContextExtractor {
+	return ContextExtractorFunc(func(ctx context.Context) Fields {
+		value := ctx.Value(contextKey)
+		if value == nil {
+			return nil
+		}
+		return Fields{{Key: key, Value: value}}
+	})
+}
+
+// extractContextFields returns fields extracted from ctx by extractors
+func extractContextFields(ctx context.Context, extractors []ContextExtractor) Fields {
+	if ctx == nil || len(extractors) == 0 {
+		return nil
+	}
+
+	var fields Fields
+	for _, extractor := range extractors {
+		fields = append(fields, extractor.Extract(ctx)...)
+	}
+	return fields
+}
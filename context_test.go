@@ -0,0 +1,69 @@
+package golog
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testContextKey string
+
+func newContextTestLogger(appender Appender) Logger {
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	logger.SetMetadataConfig(&MetadataConfig{})
+	logger.SetContextExtractor(
+		NewContextValueExtractor("requestID", testContextKey("requestID")),
+		ContextExtractorFunc(func(ctx context.Context) Fields {
+			return Fields{{Key: "tenant", Value: "t1"}}
+		}),
+	)
+	return logger
+}
+
+func TestLogger_Ctx(t *testing.T) {
+	ctx := context.WithValue(context.Background(), testContextKey("requestID"), "abc")
+
+	t.Run("Ctx methods add fields extracted from ctx", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := newContextTestLogger(appender)
+
+		logger.InfoCtx(ctx, "message")
+		logger.With("key", "value").WarnCtx(ctx, "message")
+
+		assert.Equal(t, "   () message requestID=abc tenant=t1\n   () message requestID=abc tenant=t1 key=value\n", appender.String())
+	})
+
+	t.Run("WithContext adds fields extracted from ctx to every event", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := newContextTestLogger(appender)
+
+		logger.WithContext(ctx).Infof("%d", 1)
+		logger.WithContext(context.Background()).Info("message")
+		logger.Info("no context")
+
+		assert.Equal(t, "   () 1 requestID=abc tenant=t1\n   () message tenant=t1\n   () no context\n", appender.String())
+	})
+
+	t.Run("json event renders fields extracted from ctx", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := newContextTestLogger(appender)
+
+		logger.WithContext(ctx).Infoj(map[string]string{"name": "value"})
+
+		var decoded map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(appender.String()), &decoded))
+		assert.Equal(t, map[string]interface{}{"EventData": map[string]interface{}{"name": "value"}, "requestID": "abc", "tenant": "t1"}, decoded)
+	})
+
+	t.Run("slog handler passes the context of the record", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := newContextTestLogger(appender)
+
+		slog.New(NewSlogHandler(&logger)).InfoContext(ctx, "message")
+
+		assert.Equal(t, "   () message requestID=abc tenant=t1\n", appender.String())
+	})
+}
@@ -0,0 +1,115 @@
+package golog
+
+import (
+	"time"
+)
+
+// Encoder encodes Record.
+// The format is chosen by the appender by EncoderAppender instead of the Logger method.
+type Encoder interface {
+	Encode(record *Record) []byte
+}
+
+// EncoderFunc is a function implementing Encoder
+type EncoderFunc func(record *Record) []byte
+
+// Encode implements Encoder
+func (encoderFunc EncoderFunc) Encode(record *Record) []byte {
+	return encoderFunc(record)
+}
+
+// Encoder Variables
+var (
+	// Encoder_EVENT encodes by the original LogEvent, which is the format of the Logger method
+	Encoder_EVENT Encoder = EncoderFunc(func(record *Record) []byte {
+		return record.Event.Encode(record.Metadata)
+	})
+
+	// Encoder_TEXT encodes as TextLogEvent
+	Encoder_TEXT Encoder = EncoderFunc(func(record *Record) []byte {
+		return TextLogEvent{Event: record.Message, Fields: record.Fields}.Encode(record.Metadata)
+	})
+
+	// Encoder_JSON encodes as a JSON object. The message is written as "message" with the keys of JsonLogEvent.
+	// The object of JsonLogEvent is encoded by JsonLogEvent.
+	Encoder_JSON Encoder = EncoderFunc(encodeJSONRecord)
+
+	// Encoder_LOGFMT encodes as LogfmtLogEvent
+	Encoder_LOGFMT Encoder = EncoderFunc(func(record *Record) []byte {
+		return LogfmtLogEvent{Message: record.Message, Fields: record.Fields}.Encode(record.Metadata)
+	})
+
+	// Encoder_OTLP_PROTOBUF encodes as an OTLP ExportLogsServiceRequest of a LogRecord in protobuf
+	Encoder_OTLP_PROTOBUF Encoder = EncoderFunc(func(record *Record) []byte {
+		return encodeOTLPProtobuf(nil, []otlpLogRecord{newOTLPLogRecord(record, time.Now())})
+	})
+)
+
+// encodeJSONRecord
+func encodeJSONRecord(record *Record) []byte {
+	if event, ok := record.Event.(JsonLogEvent); ok {
+		return event.Encode(record.Metadata)
+	}
+
+	encoded := []byte{'{'}
+	if record.Metadata != nil {
+		encoded = appendJSONMetadata(encoded, record.Metadata)
+	}
+	encoded = appendJSONStringField(encoded, "message", record.Message)
+	encoded = append(encoded, '}')
+
+	if record.Metadata != nil {
+		encoded = appendJsonFields(encoded, record.Metadata.ContextFields)
+	}
+	return appendJsonFields(encoded, record.Fields)
+}
+
+// EncoderAppender encodes records by the Encoder and writes them to the appender,
+// so that each appender can write the same event in its own format.
+//
+// EncoderAppender can be wrapped by AsyncAppender and can wrap AsyncAppender.
+// In both cases the record is encoded on the logging goroutine.
+type EncoderAppender struct {
+	appender Appender
+	encoder  Encoder
+}
+
+// NewEncoderAppender returns new EncoderAppender
+func NewEncoderAppender(appender Appender, encoder Encoder) *EncoderAppender {
+	return &EncoderAppender{appender: appender, encoder: encoder}
+}
+
+// Write implements io.Writer. data is written as is.
+func (appender *EncoderAppender) Write(data []byte) (n int, err error) {
+	return appender.appender.Write(data)
+}
+
+// WriteLevel implements LevelWriter. data is written as is.
+func (appender *EncoderAppender) WriteLevel(level LogLevel, data []byte) (n int, err error) {
+	if levelWriter, ok := appender.appender.(LevelWriter); ok {
+		return levelWriter.WriteLevel(level, data)
+	}
+	return appender.appender.Write(data)
+}
+
+// WriteRecord implements RecordWriter
+func (appender *EncoderAppender) WriteRecord(record *Record) error {
+	_, err := appender.WriteLevel(record.Level, appender.encodeRecord(record))
+	return err
+}
+
+// encodeRecord implements recordEncoder
+func (appender *EncoderAppender) encodeRecord(record *Record) []byte {
+	return appender.encoder.Encode(record)
+}
+
+// WriteEvent implements EventWriter for appenders wrapping EncoderAppender
+func (appender *EncoderAppender) WriteEvent(level LogLevel, metadata *LogEventMetadata, event LogEvent) error {
+	record := NewRecord(level, metadata, event)
+	return appender.WriteRecord(&record)
+}
+
+// Close implements io.Closer
+func (appender *EncoderAppender) Close() error {
+	return appender.appender.Close()
+}
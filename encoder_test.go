@@ -0,0 +1,145 @@
+package golog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder(t *testing.T) {
+	newRecord := func(event LogEvent) *Record {
+		metadata := NewLogEventMetadata(&MetadataConfig{IsEnabledLogLevel: true, IsEnabledLoggerName: true}, nil)
+		metadata.LogLevel = LogLevel_WARN
+		metadata.LoggerName = "app"
+		metadata.ContextFields = Fields{{Key: "requestID", Value: "abc"}}
+		record := NewRecord(LogLevel_WARN, &metadata, event)
+		return &record
+	}
+	textEvent := TextLogEvent{Event: "message", Fields: Fields{{Key: "user", Value: "u1"}}}
+
+	t.Run("event", func(t *testing.T) {
+		record := newRecord(textEvent)
+		assert.Equal(t, string(textEvent.Encode(record.Metadata)), string(Encoder_EVENT.Encode(record)))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		assert.Equal(t, "[WARN]  app () message requestID=abc user=u1", string(Encoder_TEXT.Encode(newRecord(textEvent))))
+		assert.Equal(t, `[WARN]  app () {"a":1} requestID=abc`, string(Encoder_TEXT.Encode(newRecord(newJsonLogEvent(map[string]int{"a": 1}, nil)))))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		assert.Equal(t, `{"logLevel":"[WARN]","loggerName":"app","message":"message","requestID":"abc","user":"u1"}`,
+			string(Encoder_JSON.Encode(newRecord(textEvent))))
+
+		record := NewRecord(LogLevel_INFO, nil, FormatLogEvent{format: "%d \"quoted\"", args: []interface{}{1}})
+		assert.Equal(t, `{"message":"1 \"quoted\""}`, string(Encoder_JSON.Encode(&record)))
+
+		record = *newRecord(newJsonLogEvent(map[string]int{"a": 1}, nil))
+		assert.Equal(t, `{"EventData":{"a":1},"logLevel":"[WARN]","loggerName":"app","requestID":"abc"}`, string(Encoder_JSON.Encode(&record)))
+	})
+
+	t.Run("logfmt", func(t *testing.T) {
+		assert.Equal(t, "level=warn logger=app msg=message requestID=abc user=u1", string(Encoder_LOGFMT.Encode(newRecord(textEvent))))
+	})
+
+	t.Run("otlp protobuf", func(t *testing.T) {
+		request := decodeProto(t, Encoder_OTLP_PROTOBUF.Encode(newRecord(textEvent)))
+		scopeLogs := request.message(t, 1, 0).message(t, 2, 0)
+		assert.Equal(t, "app", scopeLogs.message(t, 1, 0).string(1))
+
+		logRecord := scopeLogs.message(t, 2, 0)
+		assert.Equal(t, uint64(13), logRecord[2][0])
+		assert.Equal(t, "WARN", logRecord.string(3))
+		assert.Equal(t, "message", logRecord.message(t, 5, 0).string(1))
+		assert.Len(t, logRecord[6], 2)
+	})
+
+	t.Run("func", func(t *testing.T) {
+		encoder := EncoderFunc(func(record *Record) []byte {
+			return []byte(levelName(record.Level) + ":" + record.Message)
+		})
+		assert.Equal(t, "WARN:message", string(encoder.Encode(newRecord(textEvent))))
+	})
+}
+
+// recordCountingAppender counts records
+type recordCountingAppender struct {
+	*ByteBufferAppender
+	records []*Record
+}
+
+func (appender *recordCountingAppender) WriteRecord(record *Record) error {
+	appender.records = append(appender.records, record)
+	return nil
+}
+
+func TestEncoderAppender(t *testing.T) {
+	console := NewByteBufferAppender()
+	file := NewByteBufferAppender()
+	network := NewByteBufferAppender()
+	counting := &recordCountingAppender{ByteBufferAppender: NewByteBufferAppender()}
+
+	logger := NewLogger("testLogger", LogLevel_INFO,
+		NewEncoderAppender(console, MustPatternLayout("%highlight(%-5level) %msg%n")),
+		NewEncoderAppender(file, Encoder_JSON),
+		NewEncoderAppender(network, Encoder_OTLP_PROTOBUF),
+		counting)
+	logger.SetMetadataConfig(&MetadataConfig{IsEnabledLoggerName: true})
+
+	logger.With("user", "u1").Info("message")
+
+	assert.Equal(t, "\x1b[34mINFO \x1b[0m message\n", console.String())
+
+	var object map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(file.String()), &object))
+	assert.Equal(t, map[string]interface{}{"loggerName": "testLogger", "message": "message", "user": "u1"}, object)
+
+	encoded := network.String()
+	logRecord := decodeProto(t, []byte(encoded[:len(encoded)-1])).message(t, 1, 0).message(t, 2, 0).message(t, 2, 0)
+	assert.Equal(t, "message", logRecord.message(t, 5, 0).string(1))
+
+	// the record is shared by appenders
+	assert.Len(t, counting.records, 1)
+	assert.Equal(t, "message", counting.records[0].Message)
+	assert.Equal(t, Fields{{Key: "user", Value: "u1"}}, counting.records[0].Fields)
+}
+
+func TestEncoderAppender_Async(t *testing.T) {
+	layout := MustPatternLayout("%level:%msg%n")
+
+	t.Run("async appender wraps encoder appender", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		async := NewAsyncAppender(NewEncoderAppender(appender, layout))
+		logger := NewLogger("testLogger", LogLevel_INFO, async)
+
+		logger.Info("message")
+		assert.Nil(t, async.Close())
+
+		assert.Equal(t, "INFO:message\n", appender.String())
+	})
+
+	t.Run("encoder appender wraps async appender", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		async := NewAsyncAppender(appender)
+		logger := NewLogger("testLogger", LogLevel_INFO, NewEncoderAppender(async, layout))
+
+		logger.Info("message")
+		assert.Nil(t, async.Close())
+
+		assert.Equal(t, "INFO:message\n", appender.String())
+	})
+
+	t.Run("pooled json event is encoded before queueing", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		async := NewAsyncAppender(appender)
+		logger := NewLogger("testLogger", LogLevel_INFO, async)
+		logger.DisableLogEventMetadata()
+
+		logger.Infoj(map[string]int{"a": 1})
+		logger.Infoj(map[string]int{"b": 2})
+		assert.Nil(t, async.Close())
+
+		assert.Equal(t, "{\"a\":1}\n{\"b\":2}\n", appender.String())
+	})
+}
@@ -0,0 +1,100 @@
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// badKey is used as the key of a value without key
+const badKey = "!BADKEY"
+
+// Field is a key/value pair attached to a log event
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields
+type Fields []Field
+
+// newFields converts alternating keys and values to Fields.
+// A key which is not a string is converted by fmt.Sprint,
+// and a trailing value without key is stored under "!BADKEY".
+func newFields(keysAndValues ...interface{}) Fields {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	fields := make(Fields, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			fields = append(fields, Field{Key: badKey, Value: keysAndValues[i]})
+			break
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields = append(fields, Field{Key: key, Value: keysAndValues[i+1]})
+	}
+	return fields
+}
+
+// with returns new Fields appended keysAndValues.
+// The receiver is never modified so that it can be shared by derived loggers.
+func (fields Fields) with(keysAndValues ...interface{}) Fields {
+	if len(keysAndValues) == 0 {
+		return fields
+	}
+
+	added := newFields(keysAndValues...)
+	if len(fields) == 0 {
+		return added
+	}
+
+	merged := make(Fields, 0, len(fields)+len(added))
+	merged = append(merged, fields...)
+	return append(merged, added...)
+}
+
+// has reports whether fields contain key
+func (fields Fields) has(key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the value of the last field of key
+func (fields Fields) get(key string) (interface{}, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == key {
+			return fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// appendText appends fields as " key=value" pairs.
+// Values containing spaces, '=' or '"' are quoted.
+func (fields Fields) appendText(buf []byte) []byte {
+	for _, field := range fields {
+		buf = append(buf, ' ')
+		buf = appendTextValue(buf, field.Key)
+		buf = append(buf, '=')
+		buf = appendTextValue(buf, fmt.Sprint(field.Value))
+	}
+	return buf
+}
+
+// appendTextValue
+func appendTextValue(buf []byte, value string) []byte {
+	if value == "" || strings.ContainsAny(value, " =\"\t\r\n") {
+		return strconv.AppendQuote(buf, value)
+	}
+	return append(buf, value...)
+}
@@ -0,0 +1,49 @@
+package golog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFields(t *testing.T) {
+
+	t.Run("returns pairs of keys and values", func(t *testing.T) {
+		fields := newFields("a", 1, "b", "x")
+		assert.Equal(t, Fields{{Key: "a", Value: 1}, {Key: "b", Value: "x"}}, fields)
+	})
+
+	t.Run("non string key is converted", func(t *testing.T) {
+		fields := newFields(1, 2)
+		assert.Equal(t, Fields{{Key: "1", Value: 2}}, fields)
+	})
+
+	t.Run("value without key is stored under bad key", func(t *testing.T) {
+		fields := newFields("a", 1, "b")
+		assert.Equal(t, Fields{{Key: "a", Value: 1}, {Key: badKey, Value: "b"}}, fields)
+	})
+}
+
+func TestFields_with(t *testing.T) {
+
+	t.Run("receiver is not modified", func(t *testing.T) {
+		parent := make(Fields, 1, 10)
+		parent[0] = Field{Key: "a", Value: 1}
+		child1 := parent.with("b", 2)
+		child2 := parent.with("c", 3)
+		assert.Len(t, parent, 1)
+		assert.Equal(t, "b", child1[1].Key)
+		assert.Equal(t, "c", child2[1].Key)
+	})
+}
+
+func TestFields_appendText(t *testing.T) {
+	fields := Fields{
+		{Key: "a", Value: 1},
+		{Key: "b", Value: "x y"},
+		{Key: "c", Value: ""},
+		{Key: "err", Value: errors.New("failed")},
+	}
+	assert.Equal(t, ` a=1 b="x y" c="" err=failed`, string(fields.appendText(nil)))
+}
@@ -0,0 +1,265 @@
+package golog
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONAppender is implemented by values which append themselves as JSON, like encoding.TextAppender.
+// Event data and fields implementing it are encoded without reflection.
+type JSONAppender interface {
+	AppendJSON(buf []byte) []byte
+}
+
+// jsonHex
+const jsonHex = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string escaped in the same way as encoding/json
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', jsonHex[b>>4], jsonHex[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are escaped for JSONP
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', jsonHex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// appendJSONFloat appends f in the same format as encoding/json.
+// NaN and infinities, which are not valid JSON numbers, are written as strings.
+func appendJSONFloat(buf []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		buf = append(buf, '"')
+		buf = strconv.AppendFloat(buf, f, 'g', -1, bits)
+		return append(buf, '"')
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
+
+// appendJSONValue appends value as JSON.
+// Basic types, time.Time, time.Duration, errors, byte slices and JSONAppender are encoded without reflection,
+// and other values are encoded by encoding/json. Values which cannot be encoded are written as strings by fmt.Sprint.
+func appendJSONValue(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return appendJSONString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		return appendJSONFloat(buf, v, 64)
+	case time.Time:
+		buf = append(buf, '"')
+		buf = v.AppendFormat(buf, time.RFC3339Nano)
+		return append(buf, '"')
+	case time.Duration:
+		// nanoseconds as encoding/json
+		return strconv.AppendInt(buf, int64(v), 10)
+	case json.RawMessage:
+		if len(v) == 0 {
+			return append(buf, "null"...)
+		}
+		return append(buf, v...)
+	case []byte:
+		if v == nil {
+			return append(buf, "null"...)
+		}
+		buf = append(buf, '"')
+		buf = base64.StdEncoding.AppendEncode(buf, v)
+		return append(buf, '"')
+	case []string:
+		if v == nil {
+			return append(buf, "null"...)
+		}
+		buf = append(buf, '[')
+		for i, s := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, s)
+		}
+		return append(buf, ']')
+	case []interface{}:
+		if v == nil {
+			return append(buf, "null"...)
+		}
+		buf = append(buf, '[')
+		for i, element := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONValue(buf, element)
+		}
+		return append(buf, ']')
+	case JSONAppender:
+		return v.AppendJSON(buf)
+	case error:
+		return appendJSONString(buf, v.Error())
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return appendJSONString(buf, fmt.Sprint(v))
+		}
+		return append(buf, encoded...)
+	}
+}
+
+// appendJSONKey appends "key": after a comma unless buf ends with the beginning of an object
+func appendJSONKey(buf []byte, key string) []byte {
+	if len(buf) > 0 && buf[len(buf)-1] != '{' {
+		buf = append(buf, ',')
+	}
+	buf = appendJSONString(buf, key)
+	return append(buf, ':')
+}
+
+// appendJSONStringField appends "key":"value" unless value is empty
+func appendJSONStringField(buf []byte, key string, value string) []byte {
+	if value == "" {
+		return buf
+	}
+	return appendJSONString(appendJSONKey(buf, key), value)
+}
+
+// appendJSONMetadata appends the metadata keys of JsonLogEvent. Disabled and empty metadata are omitted.
+// The time, the line and the trace context formatted by the default formatters are appended without allocation.
+func appendJSONMetadata(buf []byte, metadata *LogEventMetadata) []byte {
+	buf = appendJSONStringField(buf, "logLevel", metadata.GetLogLevel())
+
+	if metadata.IsEnabledTime {
+		if isFormatter(metadata.TimeFormatter, defaultTimeFormatter) {
+			buf = append(appendJSONKey(buf, "timestamp"), '"')
+			buf = time.Unix(metadata.UnixTime, int64(metadata.Nanosecond)).AppendFormat(buf, time.RFC3339)
+			buf = append(buf, '"')
+		} else {
+			buf = appendJSONStringField(buf, "timestamp", metadata.GetTime())
+		}
+	}
+
+	if metadata.IsEnabledSourceLine {
+		if isFormatter(metadata.SourceLineFormatter, defaultSourceLineFormatter) {
+			buf = append(appendJSONKey(buf, "sourceLine"), '"')
+			buf = strconv.AppendInt(buf, int64(metadata.SourceLine), 10)
+			buf = append(buf, '"')
+		} else {
+			buf = appendJSONStringField(buf, "sourceLine", metadata.GetSourceLine())
+		}
+	}
+
+	buf = appendJSONStringField(buf, "sourceFile", metadata.GetSourceFile())
+	buf = appendJSONStringField(buf, "loggerName", metadata.GetLoggerName())
+
+	if metadata.IsEnabledTrace && metadata.TraceContext.IsValid() {
+		traceContext := metadata.TraceContext
+		if metadata.TraceIDFormatter == nil {
+			buf = append(appendJSONKey(buf, "trace_id"), '"')
+			buf = hex.AppendEncode(buf, traceContext.TraceID[:])
+			buf = append(buf, '"')
+		} else {
+			buf = appendJSONStringField(buf, "trace_id", metadata.TraceIDFormatter(traceContext.TraceID))
+		}
+		if metadata.SpanIDFormatter == nil {
+			buf = append(appendJSONKey(buf, "span_id"), '"')
+			buf = hex.AppendEncode(buf, traceContext.SpanID[:])
+			buf = append(buf, '"')
+		} else {
+			buf = appendJSONStringField(buf, "span_id", metadata.SpanIDFormatter(traceContext.SpanID))
+		}
+		if metadata.TraceFlagsFormatter == nil {
+			buf = append(appendJSONKey(buf, "trace_flags"), '"')
+			buf = append(buf, jsonHex[traceContext.TraceFlags>>4], jsonHex[traceContext.TraceFlags&0xf])
+			buf = append(buf, '"')
+		} else {
+			buf = appendJSONStringField(buf, "trace_flags", metadata.TraceFlagsFormatter(traceContext.TraceFlags))
+		}
+	}
+	return buf
+}
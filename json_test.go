@@ -0,0 +1,233 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonAppenderValue struct {
+	id int
+}
+
+func (v jsonAppenderValue) AppendJSON(buf []byte) []byte {
+	buf = append(buf, `{"id":`...)
+	buf = strconv.AppendInt(buf, int64(v.id), 10)
+	return append(buf, '}')
+}
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"message",
+		`quote " backslash \ slash /`,
+		"\n\r\t\b\f\x00\x1f\x7f",
+		"<html> & </html>",
+		"日本語 🍣",
+		"  ",
+		"invalid \xff\xfe utf-8 \xe3\x81",
+	} {
+		expected, _ := json.Marshal(s)
+		assert.Equal(t, string(expected), string(appendJSONString(nil, s)), s)
+	}
+}
+
+func TestAppendJSONValue(t *testing.T) {
+
+	t.Run("same as encoding/json", func(t *testing.T) {
+		for _, value := range []interface{}{
+			nil,
+			"message",
+			true,
+			int(-1), int8(-8), int16(-16), int32(-32), int64(math.MinInt64),
+			uint(1), uint8(8), uint16(16), uint32(32), uint64(math.MaxUint64),
+			0.0, 1.5, -2.25, 1e20, 1e21, 1e-6, 1e-7, 123456789.125, math.MaxFloat64, math.SmallestNonzeroFloat64,
+			float32(0.1), float32(1e21), float32(1e-7), float32(3.4028235e38),
+			time.Date(2018, 5, 7, 12, 19, 0, 123456789, time.FixedZone("JST", 9*60*60)),
+			1500 * time.Millisecond,
+			[]byte("bytes"),
+			[]byte{},
+			[]byte(nil),
+			json.RawMessage(`{"raw":true}`),
+			[]string{"a", "<b>"},
+			[]string(nil),
+			[]interface{}{1, "a", nil, 1.5},
+			map[string]int{"b": 2, "a": 1},
+			struct {
+				Name string `json:"name"`
+			}{Name: "name"},
+		} {
+			expected, err := json.Marshal(value)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), string(appendJSONValue(nil, value)), "%#v", value)
+		}
+	})
+
+	t.Run("typed values", func(t *testing.T) {
+		assert.Equal(t, `"failed"`, string(appendJSONValue(nil, errors.New("failed"))))
+		assert.Equal(t, `{"id":1}`, string(appendJSONValue(nil, jsonAppenderValue{id: 1})))
+		assert.Equal(t, `[{"id":2},"failed"]`, string(appendJSONValue(nil, []interface{}{jsonAppenderValue{id: 2}, errors.New("failed")})))
+	})
+
+	t.Run("invalid values are strings", func(t *testing.T) {
+		assert.Equal(t, `"NaN"`, string(appendJSONValue(nil, math.NaN())))
+		assert.Equal(t, `"+Inf"`, string(appendJSONValue(nil, math.Inf(1))))
+		assert.Equal(t, `"-Inf"`, string(appendJSONValue(nil, float32(math.Inf(-1)))))
+		assert.Equal(t, `"{\u003cnil\u003e}"`, string(appendJSONValue(nil, struct{ C chan int }{})))
+	})
+}
+
+func TestJsonLogEvent_AppendEncode(t *testing.T) {
+
+	t.Run("same as Encode and appended to buf", func(t *testing.T) {
+		traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		metadata := newPatternTestMetadata()
+		metadata.TraceContext = traceContext
+		metadata.ContextFields = Fields{{Key: "requestID", Value: "abc"}}
+		event := newJsonLogEvent("message", Fields{{Key: "elapsed", Value: time.Second}})
+
+		expected := `{"EventData":"message","logLevel":"[INFO]","timestamp":"` + time.Unix(1525662860, 0).Format(time.RFC3339) +
+			`","sourceLine":"12","sourceFile":"main.go","loggerName":"app.db.pool","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736",` +
+			`"span_id":"00f067aa0ba902b7","trace_flags":"01","requestID":"abc","elapsed":1000000000}`
+		assert.Equal(t, expected, string(event.Encode(metadata)))
+		assert.Equal(t, "prefix "+expected, string(event.AppendEncode([]byte("prefix "), metadata)))
+	})
+
+	t.Run("custom formatters", func(t *testing.T) {
+		traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		metadata := newPatternTestMetadata()
+		metadata.TraceContext = traceContext
+		metadata.TimeFormatter = func(unixTime UnixTime) string {
+			return `"now"`
+		}
+		metadata.SourceLineFormatter = func(line SourceLine) string {
+			return "L" + strconv.Itoa(line)
+		}
+		metadata.TraceIDFormatter = func(traceID TraceID) string {
+			return "trace"
+		}
+		metadata.SpanIDFormatter = func(spanID SpanID) string {
+			return ""
+		}
+		metadata.TraceFlagsFormatter = func(flags TraceFlags) string {
+			return "sampled"
+		}
+
+		expected := `{"EventData":null,"logLevel":"[INFO]","timestamp":"\"now\"","sourceLine":"L12","sourceFile":"main.go","loggerName":"app.db.pool","trace_id":"trace","trace_flags":"sampled"}`
+		assert.Equal(t, expected, string(JsonLogEvent{}.Encode(metadata)))
+	})
+
+	t.Run("non object event is wrapped after buf", func(t *testing.T) {
+		event := newJsonLogEvent([]int{1, 2}, Fields{{Key: "key", Value: "value"}})
+		assert.Equal(t, `prefix {"EventData":[1,2],"key":"value"}`, string(event.AppendEncode([]byte("prefix "), nil)))
+
+		event = newJsonLogEvent(map[string]string{}, Fields{{Key: "key", Value: "value"}})
+		assert.Equal(t, `{"key":"value"}`, string(event.Encode(nil)))
+	})
+
+	t.Run("without allocation", func(t *testing.T) {
+		metadata := newPatternTestMetadata()
+		event := newBenchmarkJsonLogEvent()
+		buf := make([]byte, 0, 1024)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			buf = event.AppendEncode(buf[:0], metadata)
+		})
+		assert.Equal(t, float64(0), allocs)
+	})
+}
+
+// raceEnabled is set by race_test.go, because sync.Pool drops pooled values randomly under the race detector
+var raceEnabled = false
+
+func TestLogger_Infoj_Allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool allocates under the race detector")
+	}
+
+	logger := NewLogger("benchmark", LogLevel_INFO, discardAppender{})
+	logger.SetMetadataConfig(&MetadataConfig{
+		IsEnabledLogLevel: true,
+		IsEnabledTime:     true,
+	})
+	withFields := logger.With("method", "GET", "status", 200)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		withFields.Infoj("request completed")
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func newBenchmarkJsonLogEvent() JsonLogEvent {
+	return newJsonLogEvent(
+		"request completed",
+		Fields{
+			{Key: "method", Value: "GET"},
+			{Key: "status", Value: 200},
+			{Key: "latency", Value: 1500 * time.Microsecond},
+			{Key: "ratio", Value: 0.75},
+			{Key: "cached", Value: true},
+			{Key: "at", Value: time.Date(2018, 5, 7, 12, 19, 0, 0, time.UTC)},
+			{Key: "body", Value: []byte("body")},
+			{Key: "error", Value: errBenchmark},
+		},
+	)
+}
+
+var errBenchmark = errors.New("connection reset")
+
+func BenchmarkJsonLogEvent_AppendEncode(b *testing.B) {
+	metadata := newPatternTestMetadata()
+	event := newBenchmarkJsonLogEvent()
+	buf := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = event.AppendEncode(buf[:0], metadata)
+	}
+}
+
+func BenchmarkJsonLogEvent_Encode_struct(b *testing.B) {
+	metadata := newPatternTestMetadata()
+	event := newJsonLogEvent(struct {
+		Name    string `json:"name"`
+		Address string `json:"address"`
+	}{Name: "name", Address: "address"}, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		event.Encode(metadata)
+	}
+}
+
+type discardAppender struct{}
+
+func (discardAppender) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (discardAppender) Close() error {
+	return nil
+}
+
+func BenchmarkLogger_Infoj(b *testing.B) {
+	logger := NewLogger("benchmark", LogLevel_INFO, discardAppender{})
+	logger.SetMetadataConfig(&MetadataConfig{
+		IsEnabledLogLevel: true,
+		IsEnabledTime:     true,
+	})
+	withFields := logger.With("method", "GET", "status", 200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		withFields.Infoj("request completed")
+	}
+}
@@ -0,0 +1,600 @@
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// PatternConverter appends the value of a conversion word to buf.
+// option is the text in braces after the conversion word, e.g. "key" of %X{key}. metadata is nil if metadata is disabled.
+type PatternConverter func(buf []byte, level LogLevel, metadata *LogEventMetadata, event LogEvent, option string) []byte
+
+// PatternLayout formats events by a log4j style conversion pattern, e.g.
+//
+//	%d{2006-01-02 15:04:05.000} %-5level [%logger{20}] %file:%line %msg%n
+//
+// A conversion specifier is "%", an optional format modifier, and a conversion word with an optional option in braces.
+// The format modifier "-5" pads the value to 5 characters on the right, "5" pads on the left,
+// ".10" keeps the last 10 characters and ".-10" keeps the first 10 characters.
+// A color word such as %red(...) or %highlight(...) colors the enclosed pattern.
+// PatternLayout implements Encoder, so that it can be set to an appender by EncoderAppender.
+//
+// The pattern is compiled once by NewPatternLayout. The metadata disabled by MetadataConfig is written as an empty string.
+//
+// Conversion words:
+//
+//	d, date            time formatted by the Go layout in braces, or by TimeFormatter if no layout is given
+//	p, le, level       level name, e.g. INFO. It is written even if metadata is disabled
+//	c, lo, logger      logger name. {n} abbreviates the package segments to fit in n characters
+//	F, file            source file
+//	L, line            source line
+//	m, msg, message    message
+//	X, mdc             the value of the field of the key in braces, or all fields as key=value
+//	fields             fields of contexts and the event as key=value
+//	trace_id, span_id, trace_flags
+//	n                  newline
+//	%%                 percent sign
+//
+// Color words: black, red, green, yellow, blue, magenta, cyan, white, gray,
+// boldRed, boldGreen, boldYellow, boldBlue, boldMagenta, boldCyan, boldWhite and highlight, which colors by level.
+type PatternLayout struct {
+	pattern    string
+	nodes      []patternNode
+	converters map[string]PatternConverter
+	color      bool
+}
+
+// PatternLayoutOption
+type PatternLayoutOption func(layout *PatternLayout)
+
+// WithPatternConverter registers a converter of a conversion word.
+// A converter of the name of a built-in word overrides the built-in one.
+func WithPatternConverter(name string, converter PatternConverter) PatternLayoutOption {
+	return func(layout *PatternLayout) {
+		layout.converters[name] = converter
+	}
+}
+
+// WithPatternColor enables or disables color words. If disabled, the enclosed pattern is written without colors.
+// The default is enabled.
+func WithPatternColor(enabled bool) PatternLayoutOption {
+	return func(layout *PatternLayout) {
+		layout.color = enabled
+	}
+}
+
+// patternNode is a literal or a conversion specifier
+type patternNode struct {
+	literal string
+
+	convert  func(buf []byte, event *patternEvent) []byte
+	children []patternNode
+	color    func(level LogLevel) string
+
+	minWidth    int
+	maxWidth    int
+	leftAlign   bool
+	truncateEnd bool
+}
+
+// patternEvent is the event formatted by PatternLayout. The message and the fields are extracted once.
+type patternEvent struct {
+	level    LogLevel
+	metadata *LogEventMetadata
+	event    LogEvent
+
+	extracted bool
+	message   string
+	fields    Fields
+}
+
+// extract
+func (event *patternEvent) extract() {
+	if !event.extracted {
+		event.message, event.fields = eventMessage(event.event)
+		event.extracted = true
+	}
+}
+
+// NewPatternLayout compiles pattern. An error is returned if pattern is invalid or has unknown conversion words.
+func NewPatternLayout(pattern string, options ...PatternLayoutOption) (*PatternLayout, error) {
+	layout := &PatternLayout{
+		pattern:    pattern,
+		converters: map[string]PatternConverter{},
+		color:      true,
+	}
+
+	for _, option := range options {
+		option(layout)
+	}
+
+	parser := patternParser{layout: layout, pattern: pattern}
+	nodes, err := parser.parse(false)
+	if err != nil {
+		return nil, err
+	}
+	layout.nodes = nodes
+	return layout, nil
+}
+
+// MustPatternLayout is like NewPatternLayout but panics if pattern is invalid
+func MustPatternLayout(pattern string, options ...PatternLayoutOption) *PatternLayout {
+	layout, err := NewPatternLayout(pattern, options...)
+	if err != nil {
+		panic(err)
+	}
+	return layout
+}
+
+// String returns the pattern
+func (layout *PatternLayout) String() string {
+	return layout.pattern
+}
+
+// Format formats event
+func (layout *PatternLayout) Format(level LogLevel, metadata *LogEventMetadata, event LogEvent) []byte {
+	return layout.AppendFormat(nil, level, metadata, event)
+}
+
+// AppendFormat appends formatted event to buf
+func (layout *PatternLayout) AppendFormat(buf []byte, level LogLevel, metadata *LogEventMetadata, event LogEvent) []byte {
+	patternEvent := patternEvent{level: level, metadata: metadata, event: event}
+	return appendPatternNodes(buf, layout.nodes, &patternEvent)
+}
+
+// Encode implements Encoder.
+// A trailing newline, e.g. by %n, is removed because appenders terminate each event.
+func (layout *PatternLayout) Encode(record *Record) []byte {
+	patternEvent := patternEvent{
+		level:     record.Level,
+		metadata:  record.Metadata,
+		event:     record.Event,
+		extracted: true,
+		message:   record.Message,
+		fields:    record.Fields,
+	}
+	encoded := appendPatternNodes(nil, layout.nodes, &patternEvent)
+	if n := len(encoded); n > 0 && encoded[n-1] == '\n' {
+		encoded = encoded[:n-1]
+	}
+	return encoded
+}
+
+// appendPatternNodes
+func appendPatternNodes(buf []byte, nodes []patternNode, event *patternEvent) []byte {
+	for i := range nodes {
+		node := &nodes[i]
+		if node.convert == nil && node.children == nil {
+			buf = append(buf, node.literal...)
+			continue
+		}
+
+		start := len(buf)
+		if node.children != nil {
+			buf = appendPatternNodes(buf, node.children, event)
+		} else {
+			buf = node.convert(buf, event)
+		}
+		buf = node.adjust(buf, start)
+
+		if node.color != nil {
+			if code := node.color(event.level); code != "" {
+				buf = colorize(buf, start, code)
+			}
+		}
+	}
+	return buf
+}
+
+// adjust truncates and pads buf[start:] by the format modifier
+func (node *patternNode) adjust(buf []byte, start int) []byte {
+	if node.minWidth == 0 && node.maxWidth == 0 {
+		return buf
+	}
+
+	length := utf8.RuneCount(buf[start:])
+	if node.maxWidth > 0 && length > node.maxWidth {
+		value := buf[start:]
+		if node.truncateEnd {
+			cut := 0
+			for i := 0; i < node.maxWidth; i++ {
+				_, size := utf8.DecodeRune(value[cut:])
+				cut += size
+			}
+			buf = buf[:start+cut]
+		} else {
+			cut := 0
+			for i := 0; i < length-node.maxWidth; i++ {
+				_, size := utf8.DecodeRune(value[cut:])
+				cut += size
+			}
+			buf = append(buf[:start], value[cut:]...)
+		}
+		length = node.maxWidth
+	}
+
+	if length < node.minWidth {
+		padding := node.minWidth - length
+		if node.leftAlign {
+			for i := 0; i < padding; i++ {
+				buf = append(buf, ' ')
+			}
+		} else {
+			for i := 0; i < padding; i++ {
+				buf = append(buf, ' ')
+			}
+			copy(buf[start+padding:], buf[start:len(buf)-padding])
+			for i := 0; i < padding; i++ {
+				buf[start+i] = ' '
+			}
+		}
+	}
+	return buf
+}
+
+// colorize wraps buf[start:] by the ANSI escape code
+func colorize(buf []byte, start int, code string) []byte {
+	prefix := "\x1b[" + code + "m"
+	buf = append(buf, prefix...)
+	copy(buf[start+len(prefix):], buf[start:len(buf)-len(prefix)])
+	copy(buf[start:], prefix)
+	return append(buf, "\x1b[0m"...)
+}
+
+// patternColors are ANSI codes of color words
+var patternColors = map[string]string{
+	"black":       "30",
+	"red":         "31",
+	"green":       "32",
+	"yellow":      "33",
+	"blue":        "34",
+	"magenta":     "35",
+	"cyan":        "36",
+	"white":       "37",
+	"gray":        "90",
+	"boldRed":     "1;31",
+	"boldGreen":   "1;32",
+	"boldYellow":  "1;33",
+	"boldBlue":    "1;34",
+	"boldMagenta": "1;35",
+	"boldCyan":    "1;36",
+	"boldWhite":   "1;37",
+}
+
+// highlightColor returns the color of level
+func highlightColor(level LogLevel) string {
+	switch level {
+	case LogLevel_FATAL, LogLevel_ERROR:
+		return "1;31"
+	case LogLevel_WARN:
+		return "31"
+	case LogLevel_INFO:
+		return "34"
+	default:
+		return ""
+	}
+}
+
+// patternParser
+type patternParser struct {
+	layout  *PatternLayout
+	pattern string
+	pos     int
+}
+
+// parse parses until the end of the pattern, or the closing parenthesis if inGroup
+func (parser *patternParser) parse(inGroup bool) ([]patternNode, error) {
+	nodes := []patternNode{}
+	var literal strings.Builder
+
+	flush := func() {
+		if literal.Len() > 0 {
+			nodes = append(nodes, patternNode{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for parser.pos < len(parser.pattern) {
+		c := parser.pattern[parser.pos]
+		switch {
+		case c == '\\' && parser.pos+1 < len(parser.pattern):
+			literal.WriteByte(parser.pattern[parser.pos+1])
+			parser.pos += 2
+		case c == ')' && inGroup:
+			parser.pos++
+			flush()
+			return nodes, nil
+		case c == '%':
+			if parser.pos+1 < len(parser.pattern) && parser.pattern[parser.pos+1] == '%' {
+				literal.WriteByte('%')
+				parser.pos += 2
+				continue
+			}
+			flush()
+			node, err := parser.parseSpecifier()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		default:
+			literal.WriteByte(c)
+			parser.pos++
+		}
+	}
+
+	if inGroup {
+		return nil, fmt.Errorf("golog: missing ')' in pattern %q", parser.pattern)
+	}
+	flush()
+	return nodes, nil
+}
+
+// parseSpecifier parses a conversion specifier after '%'
+func (parser *patternParser) parseSpecifier() (patternNode, error) {
+	parser.pos++
+	var node patternNode
+
+	// format modifier
+	if parser.peek() == '-' {
+		node.leftAlign = true
+		parser.pos++
+	}
+	node.minWidth = parser.parseNumber()
+	if parser.peek() == '.' {
+		parser.pos++
+		if parser.peek() == '-' {
+			node.truncateEnd = true
+			parser.pos++
+		}
+		if node.maxWidth = parser.parseNumber(); node.maxWidth == 0 {
+			return node, fmt.Errorf("golog: invalid max width in pattern %q", parser.pattern)
+		}
+	}
+
+	// conversion word
+	start := parser.pos
+	for parser.pos < len(parser.pattern) && isPatternWordChar(parser.pattern[parser.pos], parser.pos == start) {
+		parser.pos++
+	}
+	word := parser.pattern[start:parser.pos]
+
+	var option string
+	if parser.peek() == '{' {
+		end := strings.IndexByte(parser.pattern[parser.pos:], '}')
+		if end < 0 {
+			return node, fmt.Errorf("golog: missing '}' in pattern %q", parser.pattern)
+		}
+		option = parser.pattern[parser.pos+1 : parser.pos+end]
+		parser.pos += end + 1
+	}
+
+	// group
+	if parser.peek() == '(' {
+		parser.pos++
+		children, err := parser.parse(true)
+		if err != nil {
+			return node, err
+		}
+		node.children = children
+
+		switch {
+		case word == "":
+		case word == "highlight":
+			node.color = highlightColor
+		case patternColors[word] != "":
+			code := patternColors[word]
+			node.color = func(LogLevel) string { return code }
+		default:
+			return node, fmt.Errorf("golog: unknown color %q in pattern %q", word, parser.pattern)
+		}
+		if !parser.layout.color {
+			node.color = nil
+		}
+		return node, nil
+	}
+
+	if word == "" {
+		return node, fmt.Errorf("golog: missing conversion word at %d in pattern %q", start, parser.pattern)
+	}
+
+	convert, err := parser.converter(word, option)
+	if err != nil {
+		return node, err
+	}
+	node.convert = convert
+	return node, nil
+}
+
+// converter returns the converter of word
+func (parser *patternParser) converter(word string, option string) (func(buf []byte, event *patternEvent) []byte, error) {
+	if converter, ok := parser.layout.converters[word]; ok {
+		return func(buf []byte, event *patternEvent) []byte {
+			return converter(buf, event.level, event.metadata, event.event, option)
+		}, nil
+	}
+
+	switch word {
+	case "d", "date":
+		if option == "" {
+			return func(buf []byte, event *patternEvent) []byte {
+				if event.metadata == nil {
+					return buf
+				}
+				return append(buf, event.metadata.GetTime()...)
+			}, nil
+		}
+		return func(buf []byte, event *patternEvent) []byte {
+			if event.metadata == nil || !event.metadata.IsEnabledTime {
+				return buf
+			}
+			return time.Unix(event.metadata.UnixTime, int64(event.metadata.Nanosecond)).AppendFormat(buf, option)
+		}, nil
+	case "p", "le", "level":
+		return func(buf []byte, event *patternEvent) []byte {
+			return append(buf, levelName(event.level)...)
+		}, nil
+	case "c", "lo", "logger":
+		length := -1
+		if option != "" {
+			n, err := strconv.Atoi(option)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("golog: invalid logger length %q in pattern %q", option, parser.pattern)
+			}
+			length = n
+		}
+		return func(buf []byte, event *patternEvent) []byte {
+			if event.metadata == nil {
+				return buf
+			}
+			return appendAbbreviatedLoggerName(buf, event.metadata.GetLoggerName(), length)
+		}, nil
+	case "F", "file":
+		return func(buf []byte, event *patternEvent) []byte {
+			if event.metadata == nil {
+				return buf
+			}
+			return append(buf, event.metadata.GetSourceFile()...)
+		}, nil
+	case "L", "line":
+		return func(buf []byte, event *patternEvent) []byte {
+			if event.metadata == nil {
+				return buf
+			}
+			return append(buf, event.metadata.GetSourceLine()...)
+		}, nil
+	case "m", "msg", "message":
+		return func(buf []byte, event *patternEvent) []byte {
+			event.extract()
+			return append(buf, event.message...)
+		}, nil
+	case "X", "mdc":
+		if option != "" {
+			return func(buf []byte, event *patternEvent) []byte {
+				event.extract()
+				if event.metadata != nil {
+					if value, ok := event.metadata.ContextFields.get(option); ok {
+						return append(buf, fmt.Sprint(value)...)
+					}
+				}
+				if value, ok := event.fields.get(option); ok {
+					return append(buf, fmt.Sprint(value)...)
+				}
+				return buf
+			}, nil
+		}
+		return appendPatternFields, nil
+	case "fields":
+		return appendPatternFields, nil
+	case "trace_id":
+		return func(buf []byte, event *patternEvent) []byte {
+			if event.metadata == nil {
+				return buf
+			}
+			return append(buf, event.metadata.GetTraceID()...)
+		}, nil
+	case "span_id":
+		return func(buf []byte, event *patternEvent) []byte {
+			if event.metadata == nil {
+				return buf
+			}
+			return append(buf, event.metadata.GetSpanID()...)
+		}, nil
+	case "trace_flags":
+		return func(buf []byte, event *patternEvent) []byte {
+			if event.metadata == nil {
+				return buf
+			}
+			return append(buf, event.metadata.GetTraceFlags()...)
+		}, nil
+	case "n":
+		return func(buf []byte, event *patternEvent) []byte {
+			return append(buf, '\n')
+		}, nil
+	default:
+		return nil, fmt.Errorf("golog: unknown conversion word %q in pattern %q", word, parser.pattern)
+	}
+}
+
+// appendPatternFields appends the fields of contexts and the event as key=value separated by spaces
+func appendPatternFields(buf []byte, event *patternEvent) []byte {
+	event.extract()
+	start := len(buf)
+	if event.metadata != nil {
+		buf = event.metadata.ContextFields.appendText(buf)
+	}
+	buf = event.fields.appendText(buf)
+	if len(buf) > start {
+		// remove the leading space
+		buf = append(buf[:start], buf[start+1:]...)
+	}
+	return buf
+}
+
+// appendAbbreviatedLoggerName abbreviates the dot separated segments except the last one to their first characters
+// until the name fits in length, e.g. "app.db.pool" to "a.d.pool". If length is 0, only the last segment is appended.
+func appendAbbreviatedLoggerName(buf []byte, name string, length int) []byte {
+	if length < 0 || len(name) <= length {
+		return append(buf, name...)
+	}
+
+	last := strings.LastIndexByte(name, '.')
+	if length == 0 || last < 0 {
+		return append(buf, name[last+1:]...)
+	}
+
+	remaining := len(name)
+	rest := name
+	for {
+		i := strings.IndexByte(rest, '.')
+		if i < 0 || remaining <= length {
+			return append(buf, rest...)
+		}
+		// abbreviate the segment to the first character
+		buf = append(buf, rest[0], '.')
+		remaining -= i - 1
+		rest = rest[i+1:]
+	}
+}
+
+// levelName returns the name of level without brackets, e.g. INFO
+func levelName(level LogLevel) string {
+	return strings.Trim(level.String(), "[]")
+}
+
+// isPatternWordChar
+func isPatternWordChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	default:
+		return false
+	}
+}
+
+// peek returns the current character or 0 at the end
+func (parser *patternParser) peek() byte {
+	if parser.pos < len(parser.pattern) {
+		return parser.pattern[parser.pos]
+	}
+	return 0
+}
+
+// parseNumber parses digits. 0 is returned if there are no digits.
+func (parser *patternParser) parseNumber() int {
+	n := 0
+	for parser.pos < len(parser.pattern) && parser.pattern[parser.pos] >= '0' && parser.pattern[parser.pos] <= '9' {
+		n = n*10 + int(parser.pattern[parser.pos]-'0')
+		parser.pos++
+	}
+	return n
+}
+
+// NewPatternLayoutAppender returns new EncoderAppender formatting events by layout
+func NewPatternLayoutAppender(appender Appender, layout *PatternLayout) *EncoderAppender {
+	return NewEncoderAppender(appender, layout)
+}
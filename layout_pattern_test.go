@@ -0,0 +1,176 @@
+package golog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPatternTestMetadata() *LogEventMetadata {
+	metadata := NewLogEventMetadata(nil, nil)
+	metadata.LogLevel = LogLevel_INFO
+	metadata.UnixTime = 1525662860
+	metadata.Nanosecond = 123456789
+	metadata.LoggerName = "app.db.pool"
+	metadata.SourceFile = "/src/app/main.go"
+	metadata.SourceLine = 12
+	return &metadata
+}
+
+func TestPatternLayout_Format(t *testing.T) {
+
+	t.Run("conversion words", func(t *testing.T) {
+		layout, err := NewPatternLayout("%d{2006-01-02 15:04:05.000} %-5level [%logger{20}] %file:%line %msg%n")
+		assert.Nil(t, err)
+
+		metadata := newPatternTestMetadata()
+		expected := time.Unix(1525662860, 123456789).Format("2006-01-02 15:04:05.000") + " INFO  [app.db.pool] main.go:12 message\n"
+		assert.Equal(t, expected, string(layout.Format(LogLevel_INFO, metadata, TextLogEvent{Event: "message"})))
+	})
+
+	t.Run("formatter is used without date layout", func(t *testing.T) {
+		metadata := newPatternTestMetadata()
+		metadata.TimeFormatter = func(unixTime UnixTime) string {
+			return "[timestamp]"
+		}
+		layout := MustPatternLayout("%d %p %c %F %L %m")
+		assert.Equal(t, "[timestamp] WARN app.db.pool main.go 12 message", string(layout.Format(LogLevel_WARN, metadata, TextLogEvent{Event: "message"})))
+	})
+
+	t.Run("disabled metadata is empty except level", func(t *testing.T) {
+		layout := MustPatternLayout("%d{15:04}|%level|%logger|%file|%msg")
+		assert.Equal(t, "|INFO|||message", string(layout.Format(LogLevel_INFO, nil, TextLogEvent{Event: "message"})))
+
+		metadata := newPatternTestMetadata()
+		metadata.MetadataConfig = MetadataConfig{IsEnabledLogLevel: true}
+		assert.Equal(t, "|ERROR|||message", string(layout.Format(LogLevel_ERROR, metadata, TextLogEvent{Event: "message"})))
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		metadata := newPatternTestMetadata()
+		metadata.ContextFields = Fields{{Key: "requestID", Value: "abc"}}
+		metadata.TraceContext = traceContext
+
+		layout := MustPatternLayout("%msg [%fields] [%X{requestID}] [%X{user}] [%X{none}] %trace_id %span_id %trace_flags")
+		event := TextLogEvent{Event: "message", Fields: Fields{{Key: "user", Value: "u 1"}}}
+		assert.Equal(t, `message [requestID=abc user="u 1"] [abc] [u 1] [] 4bf92f3577b34da6a3ce929d0e0e4736 00f067aa0ba902b7 01`,
+			string(layout.Format(LogLevel_INFO, metadata, event)))
+
+		assert.Equal(t, `{"a":1} [] [] [] []   `, string(layout.Format(LogLevel_INFO, nil, newJsonLogEvent(map[string]int{"a": 1}, nil))))
+	})
+
+	t.Run("format modifiers", func(t *testing.T) {
+		for pattern, expected := range map[string]string{
+			"[%5level]":      "[ INFO]",
+			"[%-5level]":     "[INFO ]",
+			"[%.2msg]":       "[語e]",
+			"[%.-2msg]":      "[日本]",
+			"[%6.-3msg]":     "[   日本語]",
+			"[%-8(%p %m)]":   "[INFO 日本語e]",
+			"[%-10(%p %m)]":  "[INFO 日本語e ]",
+			"[%.4(%p %m)]":   "[日本語e]",
+			"[%level{x}]":    "[INFO]",
+			"100%% \\(%m\\)": "100% (日本語e)",
+		} {
+			layout, err := NewPatternLayout(pattern)
+			assert.Nil(t, err, pattern)
+			assert.Equal(t, expected, string(layout.Format(LogLevel_INFO, nil, TextLogEvent{Event: "日本語e"})), pattern)
+		}
+	})
+
+	t.Run("logger abbreviation", func(t *testing.T) {
+		for length, expected := range map[string]string{
+			"":   "com.example.app.Service",
+			"0":  "Service",
+			"5":  "c.e.a.Service",
+			"16": "c.e.app.Service",
+			"20": "c.e.app.Service",
+			"21": "c.example.app.Service",
+			"30": "com.example.app.Service",
+		} {
+			metadata := newPatternTestMetadata()
+			metadata.LoggerName = "com.example.app.Service"
+			layout := MustPatternLayout("%logger{" + length + "}")
+			if length == "" {
+				layout = MustPatternLayout("%logger")
+			}
+			assert.Equal(t, expected, string(layout.Format(LogLevel_INFO, metadata, TextLogEvent{})), length)
+		}
+	})
+
+	t.Run("colors", func(t *testing.T) {
+		layout := MustPatternLayout("%highlight(%-5level) %cyan(%logger{0}) %msg")
+		metadata := newPatternTestMetadata()
+
+		assert.Equal(t, "\x1b[1;31mERROR\x1b[0m \x1b[36mpool\x1b[0m message", string(layout.Format(LogLevel_ERROR, metadata, TextLogEvent{Event: "message"})))
+		assert.Equal(t, "\x1b[34mINFO \x1b[0m \x1b[36mpool\x1b[0m message", string(layout.Format(LogLevel_INFO, metadata, TextLogEvent{Event: "message"})))
+		assert.Equal(t, "DEBUG \x1b[36mpool\x1b[0m message", string(layout.Format(LogLevel_DEBUG, metadata, TextLogEvent{Event: "message"})))
+
+		layout = MustPatternLayout("%highlight(%-5level) %cyan(%logger{0}) %msg", WithPatternColor(false))
+		assert.Equal(t, "ERROR pool message", string(layout.Format(LogLevel_ERROR, metadata, TextLogEvent{Event: "message"})))
+	})
+
+	t.Run("custom converters", func(t *testing.T) {
+		layout, err := NewPatternLayout("%upper{!} %msg %level",
+			WithPatternConverter("upper", func(buf []byte, level LogLevel, metadata *LogEventMetadata, event LogEvent, option string) []byte {
+				message, _ := eventMessage(event)
+				return append(buf, strings.ToUpper(message)+option...)
+			}),
+			WithPatternConverter("level", func(buf []byte, level LogLevel, metadata *LogEventMetadata, event LogEvent, option string) []byte {
+				return append(buf, strings.ToLower(levelName(level))...)
+			}))
+		assert.Nil(t, err)
+		assert.Equal(t, "MESSAGE! message info", string(layout.Format(LogLevel_INFO, nil, TextLogEvent{Event: "message"})))
+	})
+}
+
+func TestNewPatternLayout_Invalid(t *testing.T) {
+	for _, pattern := range []string{
+		"%unknown",
+		"%",
+		"%-5",
+		"%d{2006",
+		"%red(%msg",
+		"%purple(%msg)",
+		"%logger{x}",
+		"%.0msg",
+	} {
+		_, err := NewPatternLayout(pattern)
+		assert.NotNil(t, err, pattern)
+	}
+
+	assert.Panics(t, func() {
+		MustPatternLayout("%unknown")
+	})
+}
+
+func TestPatternLayoutAppender(t *testing.T) {
+	buffer := NewByteBufferAppender()
+	appender := NewPatternLayoutAppender(buffer, MustPatternLayout("%-5level %logger %msg%n"))
+
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	logger.SetMetadataConfig(&MetadataConfig{IsEnabledLoggerName: true})
+	logger.Info("message")
+	logger.Warnw("fields", "key", "value")
+	logger.DisableLogEventMetadata()
+	logger.Error("no metadata")
+
+	assert.Equal(t, "INFO  testLogger message\nWARN  testLogger fields\nERROR  no metadata\n", buffer.String())
+	assert.Nil(t, appender.Close())
+}
+
+func BenchmarkPatternLayout_Format(b *testing.B) {
+	layout := MustPatternLayout("%d{2006-01-02 15:04:05.000} %-5level [%logger{20}] %file:%line %msg%n")
+	metadata := newPatternTestMetadata()
+	event := TextLogEvent{Event: "message"}
+	buf := make([]byte, 0, 256)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = layout.AppendFormat(buf[:0], LogLevel_INFO, metadata, event)
+	}
+}
@@ -1,7 +1,48 @@
 package golog
 
+import (
+	"fmt"
+	"sync"
+)
+
 // LogEvent
 type LogEvent interface {
 
 	Encode(metadata *LogEventMetadata) []byte
 }
+
+// AppendEncoder is an optional interface of LogEvent.
+// If an event implements it, the logger encodes the event into a pooled buffer instead of calling Encode.
+// AppendEncode appends the same bytes as Encode to buf and returns the extended buffer.
+type AppendEncoder interface {
+	AppendEncode(buf []byte, metadata *LogEventMetadata) []byte
+}
+
+// encodeBufferPool holds the buffers of AppendEncoder
+var encodeBufferPool = &sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+// encodeBuffer_MAX_SIZE is the largest buffer returned to encodeBufferPool
+const encodeBuffer_MAX_SIZE = 64 * 1024
+
+// eventMessage returns the message and the fields of event for appenders exporting structured data.
+// Unknown events are encoded without metadata.
+func eventMessage(event LogEvent) (string, Fields) {
+	switch e := event.(type) {
+	case TextLogEvent:
+		return e.Event, e.Fields
+	case LogfmtLogEvent:
+		return e.Message, e.Fields
+	case FormatLogEvent:
+		return fmt.Sprintf(e.format, e.args...), e.fields
+	case JsonLogEvent:
+		data, fields := e.eventData()
+		return string(appendJSONValue(nil, data)), fields
+	default:
+		return string(event.Encode(nil)), nil
+	}
+}
@@ -8,10 +8,11 @@ import (
 type FormatLogEvent struct {
 	format string
 	args   []interface{}
+	fields Fields
 }
 
 // Encode implements LogEvent.Encode
 func (event FormatLogEvent) Encode(metadata *LogEventMetadata) []byte {
 	// delegate to Text log Event
-	return TextLogEvent{Event: fmt.Sprintf(event.format, event.args...),}.Encode(metadata)
+	return TextLogEvent{Event: fmt.Sprintf(event.format, event.args...), Fields: event.fields}.Encode(metadata)
 }
\ No newline at end of file
@@ -0,0 +1,140 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gelfVersion
+const gelfVersion = "1.1"
+
+// defaultGelfHost is the host of messages without host
+var defaultGelfHost = func() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "localhost"
+	}
+	return hostname
+}()
+
+// GelfLogEvent encodes an event as a GELF 1.1 (Graylog Extended Log Format) message.
+//
+// The first line of the message is short_message, and the whole message is full_message if it has multiple lines.
+// The level is mapped to the syslog severity, and the metadata, the fields of contexts,
+// Fields and the fields of the event are added as additional fields prefixed by "_".
+type GelfLogEvent struct {
+	Event LogEvent
+	Level LogLevel
+	// Host is the host of the message. The default is the host name.
+	Host string
+	// Fields are added to the message as additional fields
+	Fields Fields
+}
+
+// NewGelfLogEvent returns new GelfLogEvent
+func NewGelfLogEvent(level LogLevel, event LogEvent) GelfLogEvent {
+	return GelfLogEvent{Event: event, Level: level}
+}
+
+// Encode is implementation of LogEvent.Encode
+func (gelfLogEvent GelfLogEvent) Encode(metadata *LogEventMetadata) []byte {
+	message, fields := eventMessage(gelfLogEvent.Event)
+	message = strings.TrimRight(message, "\n")
+	shortMessage, _, multiline := strings.Cut(message, "\n")
+
+	host := gelfLogEvent.Host
+	if host == "" {
+		host = defaultGelfHost
+	}
+
+	timestamp := time.Now()
+	if metadata != nil && metadata.IsEnabledTime && metadata.UnixTime != 0 {
+		timestamp = time.Unix(metadata.UnixTime, int64(metadata.Nanosecond))
+	}
+
+	encoded := []byte(`{"version":"` + gelfVersion + `"`)
+	encoded = appendGelfField(encoded, "host", host)
+	encoded = appendGelfField(encoded, "short_message", shortMessage)
+	if multiline {
+		encoded = appendGelfField(encoded, "full_message", message)
+	}
+	encoded = append(encoded, `,"timestamp":`...)
+	encoded = strconv.AppendInt(encoded, timestamp.Unix(), 10)
+	encoded = append(encoded, fmt.Sprintf(".%06d", timestamp.Nanosecond()/1000)...)
+	encoded = append(encoded, `,"level":`...)
+	encoded = strconv.AppendInt(encoded, int64(syslogSeverity(gelfLogEvent.Level)), 10)
+
+	if metadata != nil {
+		if metadata.IsEnabledLoggerName && metadata.LoggerName != "" {
+			encoded = appendGelfField(encoded, "_logger", metadata.LoggerName)
+		}
+		if metadata.IsEnabledSourceFile && metadata.SourceFile != "" {
+			encoded = appendGelfField(encoded, "_file", metadata.SourceFile)
+		}
+		if metadata.IsEnabledSourceLine && metadata.SourceLine != 0 {
+			encoded = appendGelfField(encoded, "_line", metadata.SourceLine)
+		}
+		if traceID := metadata.GetTraceID(); traceID != "" {
+			encoded = appendGelfField(encoded, "_trace_id", traceID)
+			encoded = appendGelfField(encoded, "_span_id", metadata.GetSpanID())
+			encoded = appendGelfField(encoded, "_trace_flags", metadata.GetTraceFlags())
+		}
+		encoded = appendGelfFields(encoded, metadata.ContextFields)
+	}
+	encoded = appendGelfFields(encoded, gelfLogEvent.Fields)
+	encoded = appendGelfFields(encoded, fields)
+
+	return append(encoded, '}')
+}
+
+// appendGelfFields appends fields as additional fields
+func appendGelfFields(encoded []byte, fields Fields) []byte {
+	for _, field := range fields {
+		encoded = appendGelfField(encoded, gelfFieldName(field.Key), field.Value)
+	}
+	return encoded
+}
+
+// appendGelfField appends ,"key":value. Values other than strings and numbers are written as strings.
+func appendGelfField(encoded []byte, key string, value interface{}) []byte {
+	switch v := value.(type) {
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+	case error:
+		value = v.Error()
+	default:
+		value = fmt.Sprint(v)
+	}
+
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		encodedValue, _ = json.Marshal(fmt.Sprint(value))
+	}
+
+	encodedKey, _ := json.Marshal(key)
+	encoded = append(encoded, ',')
+	encoded = append(encoded, encodedKey...)
+	encoded = append(encoded, ':')
+	return append(encoded, encodedValue...)
+}
+
+// gelfFieldName returns "_" and key with characters other than letters, digits, underscores, dots and dashes replaced.
+// "_id" is reserved, so "id" is renamed to "_id_".
+func gelfFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	if name == "id" {
+		return "_id_"
+	}
+	return "_" + name
+}
@@ -0,0 +1,71 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeGelf(t *testing.T, encoded []byte) map[string]interface{} {
+	var message map[string]interface{}
+	assert.Nil(t, json.Unmarshal(encoded, &message), string(encoded))
+	return message
+}
+
+func TestGelfLogEvent_Encode(t *testing.T) {
+
+	t.Run("with metadata", func(t *testing.T) {
+		traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		metadata := NewLogEventMetadata(nil, nil)
+		metadata.UnixTime = 1525662860
+		metadata.Nanosecond = 123456789
+		metadata.LoggerName = "testLogger"
+		metadata.SourceFile = "main.go"
+		metadata.SourceLine = 10
+		metadata.TraceContext = traceContext
+		metadata.ContextFields = Fields{{Key: "request_id", Value: "r1"}}
+
+		event := TextLogEvent{Event: "first\nsecond\n", Fields: Fields{{Key: "id", Value: 1}, {Key: "user name", Value: "a"}, {Key: "err", Value: errors.New("e")}}}
+		gelfLogEvent := GelfLogEvent{Event: event, Level: LogLevel_WARN, Host: "host", Fields: Fields{{Key: "env", Value: "test"}}}
+
+		message := decodeGelf(t, gelfLogEvent.Encode(&metadata))
+		assert.Equal(t, map[string]interface{}{
+			"version":       "1.1",
+			"host":          "host",
+			"short_message": "first",
+			"full_message":  "first\nsecond",
+			"timestamp":     1525662860.123456,
+			"level":         float64(4),
+			"_logger":       "testLogger",
+			"_file":         "main.go",
+			"_line":         float64(10),
+			"_trace_id":     "4bf92f3577b34da6a3ce929d0e0e4736",
+			"_span_id":      "00f067aa0ba902b7",
+			"_trace_flags":  "01",
+			"_request_id":   "r1",
+			"_env":          "test",
+			"_id_":          float64(1),
+			"_user_name":    "a",
+			"_err":          "e",
+		}, message)
+	})
+
+	t.Run("without metadata", func(t *testing.T) {
+		message := decodeGelf(t, NewGelfLogEvent(LogLevel_FATAL, newJsonLogEvent(map[string]int{"a": 1}, nil)).Encode(nil))
+		assert.Equal(t, defaultGelfHost, message["host"])
+		assert.Equal(t, `{"a":1}`, message["short_message"])
+		assert.Nil(t, message["full_message"])
+		assert.Equal(t, float64(2), message["level"])
+		assert.NotZero(t, message["timestamp"])
+	})
+
+	t.Run("disabled metadata", func(t *testing.T) {
+		metadata := NewLogEventMetadata(&MetadataConfig{}, nil)
+		metadata.LoggerName = "testLogger"
+		message := decodeGelf(t, NewGelfLogEvent(LogLevel_DEBUG, TextLogEvent{Event: "message"}).Encode(&metadata))
+		assert.Nil(t, message["_logger"])
+		assert.Equal(t, float64(7), message["level"])
+	})
+}
@@ -1,55 +1,100 @@
 package golog
 
-import (
-	"fmt"
-	"os"
-	"encoding/json"
-)
+import "sync"
 
 type EventData interface {
 
 }
 
+// JsonLogEvent is encoded as JSON.
+// It only holds a pointer to the pooled event data, so that it is converted to LogEvent without allocation.
 type JsonLogEvent struct {
-	event EventData
+	data *jsonLogEventData
+}
+
+// jsonLogEventData
+type jsonLogEventData struct {
+	event  EventData
+	fields Fields
+}
+
+// jsonLogEventPool holds the data of JsonLogEvent
+var jsonLogEventPool = sync.Pool{
+	New: func() interface{} {
+		return new(jsonLogEventData)
+	},
+}
+
+// newJsonLogEvent returns JsonLogEvent of the pooled data. It is released by release.
+func newJsonLogEvent(event EventData, fields Fields) JsonLogEvent {
+	data := jsonLogEventPool.Get().(*jsonLogEventData)
+	data.event = event
+	data.fields = fields
+	return JsonLogEvent{data: data}
+}
+
+// release returns the data to the pool. The event must not be used after release.
+func (jsonLogEvent JsonLogEvent) release() {
+	if jsonLogEvent.data != nil {
+		*jsonLogEvent.data = jsonLogEventData{}
+		jsonLogEventPool.Put(jsonLogEvent.data)
+	}
+}
+
+// eventData returns the event data and the fields. The zero value has no data.
+func (jsonLogEvent JsonLogEvent) eventData() (EventData, Fields) {
+	if jsonLogEvent.data == nil {
+		return nil, nil
+	}
+	return jsonLogEvent.data.event, jsonLogEvent.data.fields
 }
 
 // Encode is implementation of LogEvent.Encode
 func (jsonLogEvent JsonLogEvent) Encode(data *LogEventMetadata) []byte {
+	return jsonLogEvent.AppendEncode(nil, data)
+}
+
+// AppendEncode is implementation of AppendEncoder.
+// The event data and the fields are encoded by appendJSONValue, so that typed values are encoded without reflection.
+func (jsonLogEvent JsonLogEvent) AppendEncode(buf []byte, data *LogEventMetadata) []byte {
+	event, fields := jsonLogEvent.eventData()
 
 	if data == nil {
-		// encode json
-		encoded, err := json.Marshal(jsonLogEvent.event)
-		if err != nil {
-			fmt.Fprintf(os.Stdout, err.Error())
+		start := len(buf)
+		buf = appendJSONValue(buf, event)
+
+		if len(fields) > 0 && buf[start] != '{' {
+			// wrap the value as {"EventData":value}
+			const prefix = `{"EventData":`
+			end := len(buf)
+			buf = append(buf, prefix...)
+			copy(buf[start+len(prefix):], buf[start:end])
+			copy(buf[start:], prefix)
+			buf = append(buf, '}')
 		}
 
-		return encoded
+		return appendJsonFields(buf, fields)
 	} else {
 
-		eventData := struct {
-			EventData
-
-			LogLevel   string `json:"logLevel,omitempty"`
-			Time       string `json:"timestamp,omitempty"`
-			SourceLine string `json:"sourceLine,omitempty"`
-			SourceFile string `json:"sourceFile,omitempty"`
-			LoggerName string `json:"loggerName,omitempty"`
-		}{
-			EventData: jsonLogEvent.event,
-
-			LogLevel:   data.GetLogLevel(),
-			Time:       data.GetTime(),
-			SourceLine: data.GetSourceLine(),
-			SourceFile: data.GetSourceFile(),
-			LoggerName: data.GetLoggerName(),
-		}
-		encoded, err := json.Marshal(eventData)
+		buf = append(buf, `{"EventData":`...)
+		buf = appendJSONValue(buf, event)
+		buf = appendJSONMetadata(buf, data)
+		buf = append(buf, '}')
 
-		if err != nil {
-			fmt.Fprint(os.Stdout, err.Error())
-		}
+		return appendJsonFields(appendJsonFields(buf, data.ContextFields), fields)
+	}
+}
 
+// appendJsonFields inserts fields as top level keys of the encoded json object
+func appendJsonFields(encoded []byte, fields Fields) []byte {
+	if len(fields) == 0 || len(encoded) < 2 || encoded[len(encoded)-1] != '}' {
 		return encoded
 	}
+
+	buf := encoded[:len(encoded)-1]
+	for _, field := range fields {
+		buf = appendJSONKey(buf, field.Key)
+		buf = appendJSONValue(buf, field.Value)
+	}
+	return append(buf, '}')
 }
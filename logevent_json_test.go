@@ -9,15 +9,15 @@ func TestJsonLogEvent_Encode(t *testing.T) {
 
 	func() {
 
-		logEvent := JsonLogEvent{
-			event: struct {
+		logEvent := newJsonLogEvent(
+			struct {
 				Name    string `json:"name"`
 				Address string `json:"address"`
 			}{
 				Name:    "name_value",
 				Address: "address_value",
 			},
-		}
+			nil)
 
 		metadata := newDefaultLogEventMetadata("defaultLogger", LogLevel_TRACE)
 		metadata.TimeFormatter = func(time UnixTime) string {
@@ -30,3 +30,38 @@ func TestJsonLogEvent_Encode(t *testing.T) {
 	}()
 
 }
+
+func TestJsonLogEvent_Encode_Fields(t *testing.T) {
+
+	t.Run("fields are top level keys", func(t *testing.T) {
+		logEvent := newJsonLogEvent(
+			struct {
+				Name string `json:"name"`
+			}{Name: "name_value"},
+			Fields{{Key: "requestID", Value: "abc"}, {Key: "count", Value: 1}},
+		)
+		metadata := newDefaultLogEventMetadata("defaultLogger", LogLevel_INFO)
+		metadata.IsEnabledTime = false
+		metadata.IsEnabledSourceFile = false
+		metadata.IsEnabledSourceLine = false
+
+		expected := `{"EventData":{"name":"name_value"},"logLevel":"[INFO]","loggerName":"defaultLogger","requestID":"abc","count":1}`
+		assert.Equal(t, expected, string(logEvent.Encode(metadata)))
+	})
+
+	t.Run("fields are added to the event without metadata", func(t *testing.T) {
+		logEvent := newJsonLogEvent(
+			map[string]string{"name": "name_value"},
+			Fields{{Key: "requestID", Value: "abc"}},
+		)
+		assert.Equal(t, `{"name":"name_value","requestID":"abc"}`, string(logEvent.Encode(nil)))
+	})
+
+	t.Run("non object event is wrapped", func(t *testing.T) {
+		logEvent := newJsonLogEvent(
+			"message",
+			Fields{{Key: "requestID", Value: "abc"}},
+		)
+		assert.Equal(t, `{"EventData":"message","requestID":"abc"}`, string(logEvent.Encode(nil)))
+	})
+}
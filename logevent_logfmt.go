@@ -0,0 +1,132 @@
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LogfmtLogEvent encodes an event as a logfmt line,
+// e.g. level=info ts=2018-05-07T12:19:00+09:00 logger=app caller=main.go:12 msg="hello world" key=value
+//
+// The metadata disabled by MetadataConfig is omitted, and each value is formatted by MetadataFormatter.
+// The level formatted by the default LogLevelFormatter is written in lowercase without brackets.
+type LogfmtLogEvent struct {
+	Message string
+
+	// Fields are written as key=value after msg
+	Fields Fields
+}
+
+// NewLogfmtLogEvent returns new LogfmtLogEvent with alternating keys and values
+func NewLogfmtLogEvent(message string, keysAndValues ...interface{}) LogfmtLogEvent {
+	return LogfmtLogEvent{Message: message, Fields: Fields(nil).with(keysAndValues...)}
+}
+
+// Encode implements LogEvent.Encode
+func (logEvent LogfmtLogEvent) Encode(metadata *LogEventMetadata) []byte {
+	var encoded []byte
+	if metadata != nil {
+		if level := metadata.GetLogLevel(); level != "" {
+			if level == metadata.LogLevel.String() {
+				level = strings.ToLower(strings.Trim(level, "[]"))
+			}
+			encoded = appendLogfmtPair(encoded, "level", level)
+		}
+		if ts := metadata.GetTime(); ts != "" {
+			encoded = appendLogfmtPair(encoded, "ts", ts)
+		}
+		if loggerName := metadata.GetLoggerName(); loggerName != "" {
+			encoded = appendLogfmtPair(encoded, "logger", loggerName)
+		}
+
+		caller := metadata.GetSourceFile()
+		if line := metadata.GetSourceLine(); line != "" {
+			caller += ":" + line
+		}
+		if caller != "" {
+			encoded = appendLogfmtPair(encoded, "caller", caller)
+		}
+	}
+
+	encoded = appendLogfmtPair(encoded, "msg", logEvent.Message)
+
+	if metadata != nil {
+		encoded = appendLogfmtFields(encoded, metadata.ContextFields)
+		if traceID := metadata.GetTraceID(); traceID != "" {
+			encoded = appendLogfmtPair(encoded, "trace_id", traceID)
+			encoded = appendLogfmtPair(encoded, "span_id", metadata.GetSpanID())
+			encoded = appendLogfmtPair(encoded, "trace_flags", metadata.GetTraceFlags())
+		}
+	}
+	return appendLogfmtFields(encoded, logEvent.Fields)
+}
+
+// appendLogfmtFields
+func appendLogfmtFields(encoded []byte, fields Fields) []byte {
+	for _, field := range fields {
+		encoded = appendLogfmtPair(encoded, field.Key, logfmtValue(field.Value))
+	}
+	return encoded
+}
+
+// appendLogfmtPair appends key=value separated by a space
+func appendLogfmtPair(encoded []byte, key string, value string) []byte {
+	if len(encoded) > 0 {
+		encoded = append(encoded, ' ')
+	}
+	encoded = appendLogfmtKey(encoded, key)
+	encoded = append(encoded, '=')
+	return appendLogfmtValue(encoded, value)
+}
+
+// appendLogfmtKey appends key with spaces, '=', '"' and control characters replaced by '_'
+func appendLogfmtKey(encoded []byte, key string) []byte {
+	if key == "" {
+		return append(encoded, '_')
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			r = '_'
+		}
+		encoded = utf8.AppendRune(encoded, r)
+	}
+	return encoded
+}
+
+// appendLogfmtValue appends value, which is quoted if it is empty or contains spaces, '=', '"', '\' or control characters.
+// Newlines and quotes in quoted values are escaped.
+func appendLogfmtValue(encoded []byte, value string) []byte {
+	if needsLogfmtQuote(value) {
+		return strconv.AppendQuote(encoded, value)
+	}
+	return append(encoded, value...)
+}
+
+// needsLogfmtQuote
+func needsLogfmtQuote(value string) bool {
+	if value == "" || !utf8.ValidString(value) {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// logfmtValue formats value of a field
+func logfmtValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(v)
+	}
+}
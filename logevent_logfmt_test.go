@@ -0,0 +1,64 @@
+package golog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtLogEvent_Encode(t *testing.T) {
+
+	t.Run("metadata", func(t *testing.T) {
+		traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		metadata := newDefaultLogEventMetadata("app.db", LogLevel_INFO)
+		metadata.TimeFormatter = func(time UnixTime) string {
+			return "2018-05-07T12:19:00+09:00"
+		}
+		metadata.ContextFields = Fields{{Key: "request_id", Value: "r1"}}
+		metadata.TraceContext = traceContext
+
+		expected := `level=info ts=2018-05-07T12:19:00+09:00 logger=app.db caller=logevent_logfmt_test.go:14 msg="hello world"` +
+			` request_id=r1 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 trace_flags=01 user=u1`
+		assert.Equal(t, expected, string(NewLogfmtLogEvent("hello world", "user", "u1").Encode(metadata)))
+	})
+
+	t.Run("disabled metadata is omitted", func(t *testing.T) {
+		metadata := NewLogEventMetadata(&MetadataConfig{IsEnabledLogLevel: true, IsEnabledSourceFile: true}, nil)
+		metadata.LogLevel = LogLevel_WARN
+		metadata.SourceFile = "/src/main.go"
+		metadata.LoggerName = "app"
+
+		assert.Equal(t, "level=warn caller=main.go msg=message", string(LogfmtLogEvent{Message: "message"}.Encode(&metadata)))
+	})
+
+	t.Run("formatter overrides", func(t *testing.T) {
+		formatter := NewDefaultMetadataFormatter()
+		formatter.LogLevelFormatter = func(level LogLevel) string {
+			return "E"
+		}
+		formatter.LoggerNameFormatter = func(loggerName LoggerName) string {
+			return "[" + loggerName + "]"
+		}
+		metadata := NewLogEventMetadata(&MetadataConfig{IsEnabledLogLevel: true, IsEnabledLoggerName: true}, &formatter)
+		metadata.LogLevel = LogLevel_ERROR
+		metadata.LoggerName = "my app"
+
+		assert.Equal(t, `level=E logger="[my app]" msg=message`, string(LogfmtLogEvent{Message: "message"}.Encode(&metadata)))
+	})
+
+	t.Run("quoting and escaping", func(t *testing.T) {
+		event := NewLogfmtLogEvent("multi\nline \"quoted\"",
+			"empty", "",
+			"equal", "a=b",
+			"path", `C:\dir`,
+			"unicode", "日本語",
+			"err", errors.New("not found"),
+			"nil", nil,
+			"number", 1.5,
+			"bad key", "v")
+
+		expected := `msg="multi\nline \"quoted\"" empty="" equal="a=b" path="C:\\dir" unicode=日本語 err="not found" nil=null number=1.5 bad_key=v`
+		assert.Equal(t, expected, string(event.Encode(nil)))
+	})
+}
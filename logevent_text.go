@@ -18,6 +18,9 @@ func init() {
 // TextLogEvent
 type TextLogEvent struct {
 	Event string
+
+	// Fields are rendered as key=value after the Event
+	Fields Fields
 }
 
 // Encode implements LogEvent.Encode
@@ -35,6 +38,13 @@ func (logEvent TextLogEvent) Encode(metadata *LogEventMetadata) []byte {
 		// get buffer from bufferPool
 		buffer := bufferPool.Get().(*bytes.Buffer)
 		buffer.WriteString(data)
+		if len(metadata.ContextFields) > 0 {
+			buffer.Write(metadata.ContextFields.appendText(nil))
+		}
+		if traceID := metadata.GetTraceID(); traceID != "" {
+			buffer.WriteString(" trace_id=" + traceID + " span_id=" + metadata.GetSpanID() + " trace_flags=" + metadata.GetTraceFlags())
+		}
+		logEvent.writeFields(buffer)
 
 		// release
 		defer func() {
@@ -42,12 +52,14 @@ func (logEvent TextLogEvent) Encode(metadata *LogEventMetadata) []byte {
 			bufferPool.Put(buffer)
 		}()
 
-		return buffer.Bytes()
+		// copy, because the buffer is reused by other goroutines after release
+		return append([]byte(nil), buffer.Bytes()...)
 	}
 
 	// get buffer from bufferPool
 	buffer := bufferPool.Get().(*bytes.Buffer)
 	buffer.WriteString(logEvent.Event)
+	logEvent.writeFields(buffer)
 
 	// release
 	defer func() {
@@ -55,5 +67,13 @@ func (logEvent TextLogEvent) Encode(metadata *LogEventMetadata) []byte {
 		bufferPool.Put(buffer)
 	}()
 
-	return buffer.Bytes()
-}
\ No newline at end of file
+	// copy, because the buffer is reused by other goroutines after release
+	return append([]byte(nil), buffer.Bytes()...)
+}
+
+// writeFields
+func (logEvent TextLogEvent) writeFields(buffer *bytes.Buffer) {
+	if len(logEvent.Fields) > 0 {
+		buffer.Write(logEvent.Fields.appendText(nil))
+	}
+}
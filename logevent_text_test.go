@@ -18,3 +18,11 @@ func TestTextLogEvent_Encode(t *testing.T) {
 	}()
 }
 
+
+func TestTextLogEvent_Encode_Fields(t *testing.T) {
+
+	t.Run("fields are rendered as key=value", func(t *testing.T) {
+		event := TextLogEvent{Event: "test", Fields: Fields{{Key: "requestID", Value: "abc"}, {Key: "user", Value: 1}}}
+		assert.Equal(t, "test requestID=abc user=1", string(event.Encode(nil)))
+	})
+}
@@ -1,9 +1,14 @@
 package golog
 
 import (
+	"context"
 	"os"
 	"fmt"
 	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
 var warnLogger Logger
@@ -11,12 +16,14 @@ var warnLogger Logger
 func init() {
 	warnLogger = Logger {
 		Name: "GoLogLogger",
-		levelAppender:map[LogLevel][]Appender {
-			LogLevel_WARN: {
-				NewConsoleAppender(Destination_STDERR),
+		config: newSharedConfig(&loggerConfig{
+			levelAppender: map[LogLevel][]Appender{
+				LogLevel_WARN: {
+					NewConsoleAppender(Destination_STDERR),
+				},
 			},
-		},
-		enabledMetadata:true,
+			enabledMetadata: true,
+		}),
 	}
 }
 
@@ -30,29 +37,33 @@ type Logger struct {
 	// If you specify an empty string, the default logger name is substituted
 	Name string
 
-	// levelAppender
+	// config
 	// Private Required
-	levelAppender map[LogLevel][]Appender
+	//
+	// Snapshot of appenders and metadata settings, replaced by copy-on-write
+	// so that the logger can be reconfigured while other goroutines are logging,
+	// and the threshold of enabled levels. It is shared with derived loggers.
+	// If not specified, all levels are enabled.
+	config *sharedConfig
 
-	// enabledMetadata
-	// Private Required
-	enabledMetadata bool
 
-	// metadataFormatter
+	// fields
 	// Private Option
 	//
-	// If not specified, the default formatter will be used
-	metadataFormatter *MetadataFormatter
+	// key/value pairs added by With, carried into every event
+	fields Fields
 
-	// metadataConfig
+	// ctx
 	// Private Option
 	//
-	// If not specified, the default config wil be used
-	metadataConfig *MetadataConfig
+	// context.Context set by WithContext, passed to ContextExtractor
+	ctx context.Context
 }
 
-// doAppendIfLevelEnabled
-func (logger *Logger) doAppendIfLevelEnabled(event []byte, level LogLevel) {
+// doAppendIfLevelEnabled encodes event and calls the appenders of level.
+// The event is encoded only once, and only if an appender needs the encoded bytes.
+// The pooled metadata and JsonLogEvent are released after the appenders return, because appenders do not retain them.
+func (logger *Logger) doAppendIfLevelEnabled(config *loggerConfig, metadata *LogEventMetadata, event LogEvent, level LogLevel) {
 
 	// recover
 	defer func(writer io.Writer) {
@@ -61,281 +72,841 @@ func (logger *Logger) doAppendIfLevelEnabled(event []byte, level LogLevel) {
 		}
 	}(os.Stderr)
 
-	if appenders, ok := logger.levelAppender[level]; ok {
+	defer releaseMetadata(metadata)
+	if jsonLogEvent, ok := event.(JsonLogEvent); ok {
+		defer jsonLogEvent.release()
+	}
+
+	if !logger.isEnabledLevel(level) {
+		return
+	}
+
+	if appenders, ok := config.appendersOf(level, logger.Level()); ok {
+		var encoded []byte
+		var pooled *[]byte
+		var record *Record
 		for _, appender := range appenders {
-			appender.Write(event)
+			if recordWriter, ok := appender.(RecordWriter); ok {
+				if record == nil {
+					newRecord := NewRecord(level, metadata, event)
+					record = &newRecord
+				}
+				recordWriter.WriteRecord(record)
+				continue
+			}
+
+			if eventWriter, ok := appender.(EventWriter); ok {
+				eventWriter.WriteEvent(level, metadata, event)
+				continue
+			}
+
+			if encoded == nil {
+				if appendEncoder, ok := event.(AppendEncoder); ok {
+					pooled = encodeBufferPool.Get().(*[]byte)
+					encoded = appendEncoder.AppendEncode((*pooled)[:0], metadata)
+				} else {
+					encoded = event.Encode(metadata)
+				}
+			}
+			if levelWriter, ok := appender.(LevelWriter); ok {
+				levelWriter.WriteLevel(level, encoded)
+			} else {
+				appender.Write(encoded)
+			}
+		}
+
+		// appenders do not retain encoded after Write returns
+		if pooled != nil && cap(encoded) <= encodeBuffer_MAX_SIZE {
+			*pooled = encoded[:0]
+			encodeBufferPool.Put(pooled)
 		}
 	}
 }
 
+// metadataPool holds the metadata of logging calls
+var metadataPool = sync.Pool{
+	New: func() interface{} {
+		return new(LogEventMetadata)
+	},
+}
+
+// acquireMetadata returns the pooled metadata initialized by config. It is released by releaseMetadata.
+func acquireMetadata(config *loggerConfig) *LogEventMetadata {
+	metadata := metadataPool.Get().(*LogEventMetadata)
+	*metadata = NewLogEventMetadata(config.metadataConfig, config.metadataFormatter)
+	return metadata
+}
+
+// releaseMetadata returns metadata to the pool
+func releaseMetadata(metadata *LogEventMetadata) {
+	if metadata != nil {
+		*metadata = LogEventMetadata{}
+		metadataPool.Put(metadata)
+	}
+}
+
 // newMetadata
-func (logger *Logger) newMetadata(level LogLevel) LogEventMetadata {
-	var metadata LogEventMetadata
-	metadata = NewLogEventMetadata(logger.metadataConfig, logger.metadataFormatter)
+func (logger *Logger) newMetadata(ctx context.Context, config *loggerConfig, level LogLevel) *LogEventMetadata {
+	metadata := acquireMetadata(config)
 	metadata.setLogLevel(level)
 	metadata.setLoggerName(logger.Name)
-	metadata.setSource(4)
+	metadata.setSource(3)
 	metadata.setTime()
+	metadata.setContext(ctx, config.contextExtractors)
+	metadata.setTraceContext(ctx, config.traceContextExtractor)
 	return metadata
 }
 
+// appendEventAt appends event with the source and the time given by the caller.
+// It is used by bridges which know the original caller, e.g. log/slog.
+func (logger *Logger) appendEventAt(ctx context.Context, level LogLevel, event LogEvent, pc uintptr, t time.Time) {
+	if !logger.isEnabledLevel(level) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := acquireMetadata(config)
+		metadata.setLogLevel(level)
+		metadata.setLoggerName(logger.Name)
+		metadata.setSourceFromPC(pc)
+		metadata.setTimeAt(t)
+		metadata.setContext(ctx, config.contextExtractors)
+		metadata.setTraceContext(ctx, config.traceContextExtractor)
+		logger.doAppendIfLevelEnabled(config, metadata, event, level)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, level)
+	}
+}
+
 // Trace calls specified appender to print string.
 func (logger *Logger) Trace(string string) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_TRACE)
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(&metadata), LogLevel_TRACE)
+	if !logger.isEnabledLevel(LogLevel_TRACE) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_TRACE)
 	} else {
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(nil), LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_TRACE)
 	}
 }
 
 // Debug calls specified appender to print string.
 func (logger *Logger) Debug(string string) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_DEBUG)
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(&metadata), LogLevel_DEBUG)
+	if !logger.isEnabledLevel(LogLevel_DEBUG) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_DEBUG)
 	} else {
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(nil), LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_DEBUG)
 	}
 }
 
 // Info calls specified appender to print string.
 func (logger *Logger) Info(string string) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_INFO)
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(&metadata), LogLevel_INFO)
+	if !logger.isEnabledLevel(LogLevel_INFO) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_INFO)
 	} else {
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(nil), LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_INFO)
 	}
 }
 
 // Warn calls specified appender to print string.
 func (logger *Logger) Warn(string string) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_WARN)
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(&metadata), LogLevel_WARN)
+	if !logger.isEnabledLevel(LogLevel_WARN) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_WARN)
 	} else {
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(nil), LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_WARN)
 	}
 }
 
 // Error calls specified appender to print string.
 func (logger *Logger) Error(string string) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_ERROR)
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(&metadata), LogLevel_ERROR)
+	if !logger.isEnabledLevel(LogLevel_ERROR) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_ERROR)
 	} else {
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(nil), LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_ERROR)
 	}
 }
 
 // Fatal calls specified appender to print string.
 func (logger *Logger) Fatal(string string) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_FATAL)
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(&metadata), LogLevel_FATAL)
-	} else {
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event: string}.Encode(nil), LogLevel_FATAL)
+	if logger.isEnabledLevel(LogLevel_FATAL) {
+		config := logger.shared().load()
+		if config.enabledMetadata {
+			metadata := logger.newMetadata(logger.ctx, config, LogLevel_FATAL)
+			logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_FATAL)
+		} else {
+			logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_FATAL)
+		}
 	}
 
-	logger.Close()
+	logger.closeAll()
 	os.Exit(1)
 }
 
 // Tracef encodes according to format specifier and calls specified appender to print.
 func (logger *Logger) Tracef(format string, args ...interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_TRACE)
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(&metadata), LogLevel_TRACE)
+	if !logger.isEnabledLevel(LogLevel_TRACE) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, metadata, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_TRACE)
 	} else {
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(nil), LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, nil, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_TRACE)
 	}
 }
 
 // Debugf encodes according to format specifier and calls specified appender to print.
 func (logger *Logger) Debugf(format string, args ...interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_DEBUG)
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(&metadata), LogLevel_DEBUG)
+	if !logger.isEnabledLevel(LogLevel_DEBUG) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, metadata, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_DEBUG)
 	} else {
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(nil), LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, nil, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_DEBUG)
 	}
 }
 
 // Infof encodes according to format specifier and calls specified appender to print.
 func (logger *Logger) Infof(format string, args ...interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_INFO)
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(&metadata), LogLevel_INFO)
+	if !logger.isEnabledLevel(LogLevel_INFO) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, metadata, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_INFO)
 	} else {
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(nil), LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, nil, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_INFO)
 	}
 }
 
 // Warnf encodes according to format specifier and calls specified appender to print.
 func (logger *Logger) Warnf(format string, args ...interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_WARN)
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(&metadata), LogLevel_WARN)
+	if !logger.isEnabledLevel(LogLevel_WARN) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, metadata, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_WARN)
 	} else {
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(nil), LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, nil, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_WARN)
 	}
 }
 
 // Errorf encodes according to format specifier and calls specified appender to print.
 func (logger *Logger) Errorf(format string, args ...interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_ERROR)
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(&metadata), LogLevel_ERROR)
+	if !logger.isEnabledLevel(LogLevel_ERROR) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, metadata, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_ERROR)
 	} else {
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(nil), LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, nil, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_ERROR)
 	}
 }
 
 // Fatalf encodes according to format specifier and calls specified appender to print.
 func (logger *Logger) Fatalf(format string, args ...interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_FATAL)
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(&metadata), LogLevel_FATAL)
-	} else {
-		logger.doAppendIfLevelEnabled(FormatLogEvent{format: format, args: args,}.Encode(nil), LogLevel_FATAL)
+	if logger.isEnabledLevel(LogLevel_FATAL) {
+		config := logger.shared().load()
+		if config.enabledMetadata {
+			metadata := logger.newMetadata(logger.ctx, config, LogLevel_FATAL)
+			logger.doAppendIfLevelEnabled(config, metadata, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_FATAL)
+		} else {
+			logger.doAppendIfLevelEnabled(config, nil, FormatLogEvent{format: format, args: args, fields: logger.fields}, LogLevel_FATAL)
+		}
 	}
 
-	logger.Close()
+	logger.closeAll()
 	os.Exit(1)
 }
 
 // Tracej encodes as Json binary and calls specified appender to print.
 func (logger *Logger) Tracej(obj interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_TRACE)
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(&metadata), LogLevel_TRACE)
+	if !logger.isEnabledLevel(LogLevel_TRACE) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, metadata, newJsonLogEvent(obj, logger.fields), LogLevel_TRACE)
 	} else {
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(nil), LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, nil, newJsonLogEvent(obj, logger.fields), LogLevel_TRACE)
 	}
 }
 
 // Debugj encodes as Json binary and calls specified appender to print.
 func (logger *Logger) Debugj(obj interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_DEBUG)
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(&metadata), LogLevel_DEBUG)
+	if !logger.isEnabledLevel(LogLevel_DEBUG) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, metadata, newJsonLogEvent(obj, logger.fields), LogLevel_DEBUG)
 	} else {
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(nil), LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, nil, newJsonLogEvent(obj, logger.fields), LogLevel_DEBUG)
 	}
 }
 
 // Infoj encodes as Json binary and calls specified appender to print.
 func (logger *Logger) Infoj(obj interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_INFO)
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(&metadata), LogLevel_INFO)
+	if !logger.isEnabledLevel(LogLevel_INFO) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, metadata, newJsonLogEvent(obj, logger.fields), LogLevel_INFO)
 	} else {
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(nil), LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, nil, newJsonLogEvent(obj, logger.fields), LogLevel_INFO)
 	}
 }
 
 // Warnj encodes as Json binary and calls specified appender to print.
 func (logger *Logger) Warnj(obj interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_WARN)
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(&metadata), LogLevel_WARN)
+	if !logger.isEnabledLevel(LogLevel_WARN) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, metadata, newJsonLogEvent(obj, logger.fields), LogLevel_WARN)
 	} else {
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(nil), LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, nil, newJsonLogEvent(obj, logger.fields), LogLevel_WARN)
 	}
 }
 
 // Errorj encodes as Json binary and calls specified appender to print.
 func (logger *Logger) Errorj(obj interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_ERROR)
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(&metadata), LogLevel_ERROR)
+	if !logger.isEnabledLevel(LogLevel_ERROR) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, metadata, newJsonLogEvent(obj, logger.fields), LogLevel_ERROR)
 	} else {
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(nil), LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, nil, newJsonLogEvent(obj, logger.fields), LogLevel_ERROR)
 	}
 }
 
 // Fatalj encodes as Json binary and calls specified appender to print.
 func (logger *Logger) Fatalj(obj interface{}) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_FATAL)
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(&metadata), LogLevel_FATAL)
+	if logger.isEnabledLevel(LogLevel_FATAL) {
+		config := logger.shared().load()
+		if config.enabledMetadata {
+			metadata := logger.newMetadata(logger.ctx, config, LogLevel_FATAL)
+			logger.doAppendIfLevelEnabled(config, metadata, newJsonLogEvent(obj, logger.fields), LogLevel_FATAL)
+		} else {
+			logger.doAppendIfLevelEnabled(config, nil, newJsonLogEvent(obj, logger.fields), LogLevel_FATAL)
+		}
+	}
+
+	logger.closeAll()
+	os.Exit(1)
+}
+
+// Tracew calls specified appender to print string with alternating keys and values.
+func (logger *Logger) Tracew(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_TRACE) {
+		return
+	}
+
+	event := TextLogEvent{Event: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_TRACE)
 	} else {
-		logger.doAppendIfLevelEnabled(JsonLogEvent{event: obj,}.Encode(nil), LogLevel_FATAL)
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_TRACE)
 	}
+}
+
+// Debugw calls specified appender to print string with alternating keys and values.
+func (logger *Logger) Debugw(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_DEBUG) {
+		return
+	}
+
+	event := TextLogEvent{Event: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_DEBUG)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_DEBUG)
+	}
+}
 
-	logger.Close()
+// Infow calls specified appender to print string with alternating keys and values.
+func (logger *Logger) Infow(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_INFO) {
+		return
+	}
+
+	event := TextLogEvent{Event: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_INFO)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_INFO)
+	}
+}
+
+// Warnw calls specified appender to print string with alternating keys and values.
+func (logger *Logger) Warnw(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_WARN) {
+		return
+	}
+
+	event := TextLogEvent{Event: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_WARN)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_WARN)
+	}
+}
+
+// Errorw calls specified appender to print string with alternating keys and values.
+func (logger *Logger) Errorw(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_ERROR) {
+		return
+	}
+
+	event := TextLogEvent{Event: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_ERROR)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_ERROR)
+	}
+}
+
+// Fatalw calls specified appender to print string with alternating keys and values.
+func (logger *Logger) Fatalw(message string, keysAndValues ...interface{}) {
+	if logger.isEnabledLevel(LogLevel_FATAL) {
+		event := TextLogEvent{Event: message, Fields: logger.fields.with(keysAndValues...)}
+		config := logger.shared().load()
+		if config.enabledMetadata {
+			metadata := logger.newMetadata(logger.ctx, config, LogLevel_FATAL)
+			logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_FATAL)
+		} else {
+			logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_FATAL)
+		}
+	}
+
+	logger.closeAll()
+	os.Exit(1)
+}
+
+// Tracel calls specified appender to print string with alternating keys and values in logfmt.
+func (logger *Logger) Tracel(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_TRACE) {
+		return
+	}
+
+	event := LogfmtLogEvent{Message: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_TRACE)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_TRACE)
+	}
+}
+
+// Debugl calls specified appender to print string with alternating keys and values in logfmt.
+func (logger *Logger) Debugl(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_DEBUG) {
+		return
+	}
+
+	event := LogfmtLogEvent{Message: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_DEBUG)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_DEBUG)
+	}
+}
+
+// Infol calls specified appender to print string with alternating keys and values in logfmt.
+func (logger *Logger) Infol(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_INFO) {
+		return
+	}
+
+	event := LogfmtLogEvent{Message: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_INFO)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_INFO)
+	}
+}
+
+// Warnl calls specified appender to print string with alternating keys and values in logfmt.
+func (logger *Logger) Warnl(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_WARN) {
+		return
+	}
+
+	event := LogfmtLogEvent{Message: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_WARN)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_WARN)
+	}
+}
+
+// Errorl calls specified appender to print string with alternating keys and values in logfmt.
+func (logger *Logger) Errorl(message string, keysAndValues ...interface{}) {
+	if !logger.isEnabledLevel(LogLevel_ERROR) {
+		return
+	}
+
+	event := LogfmtLogEvent{Message: message, Fields: logger.fields.with(keysAndValues...)}
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_ERROR)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_ERROR)
+	}
+}
+
+// Fatall calls specified appender to print string with alternating keys and values in logfmt.
+func (logger *Logger) Fatall(message string, keysAndValues ...interface{}) {
+	if logger.isEnabledLevel(LogLevel_FATAL) {
+		event := LogfmtLogEvent{Message: message, Fields: logger.fields.with(keysAndValues...)}
+		config := logger.shared().load()
+		if config.enabledMetadata {
+			metadata := logger.newMetadata(logger.ctx, config, LogLevel_FATAL)
+			logger.doAppendIfLevelEnabled(config, metadata, event, LogLevel_FATAL)
+		} else {
+			logger.doAppendIfLevelEnabled(config, nil, event, LogLevel_FATAL)
+		}
+	}
+
+	logger.closeAll()
 	os.Exit(1)
 }
 
 // STrace encodes as user defined logEvent and calls specified appender to print it.
 func (logger *Logger) STrace(logEvent LogEvent) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_TRACE)
-		logger.doAppendIfLevelEnabled(logEvent.Encode(&metadata), LogLevel_TRACE)
+	if !logger.isEnabledLevel(LogLevel_TRACE) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, metadata, logEvent, LogLevel_TRACE)
 	} else {
-		logger.doAppendIfLevelEnabled(logEvent.Encode(nil), LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, nil, logEvent, LogLevel_TRACE)
 	}
 }
 
 // SDebug encodes as user defined logEvent and calls specified appender to print it.
 func (logger *Logger) SDebug(logEvent LogEvent) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_DEBUG)
-		logger.doAppendIfLevelEnabled(logEvent.Encode(&metadata), LogLevel_DEBUG)
+	if !logger.isEnabledLevel(LogLevel_DEBUG) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, metadata, logEvent, LogLevel_DEBUG)
 	} else {
-		logger.doAppendIfLevelEnabled(logEvent.Encode(nil), LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, nil, logEvent, LogLevel_DEBUG)
 	}
 }
 
 // SInfo encodes as user defined logEvent and calls specified appender to print it.
 func (logger *Logger) SInfo(logEvent LogEvent) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_INFO)
-		logger.doAppendIfLevelEnabled(logEvent.Encode(&metadata), LogLevel_INFO)
+	if !logger.isEnabledLevel(LogLevel_INFO) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, metadata, logEvent, LogLevel_INFO)
 	} else {
-		logger.doAppendIfLevelEnabled(logEvent.Encode(nil), LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, nil, logEvent, LogLevel_INFO)
 	}
 }
 
 // SWarn encodes as user defined logEvent and calls specified appender to print it.
 func (logger *Logger) SWarn(logEvent LogEvent) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_WARN)
-		logger.doAppendIfLevelEnabled(logEvent.Encode(&metadata), LogLevel_WARN)
+	if !logger.isEnabledLevel(LogLevel_WARN) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, metadata, logEvent, LogLevel_WARN)
 	} else {
-		logger.doAppendIfLevelEnabled(logEvent.Encode(nil), LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, nil, logEvent, LogLevel_WARN)
 	}
 }
 
 // SError encodes as user defined logEvent and calls specified appender to print it.
 func (logger *Logger) SError(logEvent LogEvent) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_ERROR)
-		logger.doAppendIfLevelEnabled(logEvent.Encode(&metadata), LogLevel_ERROR)
+	if !logger.isEnabledLevel(LogLevel_ERROR) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(logger.ctx, config, LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, metadata, logEvent, LogLevel_ERROR)
 	} else {
-		logger.doAppendIfLevelEnabled(logEvent.Encode(nil), LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, nil, logEvent, LogLevel_ERROR)
 	}
 }
 
 // SFatal encodes as user defined logEvent and calls specified appender to print it.
 func (logger *Logger) SFatal(logEvent LogEvent) {
-	if logger.enabledMetadata {
-		metadata := logger.newMetadata(LogLevel_FATAL)
-		logger.doAppendIfLevelEnabled(logEvent.Encode(&metadata), LogLevel_FATAL)
+	if logger.isEnabledLevel(LogLevel_FATAL) {
+		config := logger.shared().load()
+		if config.enabledMetadata {
+			metadata := logger.newMetadata(logger.ctx, config, LogLevel_FATAL)
+			logger.doAppendIfLevelEnabled(config, metadata, logEvent, LogLevel_FATAL)
+		} else {
+			logger.doAppendIfLevelEnabled(config, nil, logEvent, LogLevel_FATAL)
+		}
+	}
+
+	logger.closeAll()
+	os.Exit(1)
+}
+
+// TraceCtx calls specified appender to print string with fields extracted from ctx.
+func (logger *Logger) TraceCtx(ctx context.Context, string string) {
+	if !logger.isEnabledLevel(LogLevel_TRACE) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(ctx, config, LogLevel_TRACE)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_TRACE)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_TRACE)
+	}
+}
+
+// DebugCtx calls specified appender to print string with fields extracted from ctx.
+func (logger *Logger) DebugCtx(ctx context.Context, string string) {
+	if !logger.isEnabledLevel(LogLevel_DEBUG) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(ctx, config, LogLevel_DEBUG)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_DEBUG)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_DEBUG)
+	}
+}
+
+// InfoCtx calls specified appender to print string with fields extracted from ctx.
+func (logger *Logger) InfoCtx(ctx context.Context, string string) {
+	if !logger.isEnabledLevel(LogLevel_INFO) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(ctx, config, LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_INFO)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_INFO)
+	}
+}
+
+// WarnCtx calls specified appender to print string with fields extracted from ctx.
+func (logger *Logger) WarnCtx(ctx context.Context, string string) {
+	if !logger.isEnabledLevel(LogLevel_WARN) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(ctx, config, LogLevel_WARN)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_WARN)
+	} else {
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_WARN)
+	}
+}
+
+// ErrorCtx calls specified appender to print string with fields extracted from ctx.
+func (logger *Logger) ErrorCtx(ctx context.Context, string string) {
+	if !logger.isEnabledLevel(LogLevel_ERROR) {
+		return
+	}
+
+	config := logger.shared().load()
+	if config.enabledMetadata {
+		metadata := logger.newMetadata(ctx, config, LogLevel_ERROR)
+		logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_ERROR)
 	} else {
-		logger.doAppendIfLevelEnabled(logEvent.Encode(nil), LogLevel_FATAL)
+		logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_ERROR)
+	}
+}
+
+// FatalCtx calls specified appender to print string with fields extracted from ctx.
+func (logger *Logger) FatalCtx(ctx context.Context, string string) {
+	if logger.isEnabledLevel(LogLevel_FATAL) {
+		config := logger.shared().load()
+		if config.enabledMetadata {
+			metadata := logger.newMetadata(ctx, config, LogLevel_FATAL)
+			logger.doAppendIfLevelEnabled(config, metadata, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_FATAL)
+		} else {
+			logger.doAppendIfLevelEnabled(config, nil, TextLogEvent{Event: string, Fields: logger.fields}, LogLevel_FATAL)
+		}
 	}
 
-	logger.Close()
+	logger.closeAll()
 	os.Exit(1)
 }
 
-// SetAppender
-func (logger *Logger) SetAppender(appender ...Appender) {
-	for k := range logger.levelAppender {
-		logger.levelAppender[k] = appender
+// With returns a derived Logger which adds alternating keys and values to every event.
+// The derived Logger shares appenders and metadata settings with the receiver.
+func (logger *Logger) With(keysAndValues ...interface{}) *Logger {
+	// the zero value is initialized, so that the derived Logger shares the configuration
+	logger.sharedConfig()
+	derived := *logger
+	derived.fields = logger.fields.with(keysAndValues...)
+	return &derived
+}
+
+// WithContext returns a derived Logger which extracts fields from ctx for every event.
+// The derived Logger shares appenders and metadata settings with the receiver.
+func (logger *Logger) WithContext(ctx context.Context) *Logger {
+	// the zero value is initialized, so that the derived Logger shares the configuration
+	logger.sharedConfig()
+	derived := *logger
+	derived.ctx = ctx
+	return &derived
+}
+
+// SetLevel changes the threshold of enabled levels.
+// It is safe to call while other goroutines are logging.
+func (logger *Logger) SetLevel(level LogLevel) {
+	logger.sharedConfig().storeLevel(level)
+}
+
+// Level returns the threshold of enabled levels
+func (logger *Logger) Level() LogLevel {
+	return logger.shared().loadLevel()
+}
+
+// IsEnabled reports whether an event of the level is appended.
+// Use it to skip building expensive arguments.
+func (logger *Logger) IsEnabled(level LogLevel) bool {
+	appenders, ok := logger.shared().load().appendersOf(level, logger.Level())
+	return ok && len(appenders) > 0
+}
+
+// isEnabledLevel reports whether level is above the threshold or set by SetAppenderWithLevel
+func (logger *Logger) isEnabledLevel(level LogLevel) bool {
+	if level >= logger.Level() {
+		return true
 	}
+	_, ok := logger.shared().load().levelAppender[level]
+	return ok
+}
+
+// shared returns the configuration. It is nil for the zero value of Logger until it is configured.
+func (logger *Logger) shared() *sharedConfig {
+	return (*sharedConfig)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&logger.config))))
+}
+
+// sharedConfig returns the configuration, initializing it for the zero value of Logger.
+// It is initialized by CAS, so that concurrent setters update the same configuration.
+func (logger *Logger) sharedConfig() *sharedConfig {
+	if config := logger.shared(); config != nil {
+		return config
+	}
+	atomic.CompareAndSwapPointer((*unsafe.Pointer)(unsafe.Pointer(&logger.config)), nil,
+		unsafe.Pointer(newSharedConfig(&loggerConfig{enabledMetadata: true})))
+	return logger.shared()
+}
+
+// updateConfig replaces the configuration snapshot by a copy modified by fn.
+// The parts modified by fn are not inherited from the parent any more.
+func (logger *Logger) updateConfig(parts configParts, fn func(config *loggerConfig)) {
+	logger.sharedConfig().update(parts, fn)
+}
+
+// SetAppender sets the appenders of all levels, including the levels set by SetAppenderWithLevel
+func (logger *Logger) SetAppender(appender ...Appender) {
+	shared := logger.sharedConfig()
+	logger.updateConfig(configParts_APPENDERS, func(config *loggerConfig) {
+		config.appenders = appender
+		// the levels set by the ancestors are also overridden
+		for k := range shared.load().levelAppender {
+			config.levelAppender[k] = appender
+		}
+	})
 }
 
 // DisableLogEventMetadata
@@ -343,48 +914,94 @@ func (logger *Logger) SetAppender(appender ...Appender) {
 // It is possible to prevent unnecessary allocation.
 // It is enabled by default.
 func (logger *Logger) DisableLogEventMetadata() {
-	logger.enabledMetadata = false
+	logger.updateConfig(configParts_METADATA, func(config *loggerConfig) {
+		config.enabledMetadata = false
+	})
 }
 
 // SetMetadataFormatter
 func (logger *Logger) SetMetadataFormatter(formatter *MetadataFormatter) {
-	logger.metadataFormatter = formatter
+	logger.updateConfig(configParts_METADATA, func(config *loggerConfig) {
+		config.metadataFormatter = formatter
+	})
 }
 
 // SetMetadataConfig
-func (logger *Logger) SetMetadataConfig(config *MetadataConfig) {
-	logger.metadataConfig = config
+func (logger *Logger) SetMetadataConfig(metadataConfig *MetadataConfig) {
+	logger.updateConfig(configParts_METADATA, func(config *loggerConfig) {
+		config.metadataConfig = metadataConfig
+	})
+}
+
+// SetContextExtractor sets extractors of fields of metadata from context.Context.
+// The context is given by WithContext or the Ctx methods, e.g. InfoCtx.
+func (logger *Logger) SetContextExtractor(extractor ...ContextExtractor) {
+	logger.updateConfig(configParts_EXTRACTORS, func(config *loggerConfig) {
+		config.contextExtractors = extractor
+	})
 }
 
-// SetLogLevel enables the specified log level
+// SetTraceContextExtractor sets the function which returns the span active in context.Context.
+// By default, TraceContext stored by ContextWithTraceContext is used.
+func (logger *Logger) SetTraceContextExtractor(extractor TraceContextExtractor) {
+	logger.updateConfig(configParts_EXTRACTORS, func(config *loggerConfig) {
+		config.traceContextExtractor = extractor
+	})
+}
+
+// SetAppenderWithLevel sets the appenders of the specified log level.
+// The level is enabled even if it is below the threshold of SetLevel.
 func (logger *Logger) SetAppenderWithLevel(logLevel LogLevel, appender ...Appender) {
-	logger.levelAppender[logLevel] = appender
+	logger.updateConfig(0, func(config *loggerConfig) {
+		config.levelAppender[logLevel] = appender
+	})
 }
 
-// SetLogLevel enables the specified log level
+// SetAppenderWithLevels sets the appenders of the specified log levels.
+// The levels are enabled even if they are below the threshold of SetLevel.
 func (logger *Logger) SetAppenderWithLevels(logLevels []LogLevel, appender ...Appender) {
-	for _, v := range logLevels {
-		logger.levelAppender[v] = appender
-	}
+	logger.updateConfig(0, func(config *loggerConfig) {
+		for _, v := range logLevels {
+			config.levelAppender[v] = appender
+		}
+	})
 }
 
-// Close implements io.Closer
+// Close implements io.Closer.
+// It closes the appenders set by the logger itself. The appenders inherited from the ancestors
+// of the hierarchy are not closed, because they are used by the other loggers.
 func (logger *Logger) Close() error {
+	closeConfigAppenders(logger.shared().own())
+	return nil
+}
 
-	for _,v := range logger.levelAppender {
-		for _, appender := range v {
-			err := appender.Close()
-			if err != nil {
-				warnLogger.Warnf("close appender is failed , error : %s", err.Error())
-			}
+// closeAll closes all appenders used by the logger, including the inherited ones, before exiting by Fatal
+func (logger *Logger) closeAll() {
+	closeConfigAppenders(logger.shared().load())
+}
+
+// closeConfigAppenders closes the appenders of the parts set in config
+func closeConfigAppenders(config *loggerConfig) {
+	if config.parts&configParts_APPENDERS != 0 {
+		closeAppenders(config.appenders)
+	}
+	for _, v := range config.levelAppender {
+		closeAppenders(v)
+	}
+}
+
+// closeAppenders
+func closeAppenders(appenders []Appender) {
+	for _, appender := range appenders {
+		err := appender.Close()
+		if err != nil {
+			warnLogger.Warnf("close appender is failed , error : %s", err.Error())
 		}
 	}
-	return nil
 }
 
 // NewLogger
 func NewLogger(loggerName string, logLevel LogLevel, appender ...Appender) Logger {
-	levelAppender := map[LogLevel][]Appender{}
 	logLevels := NewDefaultLevelFilter().DoFilter(logLevel)
 
 	if len(logLevels) == 0 {
@@ -395,14 +1012,17 @@ func NewLogger(loggerName string, logLevel LogLevel, appender ...Appender) Logge
 		warnLogger.Warn("no appender is specified")
 	}
 
-	for _, logLevel := range logLevels {
-		levelAppender[logLevel] = appender
-	}
+	// the appenders are filtered by the threshold, so that the threshold can be lowered by SetLevel later
+	config := newSharedConfig(&loggerConfig{
+		appenders:       appender,
+		levelAppender:   map[LogLevel][]Appender{},
+		enabledMetadata: true,
+	})
+	config.storeLevel(logLevel)
 
 	return Logger{
-		Name:            loggerName,
-		levelAppender:   levelAppender,
-		enabledMetadata: true,
+		Name:   loggerName,
+		config: config,
 	}
 }
 
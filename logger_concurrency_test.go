@@ -0,0 +1,136 @@
+package golog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// These tests are meant to be run with the race detector : go test -race
+
+func TestLogger_ConcurrentReconfiguration(t *testing.T) {
+	appender1 := NewByteBufferAppender()
+	appender2 := NewByteBufferAppender()
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender1)
+	derived := logger.With("key", "value")
+
+	const loggers = 8
+	const iterations = 200
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < loggers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				logger.Info("message")
+				logger.Debugf("message %d", j)
+				logger.Warnj(map[string]int{"count": j})
+				derived.Infow("message", "index", j)
+				logger.IsEnabled(LogLevel_INFO)
+			}
+		}()
+	}
+
+	var reconfigure sync.WaitGroup
+	reconfigure.Add(1)
+	go func() {
+		defer reconfigure.Done()
+		formatter := NewDefaultMetadataFormatter()
+		metadataConfig := NewDefaultMetadataConfig()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			logger.SetAppender(appender1, appender2)
+			logger.SetAppenderWithLevel(LogLevel_WARN, appender2)
+			logger.SetAppenderWithLevels([]LogLevel{LogLevel_DEBUG, LogLevel_INFO}, appender1)
+			logger.SetMetadataFormatter(&formatter)
+			logger.SetMetadataConfig(&metadataConfig)
+			logger.SetLevel(LogLevel_DEBUG)
+			logger.SetLevel(LogLevel_TRACE)
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	reconfigure.Wait()
+
+	// every event is written entirely to at least one appender
+	lines := strings.Count(appender1.String(), "\n") + strings.Count(appender2.String(), "\n")
+	assert.True(t, lines >= loggers*iterations*4)
+}
+
+func TestLogger_ConcurrentDisableMetadata(t *testing.T) {
+	appender := NewByteBufferAppender()
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logger.Info("message")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		logger.DisableLogEventMetadata()
+	}()
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSuffix(appender.String(), "\n"), "\n") {
+		assert.True(t, strings.HasSuffix(line, "message"))
+	}
+}
+
+func TestLogger_ConcurrentZeroValueConfiguration(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		appender := NewByteBufferAppender()
+		var logger Logger
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			logger.SetLevel(LogLevel_WARN)
+		}()
+		go func() {
+			defer wg.Done()
+			logger.SetAppender(appender)
+		}()
+		go func() {
+			defer wg.Done()
+			logger.DisableLogEventMetadata()
+		}()
+		wg.Wait()
+
+		// no update is lost
+		logger.Info("info")
+		logger.Warn("warn")
+		assert.Equal(t, "warn\n", appender.String())
+	}
+}
+
+func TestTextLogEvent_Encode_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			expected := strings.Repeat(string(rune('a'+i)), 64)
+			for j := 0; j < 200; j++ {
+				encoded := TextLogEvent{Event: expected}.Encode(nil)
+				// the result must not be shared with other goroutines
+				assert.Equal(t, expected, string(encoded))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,242 @@
+package golog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// loggerConfig is an immutable snapshot of the configuration of Logger.
+// Logging methods read the current snapshot without locks,
+// and setters replace it by a modified copy (copy-on-write).
+type loggerConfig struct {
+	// appenders
+	// Private Required
+	//
+	// Appenders of the levels enabled by the threshold of SetLevel
+	appenders []Appender
+
+	// levelAppender
+	// Private Option
+	//
+	// Appenders of the levels set by SetAppenderWithLevel.
+	// The levels are enabled regardless of the threshold.
+	levelAppender map[LogLevel][]Appender
+
+	// enabledMetadata
+	// Private Required
+	enabledMetadata bool
+
+	// metadataFormatter
+	// Private Option
+	//
+	// If not specified, the default formatter will be used
+	metadataFormatter *MetadataFormatter
+
+	// metadataConfig
+	// Private Option
+	//
+	// If not specified, the default config wil be used
+	metadataConfig *MetadataConfig
+
+	// contextExtractors
+	// Private Option
+	//
+	// Extract fields of metadata from context.Context
+	contextExtractors []ContextExtractor
+
+	// traceContextExtractor
+	// Private Option
+	//
+	// If not specified, TraceContextFromContext is used
+	traceContextExtractor TraceContextExtractor
+
+	// parts
+	// Private Required
+	//
+	// The parts set by the logger itself. The other parts are inherited from the parent.
+	// levelAppender is inherited per level regardless of parts.
+	parts configParts
+}
+
+// configParts is a set of the parts of loggerConfig which are inherited separately
+type configParts uint8
+
+// configParts Constants
+const (
+	// configParts_APPENDERS is appenders
+	configParts_APPENDERS configParts = 1 << iota
+	// configParts_METADATA is enabledMetadata, metadataFormatter and metadataConfig
+	configParts_METADATA
+	// configParts_EXTRACTORS is contextExtractors and traceContextExtractor
+	configParts_EXTRACTORS
+
+	configParts_ALL = configParts_APPENDERS | configParts_METADATA | configParts_EXTRACTORS
+)
+
+// emptyLoggerConfig is used by the zero value of Logger
+var emptyLoggerConfig = &loggerConfig{parts: configParts_ALL}
+
+// clone returns a copy which can be modified without affecting the receiver.
+// Appender slices are shared because they are replaced, never modified in place.
+func (config *loggerConfig) clone() *loggerConfig {
+	cloned := *config
+	cloned.levelAppender = make(map[LogLevel][]Appender, len(config.levelAppender))
+	for level, appenders := range config.levelAppender {
+		cloned.levelAppender[level] = appenders
+	}
+	return &cloned
+}
+
+// copyParts copies parts from config
+func (config *loggerConfig) copyParts(from *loggerConfig, parts configParts) {
+	if parts&configParts_APPENDERS != 0 {
+		config.appenders = from.appenders
+	}
+	if parts&configParts_METADATA != 0 {
+		config.enabledMetadata = from.enabledMetadata
+		config.metadataFormatter = from.metadataFormatter
+		config.metadataConfig = from.metadataConfig
+	}
+	if parts&configParts_EXTRACTORS != 0 {
+		config.contextExtractors = from.contextExtractors
+		config.traceContextExtractor = from.traceContextExtractor
+	}
+}
+
+// appendersOf returns the appenders of level and whether level is enabled.
+// The appenders set for the level win over the appenders enabled by threshold.
+func (config *loggerConfig) appendersOf(level LogLevel, threshold LogLevel) ([]Appender, bool) {
+	if appenders, ok := config.levelAppender[level]; ok {
+		return appenders, true
+	}
+	if level >= threshold {
+		return config.appenders, true
+	}
+	return nil, false
+}
+
+// levelInherited is the level of a sharedConfig which inherits the level of its parent
+const levelInherited = -1
+
+// configGeneration is incremented by every update, so that the resolved configurations of
+// the loggers inheriting from the updated one are resolved again
+var configGeneration uint64
+
+// resolvedConfig is the configuration merged with the ancestors at generation
+type resolvedConfig struct {
+	generation uint64
+	config     *loggerConfig
+}
+
+// sharedConfig holds the current snapshot and the threshold of enabled levels.
+// It is shared with derived loggers.
+//
+// A sharedConfig of a registered logger inherits from its parent:
+// appenders, metadata settings, extractors and the level are inherited separately until they are set,
+// and the appenders of each level set by SetAppenderWithLevel are inherited unless the level is set.
+type sharedConfig struct {
+	value    atomic.Value
+	resolved atomic.Value
+	level    int32
+	parent   *sharedConfig
+	mu       sync.Mutex
+}
+
+// newSharedConfig returns a sharedConfig which sets all parts
+func newSharedConfig(config *loggerConfig) *sharedConfig {
+	config.parts = configParts_ALL
+	shared := new(sharedConfig)
+	shared.value.Store(config)
+	return shared
+}
+
+// newInheritedConfig returns a sharedConfig inheriting everything from parent
+func newInheritedConfig(parent *sharedConfig) *sharedConfig {
+	shared := &sharedConfig{
+		level:  levelInherited,
+		parent: parent,
+	}
+	shared.value.Store(&loggerConfig{})
+	return shared
+}
+
+// own returns the snapshot of the parts set by the logger itself
+func (shared *sharedConfig) own() *loggerConfig {
+	if shared == nil {
+		return emptyLoggerConfig
+	}
+	return shared.value.Load().(*loggerConfig)
+}
+
+// load returns the snapshot merged with the ancestors.
+// The merged snapshot is cached until any configuration is updated.
+func (shared *sharedConfig) load() *loggerConfig {
+	if shared == nil {
+		return emptyLoggerConfig
+	}
+	if shared.parent == nil {
+		return shared.own()
+	}
+
+	// the generation is loaded before resolving, so that an update while resolving is not missed
+	generation := atomic.LoadUint64(&configGeneration)
+	if resolved, _ := shared.resolved.Load().(*resolvedConfig); resolved != nil && resolved.generation == generation {
+		return resolved.config
+	}
+
+	config := shared.resolve()
+	shared.resolved.Store(&resolvedConfig{generation: generation, config: config})
+	return config
+}
+
+// resolve merges the parts of the nearest ancestors which set them (including itself)
+func (shared *sharedConfig) resolve() *loggerConfig {
+	resolved := &loggerConfig{
+		levelAppender: map[LogLevel][]Appender{},
+		parts:         configParts_ALL,
+	}
+
+	inherited := configParts_ALL
+	for current := shared; current != nil; current = current.parent {
+		config := current.own()
+		resolved.copyParts(config, config.parts&inherited)
+		inherited &^= config.parts
+
+		for level, appenders := range config.levelAppender {
+			if _, ok := resolved.levelAppender[level]; !ok {
+				resolved.levelAppender[level] = appenders
+			}
+		}
+	}
+	return resolved
+}
+
+// loadLevel returns the level of the nearest configured ancestor (including itself)
+func (shared *sharedConfig) loadLevel() LogLevel {
+	for current := shared; current != nil; current = current.parent {
+		if level := atomic.LoadInt32(&current.level); level != levelInherited {
+			return LogLevel(level)
+		}
+	}
+	return LogLevel_TRACE
+}
+
+// storeLevel
+func (shared *sharedConfig) storeLevel(level LogLevel) {
+	atomic.StoreInt32(&shared.level, level.TypeVal())
+}
+
+// update replaces the own snapshot by a copy modified by fn, and stops inheriting parts.
+// The parts which are inherited are copied from the inherited snapshot before fn is called.
+// Concurrent updates are serialized so that no update is lost.
+func (shared *sharedConfig) update(parts configParts, fn func(config *loggerConfig)) {
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+
+	config := shared.own().clone()
+	config.copyParts(shared.load(), parts&^config.parts)
+	config.parts |= parts
+	fn(config)
+	shared.value.Store(config)
+	atomic.AddUint64(&configGeneration, 1)
+}
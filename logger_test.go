@@ -1,10 +1,13 @@
 package golog
 
 import (
+	"context"
+	"runtime"
 	"testing"
 	"fmt"
 	"os"
 	"log"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -52,15 +55,15 @@ func TestNewLogger(t *testing.T) {
 		logger := NewLogger("testLogger", LogLevel_TRACE)
 		appender := NewByteBufferAppender()
 		logger.SetAppender(appender)
-		logger.SInfo(JsonLogEvent{
-			event: struct{
+		logger.SInfo(newJsonLogEvent(
+			struct{
 				Name string `json:"name"`
 				Address string `json:"address"`
 			}{
 				Name:"name_value",
 				Address:"address_value",
 			},
-		})
+			nil))
 		fmt.Println(appender.String())
 	}()
 
@@ -119,7 +122,7 @@ func BenchmarkLogger_text_to_buffer(b *testing.B) {
 	logger.DisableLogEventMetadata()
 
 	for i := 0; i< b.N; i++ {
-		logger.doAppendIfLevelEnabled(TextLogEvent{Event:"ssss"}.Encode(nil), LogLevel_INFO)
+		logger.doAppendIfLevelEnabled(logger.config.load(), nil, TextLogEvent{Event:"ssss"}, LogLevel_INFO)
 	}
 }
 
@@ -155,4 +158,145 @@ func BenchmarkLogger_default(b *testing.B) {
 	for i :=0; i<b.N; i++ {
 		log.Print("xxxxxxx")
 	}
-}
\ No newline at end of file
+}
+func TestLogger_With(t *testing.T) {
+
+	t.Run("fields are carried into every event", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.DisableLogEventMetadata()
+
+		child := logger.With("requestID", "abc")
+		child.Info("message1")
+		child.Infof("message%d", 2)
+		child.Infow("message3", "user", "u1")
+		logger.Info("message4")
+
+		assert.Equal(t, "message1 requestID=abc\nmessage2 requestID=abc\nmessage3 requestID=abc user=u1\nmessage4\n", appender.String())
+	})
+
+	t.Run("fields are top level keys of json event", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.DisableLogEventMetadata()
+
+		logger.With("requestID", "abc").Infoj(map[string]string{"name": "name_value"})
+		assert.Equal(t, `{"name":"name_value","requestID":"abc"}`+"\n", appender.String())
+	})
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+
+	t.Run("level given to NewLogger is the threshold", func(t *testing.T) {
+		logger := NewLogger("testLogger", LogLevel_WARN, NewByteBufferAppender())
+		assert.Equal(t, LogLevel_WARN, logger.Level())
+		assert.False(t, logger.IsEnabled(LogLevel_INFO))
+		assert.True(t, logger.IsEnabled(LogLevel_WARN))
+	})
+
+	t.Run("threshold can be lowered and raised", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_WARN, appender)
+		logger.DisableLogEventMetadata()
+
+		logger.Debug("debug1")
+		logger.SetLevel(LogLevel_DEBUG)
+		logger.Debug("debug2")
+		logger.SetLevel(LogLevel_ERROR)
+		logger.Warn("warn")
+		logger.Error("error")
+
+		assert.Equal(t, "debug2\nerror\n", appender.String())
+	})
+
+	t.Run("derived logger shares the threshold", func(t *testing.T) {
+		logger := NewLogger("testLogger", LogLevel_INFO, NewByteBufferAppender())
+		child := logger.With("key", "value")
+		logger.SetLevel(LogLevel_ERROR)
+		assert.Equal(t, LogLevel_ERROR, child.Level())
+	})
+
+	t.Run("level without appender is not enabled", func(t *testing.T) {
+		logger := NewLogger("testLogger", LogLevel_TRACE)
+		assert.False(t, logger.IsEnabled(LogLevel_INFO))
+	})
+
+	t.Run("zero value logger enables all levels", func(t *testing.T) {
+		logger := Logger{}
+		assert.Equal(t, LogLevel_TRACE, logger.Level())
+	})
+
+	t.Run("level set by SetAppenderWithLevel is enabled below the threshold", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		debugAppender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_INFO, appender)
+		logger.DisableLogEventMetadata()
+		logger.SetAppenderWithLevel(LogLevel_DEBUG, debugAppender)
+
+		assert.True(t, logger.IsEnabled(LogLevel_DEBUG))
+		assert.False(t, logger.IsEnabled(LogLevel_TRACE))
+		logger.Debug("debug")
+		logger.Trace("trace")
+		logger.Info("info")
+
+		assert.Equal(t, "debug\n", debugAppender.String())
+		assert.Equal(t, "info\n", appender.String())
+	})
+}
+
+func TestLogger_Source(t *testing.T) {
+	appender := NewByteBufferAppender()
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	logger.SetMetadataConfig(&MetadataConfig{IsEnabledSourceFile: true, IsEnabledSourceLine: true})
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.Info("info")
+	logger.Infow("infow", "key", "value")
+
+	expected := fmt.Sprintf("   logger_test.go(%d) info\n   logger_test.go(%d) infow key=value\n", line+1, line+2)
+	assert.Equal(t, expected, appender.String())
+}
+
+func TestLogger_Logfmt(t *testing.T) {
+	appender := NewByteBufferAppender()
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	logger.SetMetadataConfig(&MetadataConfig{IsEnabledLogLevel: true, IsEnabledLoggerName: true, IsEnabledSourceFile: true, IsEnabledSourceLine: true})
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.With("requestID", "abc").Warnl("disk is full", "free", 0)
+	logger.Debugl("debug")
+
+	expected := fmt.Sprintf("level=warn logger=testLogger caller=logger_test.go:%d msg=\"disk is full\" requestID=abc free=0\n"+
+		"level=debug logger=testLogger caller=logger_test.go:%d msg=debug\n", line+1, line+2)
+	assert.Equal(t, expected, appender.String())
+}
+
+func TestLogger_DisabledLevel(t *testing.T) {
+	appender := NewByteBufferAppender()
+	logger := NewLogger("testLogger", LogLevel_INFO, appender)
+
+	extracted := 0
+	logger.SetContextExtractor(ContextExtractorFunc(func(ctx context.Context) Fields {
+		extracted++
+		return nil
+	}))
+
+	t.Run("metadata is not built for disabled levels", func(t *testing.T) {
+		logger.Debug("debug")
+		logger.Debugw("debugw", "key", "value")
+		logger.Tracel("tracel", "key", "value")
+		logger.DebugCtx(context.Background(), "debugctx")
+		assert.Equal(t, 0, extracted)
+		assert.Equal(t, "", appender.String())
+
+		logger.InfoCtx(context.Background(), "info")
+		assert.Equal(t, 1, extracted)
+	})
+
+	t.Run("disabled levels do not allocate", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.Debugw("debugw", "key", "value")
+		})
+		assert.Equal(t, float64(0), allocs)
+	})
+}
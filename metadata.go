@@ -1,6 +1,7 @@
 package golog
 
 import (
+	"context"
 	"runtime"
 	"time"
 )
@@ -21,10 +22,20 @@ type UnixTime = int64
 type LogEventMetadata struct {
 	LogLevel   LogLevel
 	UnixTime   UnixTime
+	// Nanosecond is the sub-second part of UnixTime
+	Nanosecond int
 	SourceFile SourceFile
 	SourceLine SourceLine
+	// PC is the program counter of the source, zero if the source is disabled
+	PC         uintptr
 	LoggerName LoggerName
 
+	// ContextFields are extracted from context.Context by ContextExtractor
+	ContextFields Fields
+
+	// TraceContext is the span in which the event is logged
+	TraceContext TraceContext
+
 	MetadataFormatter
 	MetadataConfig
 }
@@ -36,6 +47,7 @@ type MetadataConfig struct {
 	IsEnabledSourceFile bool
 	IsEnabledSourceLine bool
 	IsEnabledLoggerName bool
+	IsEnabledTrace      bool
 }
 
 // NewDefaultMetadataConfig
@@ -46,6 +58,7 @@ func NewDefaultMetadataConfig() MetadataConfig {
 		IsEnabledSourceFile: true,
 		IsEnabledSourceLine: true,
 		IsEnabledTime:       true,
+		IsEnabledTrace:      true,
 	}
 }
 
@@ -99,6 +112,45 @@ func (metadata *LogEventMetadata) GetSourceLine() string {
 	return metadata.SourceLineFormatter(metadata.SourceLine)
 }
 
+// GetTraceID returns trace id formatted by TraceIDFormatter
+// if metadata config is disabled or the event is not logged in a span, returns empty string
+func (metadata *LogEventMetadata) GetTraceID() string {
+	if metadata.IsEnabledTrace == false || !metadata.TraceContext.IsValid() {
+		return ""
+	}
+
+	if metadata.TraceIDFormatter == nil {
+		return metadata.TraceContext.TraceID.String()
+	}
+	return metadata.TraceIDFormatter(metadata.TraceContext.TraceID)
+}
+
+// GetSpanID returns span id formatted by SpanIDFormatter
+// if metadata config is disabled or the event is not logged in a span, returns empty string
+func (metadata *LogEventMetadata) GetSpanID() string {
+	if metadata.IsEnabledTrace == false || !metadata.TraceContext.IsValid() {
+		return ""
+	}
+
+	if metadata.SpanIDFormatter == nil {
+		return metadata.TraceContext.SpanID.String()
+	}
+	return metadata.SpanIDFormatter(metadata.TraceContext.SpanID)
+}
+
+// GetTraceFlags returns trace flags formatted by TraceFlagsFormatter
+// if metadata config is disabled or the event is not logged in a span, returns empty string
+func (metadata *LogEventMetadata) GetTraceFlags() string {
+	if metadata.IsEnabledTrace == false || !metadata.TraceContext.IsValid() {
+		return ""
+	}
+
+	if metadata.TraceFlagsFormatter == nil {
+		return metadata.TraceContext.TraceFlags.String()
+	}
+	return metadata.TraceFlagsFormatter(metadata.TraceContext.TraceFlags)
+}
+
 // setLoggerName
 func (metadata *LogEventMetadata) setLoggerName(loggerName string) {
 	if metadata == nil {
@@ -129,7 +181,9 @@ func (metadata *LogEventMetadata) setSource(skip int) {
 
 	if metadata.IsEnabledSourceLine == true || metadata.IsEnabledSourceFile == true {
 
-		_, file, line, _ := runtime.Caller(skip)
+		pc, file, line, _ := runtime.Caller(skip)
+
+		metadata.PC = pc
 
 		metadata.SourceLine = line
 
@@ -138,6 +192,31 @@ func (metadata *LogEventMetadata) setSource(skip int) {
 	}
 }
 
+// setContext sets fields extracted from ctx
+func (metadata *LogEventMetadata) setContext(ctx context.Context, extractors []ContextExtractor) {
+	if metadata == nil {
+		return
+	}
+
+	metadata.ContextFields = extractContextFields(ctx, extractors)
+}
+
+// setTraceContext sets the span active in ctx
+func (metadata *LogEventMetadata) setTraceContext(ctx context.Context, extractor TraceContextExtractor) {
+	if metadata == nil || ctx == nil {
+		return
+	}
+
+	if metadata.IsEnabledTrace == true {
+		if extractor == nil {
+			extractor = TraceContextFromContext
+		}
+		if traceContext, ok := extractor(ctx); ok {
+			metadata.TraceContext = traceContext
+		}
+	}
+}
+
 // setTime
 func (metadata *LogEventMetadata) setTime() {
 	if metadata == nil {
@@ -145,7 +224,52 @@ func (metadata *LogEventMetadata) setTime() {
 	}
 
 	if metadata.IsEnabledTime == true {
-		metadata.UnixTime = time.Now().Unix()
+		now := time.Now()
+		metadata.UnixTime = now.Unix()
+		metadata.Nanosecond = now.Nanosecond()
+	}
+}
+
+// setSourceFromPC sets the source from the program counter of the original caller.
+// If pc is zero, the source is disabled.
+func (metadata *LogEventMetadata) setSourceFromPC(pc uintptr) {
+	if metadata == nil {
+		return
+	}
+
+	if pc == 0 {
+		metadata.IsEnabledSourceFile = false
+		metadata.IsEnabledSourceLine = false
+		return
+	}
+
+	if metadata.IsEnabledSourceLine == true || metadata.IsEnabledSourceFile == true {
+
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+		metadata.PC = pc
+
+		metadata.SourceLine = frame.Line
+
+		metadata.SourceFile = frame.File
+	}
+}
+
+// setTimeAt sets the time given by the original caller.
+// If t is zero, the time is disabled.
+func (metadata *LogEventMetadata) setTimeAt(t time.Time) {
+	if metadata == nil {
+		return
+	}
+
+	if t.IsZero() {
+		metadata.IsEnabledTime = false
+		return
+	}
+
+	if metadata.IsEnabledTime == true {
+		metadata.UnixTime = t.Unix()
+		metadata.Nanosecond = t.Nanosecond()
 	}
 }
 
@@ -3,6 +3,7 @@ package golog
 import (
 	"strconv"
 	"path/filepath"
+	"reflect"
 
 	"time"
 )
@@ -22,6 +23,15 @@ type SourceFileFormatter = func(packageName SourceFile) string
 // LoggerNameFormatter
 type LoggerNameFormatter = func(loggerName LoggerName) string
 
+// TraceIDFormatter
+type TraceIDFormatter = func(traceID TraceID) string
+
+// SpanIDFormatter
+type SpanIDFormatter = func(spanID SpanID) string
+
+// TraceFlagsFormatter
+type TraceFlagsFormatter = func(flags TraceFlags) string
+
 // MetadataFormatter
 type MetadataFormatter struct {
 	LogLevelFormatter   LogLevelFormatter
@@ -29,36 +39,15 @@ type MetadataFormatter struct {
 	SourceFileFormatter SourceFileFormatter
 	SourceLineFormatter SourceLineFormatter
 	LoggerNameFormatter LoggerNameFormatter
+
+	// Trace formatters are optional. If nil, lowercase hex is used.
+	TraceIDFormatter    TraceIDFormatter
+	SpanIDFormatter     SpanIDFormatter
+	TraceFlagsFormatter TraceFlagsFormatter
 }
 
 // NewDefaultMetadataFormatter
 func NewDefaultMetadataFormatter() MetadataFormatter {
-	// DefaultLogLevelFormatter
-	var defaultLogLevelFormatter = func(logLevel LogLevel) string {
-		return logLevel.String()
-	}
-
-	// DefaultTimeFormatter
-	var defaultTimeFormatter = func(unixTime UnixTime) string {
-		return time.Unix(unixTime, 0).Format(time.RFC3339)
-	}
-
-	// DefaultLineFormatter
-	var defaultSourceLineFormatter = func(sourceLine SourceLine) string {
-		return strconv.FormatInt(int64(sourceLine), 10)
-	}
-
-	// DefaultSourceFileFormatter
-	var defaultSourceFileFormatter = func(sourceFile SourceFile) string {
-		_, fileName := filepath.Split(sourceFile)
-		return fileName
-	}
-
-	// DefaultLoggerNameFormatter
-	var defaultLoggerNameFormatter = func(loggerName LoggerName) string {
-		return loggerName
-	}
-
 	return  MetadataFormatter{
 		LogLevelFormatter:   defaultLogLevelFormatter,
 		TimeFormatter:       defaultTimeFormatter,
@@ -68,3 +57,34 @@ func NewDefaultMetadataFormatter() MetadataFormatter {
 	}
 }
 
+// defaultLogLevelFormatter
+func defaultLogLevelFormatter(logLevel LogLevel) string {
+	return logLevel.String()
+}
+
+// defaultTimeFormatter
+func defaultTimeFormatter(unixTime UnixTime) string {
+	return time.Unix(unixTime, 0).Format(time.RFC3339)
+}
+
+// defaultSourceLineFormatter
+func defaultSourceLineFormatter(sourceLine SourceLine) string {
+	return strconv.FormatInt(int64(sourceLine), 10)
+}
+
+// defaultSourceFileFormatter
+func defaultSourceFileFormatter(sourceFile SourceFile) string {
+	_, fileName := filepath.Split(sourceFile)
+	return fileName
+}
+
+// defaultLoggerNameFormatter
+func defaultLoggerNameFormatter(loggerName LoggerName) string {
+	return loggerName
+}
+
+// isFormatter reports whether formatter is the function f.
+// Encoders use it to append the default formats without allocating the formatted strings.
+func isFormatter(formatter interface{}, f interface{}) bool {
+	return reflect.ValueOf(formatter).Pointer() == reflect.ValueOf(f).Pointer()
+}
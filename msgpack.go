@@ -0,0 +1,276 @@
+package golog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// msgpack implements the subset of MessagePack needed by the appenders.
+// Encoding is append-style so that callers can reuse their buffers.
+
+// msgpackExtEventTime is the fluentd EventTime extension type
+const msgpackExtEventTime = 0
+
+// appendMsgpackArrayHeader
+func appendMsgpackArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, 0xdc, byte(n>>8), byte(n))
+	default:
+		return append(buf, 0xdd, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// appendMsgpackMapHeader
+func appendMsgpackMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, 0xde, byte(n>>8), byte(n))
+	default:
+		return append(buf, 0xdf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// appendMsgpackStringHeader
+func appendMsgpackStringHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 32:
+		return append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, 0xda, byte(n>>8), byte(n))
+	default:
+		return append(buf, 0xdb, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// appendMsgpackString
+func appendMsgpackString(buf []byte, s string) []byte {
+	return append(appendMsgpackStringHeader(buf, len(s)), s...)
+}
+
+// appendMsgpackStringBytes encodes b as a msgpack str
+func appendMsgpackStringBytes(buf []byte, b []byte) []byte {
+	return append(appendMsgpackStringHeader(buf, len(b)), b...)
+}
+
+// appendMsgpackBinHeader
+func appendMsgpackBinHeader(buf []byte, n int) []byte {
+	switch {
+	case n <= math.MaxUint8:
+		return append(buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, 0xc5, byte(n>>8), byte(n))
+	default:
+		return append(buf, 0xc6, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// appendMsgpackInt
+func appendMsgpackInt(buf []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v < 128:
+		return append(buf, byte(v))
+	case v < 0 && v >= -32:
+		return append(buf, byte(v))
+	default:
+		buf = append(buf, 0xd3)
+		return binary.BigEndian.AppendUint64(buf, uint64(v))
+	}
+}
+
+// appendMsgpackEventTime encodes t as fluentd EventTime (ext type 0)
+func appendMsgpackEventTime(buf []byte, t time.Time) []byte {
+	buf = append(buf, 0xd7, msgpackExtEventTime)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(buf, uint32(t.Nanosecond()))
+}
+
+// errMsgpackUnsupported
+var errMsgpackUnsupported = errors.New("golog: unsupported msgpack type")
+
+// msgpackExt is a decoded extension value
+type msgpackExt struct {
+	Type int8
+	Data []byte
+}
+
+// decodeMsgpack reads one value from reader.
+// Maps are decoded as map[string]interface{}, arrays as []interface{},
+// integers as int64, str as string and bin as []byte.
+func decodeMsgpack(reader *bufio.Reader) (interface{}, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return decodeMsgpackMap(reader, int(b&0x0f))
+	case b&0xf0 == 0x90:
+		return decodeMsgpackArray(reader, int(b&0x0f))
+	case b&0xe0 == 0xa0:
+		return decodeMsgpackRaw(reader, int(b&0x1f), true)
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readMsgpackLength(reader, b-0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackRaw(reader, n, false)
+	case 0xd9, 0xda, 0xdb:
+		n, err := readMsgpackLength(reader, b-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackRaw(reader, n, true)
+	case 0xdc, 0xdd:
+		n, err := readMsgpackLength(reader, b-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackArray(reader, n)
+	case 0xde, 0xdf:
+		n, err := readMsgpackLength(reader, b-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackMap(reader, n)
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		size := 1 << (b - 0xcc)
+		raw, err := readMsgpackN(reader, size)
+		if err != nil {
+			return nil, err
+		}
+		var v uint64
+		for _, c := range raw {
+			v = v<<8 | uint64(c)
+		}
+		return int64(v), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		raw, err := readMsgpackN(reader, size)
+		if err != nil {
+			return nil, err
+		}
+		var v uint64
+		for _, c := range raw {
+			v = v<<8 | uint64(c)
+		}
+		shift := uint(64 - 8*size)
+		return int64(v<<shift) >> shift, nil
+	case 0xcb:
+		raw, err := readMsgpackN(reader, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return decodeMsgpackExt(reader, 1<<(b-0xd4))
+	}
+
+	return nil, fmt.Errorf("%w: 0x%x", errMsgpackUnsupported, b)
+}
+
+// readMsgpackLength reads a big endian length of 1, 2 or 4 bytes (sizeClass 0, 1, 2)
+func readMsgpackLength(reader *bufio.Reader, sizeClass byte) (int, error) {
+	raw, err := readMsgpackN(reader, 1<<sizeClass)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, c := range raw {
+		n = n<<8 | int(c)
+	}
+	return n, nil
+}
+
+// readMsgpackN
+func readMsgpackN(reader *bufio.Reader, n int) ([]byte, error) {
+	raw := make([]byte, n)
+	if _, err := io.ReadFull(reader, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// decodeMsgpackRaw
+func decodeMsgpackRaw(reader *bufio.Reader, n int, isString bool) (interface{}, error) {
+	raw, err := readMsgpackN(reader, n)
+	if err != nil {
+		return nil, err
+	}
+	if isString {
+		return string(raw), nil
+	}
+	return raw, nil
+}
+
+// decodeMsgpackExt
+func decodeMsgpackExt(reader *bufio.Reader, n int) (interface{}, error) {
+	typ, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := readMsgpackN(reader, n)
+	if err != nil {
+		return nil, err
+	}
+	return msgpackExt{Type: int8(typ), Data: raw}, nil
+}
+
+// decodeMsgpackArray
+func decodeMsgpackArray(reader *bufio.Reader, n int) (interface{}, error) {
+	array := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := decodeMsgpack(reader)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, v)
+	}
+	return array, nil
+}
+
+// decodeMsgpackMap
+func decodeMsgpackMap(reader *bufio.Reader, n int) (interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := decodeMsgpack(reader)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: non string map key", errMsgpackUnsupported)
+		}
+		v, err := decodeMsgpack(reader)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
@@ -0,0 +1,111 @@
+package golog
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Minimal protocol buffers encoder used by the exporters.
+// Messages are appended to a byte slice, field by field.
+
+// protobuf wire types
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+// appendProtoVarint
+func appendProtoVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// appendProtoTag
+func appendProtoTag(b []byte, field int, wireType int) []byte {
+	return appendProtoVarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+// appendProtoUint64Field appends a varint field. Zero is omitted as proto3 does.
+func appendProtoUint64Field(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, protoWireVarint)
+	return appendProtoVarint(b, v)
+}
+
+// appendProtoInt64Field appends an int64 varint field. Zero is omitted.
+func appendProtoInt64Field(b []byte, field int, v int64) []byte {
+	return appendProtoUint64Field(b, field, uint64(v))
+}
+
+// appendProtoFixed64Field appends a fixed64 field. Zero is omitted.
+func appendProtoFixed64Field(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, protoWireFixed64)
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+// appendProtoDouble appends the value of a double field
+func appendProtoDouble(b []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+// appendProtoFixed32Field appends a fixed32 field. Zero is omitted.
+func appendProtoFixed32Field(b []byte, field int, v uint32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, protoWireFixed32)
+	return binary.LittleEndian.AppendUint32(b, v)
+}
+
+// appendProtoStringField appends a string field. Empty string is omitted.
+func appendProtoStringField(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = appendProtoTag(b, field, protoWireBytes)
+	b = appendProtoVarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// appendProtoBytesField appends a bytes field. Empty bytes are omitted.
+func appendProtoBytesField(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, protoWireBytes)
+	b = appendProtoVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendProtoMessageField appends an embedded message encoded by fn.
+// The message is always appended, even if it is empty.
+func appendProtoMessageField(b []byte, field int, fn func(b []byte) []byte) []byte {
+	b = appendProtoTag(b, field, protoWireBytes)
+
+	// reserve the length assuming it fits in 1 byte, and move the message if it does not
+	start := len(b)
+	b = append(b, 0)
+	b = fn(b)
+	length := len(b) - start - 1
+	if length < 0x80 {
+		b[start] = byte(length)
+		return b
+	}
+
+	var header [binary.MaxVarintLen64]byte
+	n := len(appendProtoVarint(header[:0], uint64(length)))
+	b = append(b, header[:n-1]...)
+	copy(b[start+n:], b[start+1:start+1+length])
+	copy(b[start:], header[:n])
+	return b
+}
@@ -0,0 +1,81 @@
+package golog
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// protoMessage is a decoded message. Values are uint64 for varint and fixed64,
+// uint32 for fixed32 and []byte for length delimited fields.
+type protoMessage map[int][]interface{}
+
+// decodeProto decodes a message without schema
+func decodeProto(t *testing.T, b []byte) protoMessage {
+	message := protoMessage{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("invalid tag")
+		}
+		b = b[n:]
+		field := int(tag >> 3)
+
+		switch tag & 7 {
+		case protoWireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				t.Fatalf("invalid varint")
+			}
+			message[field] = append(message[field], v)
+			b = b[n:]
+		case protoWireFixed64:
+			message[field] = append(message[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case protoWireFixed32:
+			message[field] = append(message[field], binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case protoWireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || int(length) > len(b)-n {
+				t.Fatalf("invalid length")
+			}
+			message[field] = append(message[field], b[n:n+int(length)])
+			b = b[n+int(length):]
+		default:
+			t.Fatalf("unknown wire type %d", tag&7)
+		}
+	}
+	return message
+}
+
+// message decodes the embedded message of field
+func (message protoMessage) message(t *testing.T, field int, index int) protoMessage {
+	return decodeProto(t, message[field][index].([]byte))
+}
+
+// string returns the string of field
+func (message protoMessage) string(field int) string {
+	if len(message[field]) == 0 {
+		return ""
+	}
+	return string(message[field][0].([]byte))
+}
+
+func TestAppendProtoMessageField(t *testing.T) {
+	for _, length := range []int{0, 1, 127, 128, 300, 20000} {
+		value := strings.Repeat("a", length)
+		encoded := appendProtoMessageField([]byte{0xff}, 1, func(b []byte) []byte {
+			b = appendProtoUint64Field(b, 1, 150)
+			return appendProtoStringField(b, 2, value)
+		})
+
+		assert.Equal(t, byte(0xff), encoded[0])
+		message := decodeProto(t, encoded[1:])
+		embedded := message.message(t, 1, 0)
+		assert.Equal(t, uint64(150), embedded[1][0])
+		assert.Equal(t, value, embedded.string(2))
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package golog
+
+func init() {
+	raceEnabled = true
+}
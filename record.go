@@ -0,0 +1,44 @@
+package golog
+
+// Record is the structured event passed from Logger to appenders.
+// It is built once per event and shared by all appenders, so that each appender can encode it by its own Encoder.
+type Record struct {
+	Level LogLevel
+
+	// Message is the message of Event. The object of JsonLogEvent is encoded as JSON.
+	Message string
+
+	// Fields are the fields of Event, including the fields given by Logger.With
+	Fields Fields
+
+	// Metadata is nil if metadata is disabled
+	Metadata *LogEventMetadata
+
+	// Event is the original event
+	Event LogEvent
+}
+
+// NewRecord returns new Record of event
+func NewRecord(level LogLevel, metadata *LogEventMetadata, event LogEvent) Record {
+	message, fields := eventMessage(event)
+	return Record{
+		Level:    level,
+		Message:  message,
+		Fields:   fields,
+		Metadata: metadata,
+		Event:    event,
+	}
+}
+
+// RecordWriter is an optional interface of Appender.
+// If an appender implements it, the logger calls WriteRecord instead of WriteEvent and Write.
+// The appender must not retain record, its metadata and its event after WriteRecord returns.
+type RecordWriter interface {
+	WriteRecord(record *Record) error
+}
+
+// recordEncoder is implemented by appenders which encode records by themselves, e.g. EncoderAppender.
+// Appenders wrapping them, e.g. AsyncAppender, encode records by them before queueing.
+type recordEncoder interface {
+	encodeRecord(record *Record) []byte
+}
@@ -0,0 +1,84 @@
+package golog
+
+import (
+	"strings"
+	"sync"
+)
+
+// RootLoggerName is the name of the root of the logger hierarchy
+const RootLoggerName = "root"
+
+// loggerRegistry holds named loggers.
+//
+// Dotted names form a hierarchy : "app" is the parent of "app.db",
+// which is the parent of "app.db.pool". The root logger is the parent of top level names.
+type loggerRegistry struct {
+	mu      sync.Mutex
+	loggers map[string]*Logger
+	root    *Logger
+}
+
+// registry is the default registry used by GetLogger
+var registry = newLoggerRegistry()
+
+// newLoggerRegistry returns a registry whose root logger writes all levels to stdout
+func newLoggerRegistry() *loggerRegistry {
+	root := NewDefaultLogger()
+	root.Name = RootLoggerName
+
+	return &loggerRegistry{
+		loggers: map[string]*Logger{},
+		root:    &root,
+	}
+}
+
+// getLogger returns the logger of name, creating it and its ancestors if necessary
+func (registry *loggerRegistry) getLogger(name string) *Logger {
+	name = strings.Trim(name, ".")
+	if name == "" || name == RootLoggerName {
+		return registry.root
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	return registry.getOrCreate(name)
+}
+
+// getOrCreate. The caller must hold mu.
+func (registry *loggerRegistry) getOrCreate(name string) *Logger {
+	if logger, ok := registry.loggers[name]; ok {
+		return logger
+	}
+
+	parent := registry.root
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		parent = registry.getOrCreate(name[:i])
+	}
+
+	logger := &Logger{
+		Name:   name,
+		config: newInheritedConfig(parent.config),
+	}
+	registry.loggers[name] = logger
+	return logger
+}
+
+// GetLogger returns the named logger from the hierarchy of loggers.
+//
+// The same name always returns the same *Logger. A logger inherits appenders,
+// metadata settings, context extractors and level from the nearest ancestor which has configured each of them,
+// and reconfiguring a logger at runtime affects all its descendants which inherit from it.
+// SetAppender stops inheriting appenders, SetMetadataConfig, SetMetadataFormatter and DisableLogEventMetadata
+// stop inheriting metadata settings, SetContextExtractor and SetTraceContextExtractor stop inheriting
+// context extractors, and SetLevel stops inheriting the level. SetAppenderWithLevel overrides only its levels.
+// An empty name or "root" returns the root logger.
+func GetLogger(name string) *Logger {
+	return registry.getLogger(name)
+}
+
+// RootLogger returns the root of the hierarchy of loggers.
+// By default, it writes all levels to stdout.
+func RootLogger() *Logger {
+	return registry.root
+}
@@ -0,0 +1,144 @@
+package golog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLogger(t *testing.T) {
+
+	t.Run("returns the same logger for the same name", func(t *testing.T) {
+		assert.Same(t, GetLogger("app.db.pool"), GetLogger("app.db.pool"))
+		assert.Equal(t, "app.db.pool", GetLogger("app.db.pool").Name)
+	})
+
+	t.Run("returns root logger for empty name", func(t *testing.T) {
+		assert.Same(t, RootLogger(), GetLogger(""))
+		assert.Same(t, RootLogger(), GetLogger(RootLoggerName))
+	})
+}
+
+func TestLoggerRegistry_Inheritance(t *testing.T) {
+
+	t.Run("children inherit appenders from the nearest configured ancestor", func(t *testing.T) {
+		registry := newLoggerRegistry()
+		rootAppender := NewByteBufferAppender()
+		dbAppender := NewByteBufferAppender()
+		registry.root.SetAppender(rootAppender)
+		registry.root.DisableLogEventMetadata()
+
+		pool := registry.getLogger("app.db.pool")
+		web := registry.getLogger("app.web")
+		registry.getLogger("app.db").SetAppender(dbAppender)
+
+		pool.Info("pool")
+		web.Info("web")
+
+		assert.Equal(t, "pool\n", dbAppender.String())
+		assert.Equal(t, "web\n", rootAppender.String())
+	})
+
+	t.Run("children inherit level and follow changes of ancestors", func(t *testing.T) {
+		registry := newLoggerRegistry()
+		pool := registry.getLogger("app.db.pool")
+		db := registry.getLogger("app.db")
+
+		assert.Equal(t, LogLevel_TRACE, pool.Level())
+
+		db.SetLevel(LogLevel_WARN)
+		assert.Equal(t, LogLevel_WARN, pool.Level())
+		assert.Equal(t, LogLevel_TRACE, registry.getLogger("app").Level())
+
+		pool.SetLevel(LogLevel_DEBUG)
+		db.SetLevel(LogLevel_ERROR)
+		assert.Equal(t, LogLevel_DEBUG, pool.Level())
+	})
+
+	t.Run("children inherit metadata config", func(t *testing.T) {
+		registry := newLoggerRegistry()
+		appender := NewByteBufferAppender()
+		app := registry.getLogger("app")
+		app.SetAppender(appender)
+		app.SetMetadataConfig(&MetadataConfig{IsEnabledLoggerName: true})
+
+		registry.getLogger("app.db").Info("message")
+
+		assert.Equal(t, "  app.db () message\n", appender.String())
+	})
+
+	t.Run("derived logger follows the registered logger", func(t *testing.T) {
+		registry := newLoggerRegistry()
+		appender := NewByteBufferAppender()
+		db := registry.getLogger("app.db")
+		derived := db.With("key", "value")
+
+		registry.getLogger("app").SetAppender(appender)
+		registry.getLogger("app").DisableLogEventMetadata()
+		derived.Info("message")
+
+		assert.Equal(t, "message key=value\n", appender.String())
+	})
+
+	t.Run("child sets metadata, parent changes appenders", func(t *testing.T) {
+		registry := newLoggerRegistry()
+		oldAppender := NewByteBufferAppender()
+		newAppender := NewByteBufferAppender()
+		registry.root.SetAppender(oldAppender)
+
+		child := registry.getLogger("zz.child")
+		child.SetMetadataConfig(nil)
+		child.DisableLogEventMetadata()
+		registry.root.SetAppender(newAppender)
+		child.Info("message")
+
+		assert.Equal(t, "", oldAppender.String())
+		assert.Equal(t, "message\n", newAppender.String())
+	})
+
+	t.Run("child sets the appenders of a level and inherits the others", func(t *testing.T) {
+		registry := newLoggerRegistry()
+		rootAppender := NewByteBufferAppender()
+		debugAppender := NewByteBufferAppender()
+		registry.root.DisableLogEventMetadata()
+		registry.root.SetLevel(LogLevel_INFO)
+
+		child := registry.getLogger("app")
+		child.SetAppenderWithLevel(LogLevel_DEBUG, debugAppender)
+		registry.root.SetAppender(rootAppender)
+		child.Debug("debug")
+		child.Info("info")
+
+		assert.Equal(t, "debug\n", debugAppender.String())
+		assert.Equal(t, "info\n", rootAppender.String())
+	})
+
+	t.Run("close does not close the appenders of the ancestors", func(t *testing.T) {
+		registry := newLoggerRegistry()
+		rootAppender := &closeCountAppender{}
+		childAppender := &closeCountAppender{}
+		registry.root.SetAppender(rootAppender)
+
+		assert.Nil(t, registry.getLogger("a.b").Close())
+		assert.Equal(t, 0, rootAppender.closed)
+
+		registry.getLogger("a").SetAppender(childAppender)
+		assert.Nil(t, registry.getLogger("a").Close())
+		assert.Equal(t, 1, childAppender.closed)
+		assert.Equal(t, 0, rootAppender.closed)
+	})
+}
+
+// closeCountAppender counts Close calls
+type closeCountAppender struct {
+	closed int
+}
+
+func (appender *closeCountAppender) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (appender *closeCountAppender) Close() error {
+	appender.closed++
+	return nil
+}
@@ -0,0 +1,129 @@
+package golog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler is a slog.Handler which writes records through a golog Logger.
+//
+// slog levels are mapped to LogLevel, and attributes are converted to Fields.
+// Attributes in groups are flattened with dotted keys, e.g. "request.id".
+type SlogHandler struct {
+	logger *Logger
+	fields Fields
+	prefix string
+}
+
+// NewSlogHandler returns new SlogHandler backed by logger
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+	}
+}
+
+// Enabled implements slog.Handler
+func (handler *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return handler.logger.IsEnabled(logLevelFromSlog(level))
+}
+
+// Handle implements slog.Handler
+func (handler *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(Fields, 0, len(handler.logger.fields)+len(handler.fields)+record.NumAttrs())
+	fields = append(fields, handler.logger.fields...)
+	fields = append(fields, handler.fields...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendSlogAttr(fields, handler.prefix, attr)
+		return true
+	})
+
+	event := TextLogEvent{Event: record.Message, Fields: fields}
+	handler.logger.appendEventAt(ctx, logLevelFromSlog(record.Level), event, record.PC, record.Time)
+	return nil
+}
+
+// WithAttrs implements slog.Handler
+func (handler *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return handler
+	}
+
+	fields := make(Fields, 0, len(handler.fields)+len(attrs))
+	fields = append(fields, handler.fields...)
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, handler.prefix, attr)
+	}
+
+	derived := *handler
+	derived.fields = fields
+	return &derived
+}
+
+// WithGroup implements slog.Handler
+func (handler *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+
+	derived := *handler
+	derived.prefix = handler.prefix + name + "."
+	return &derived
+}
+
+// appendSlogAttr appends attr to fields, flattening groups
+func appendSlogAttr(fields Fields, prefix string, attr slog.Attr) Fields {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			fields = appendSlogAttr(fields, prefix, member)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: prefix + attr.Key, Value: attr.Value.Any()})
+}
+
+// logLevelFromSlog maps slog.Level to LogLevel.
+// Levels between the standard slog levels are rounded down.
+func logLevelFromSlog(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return LogLevel_TRACE
+	case level < slog.LevelInfo:
+		return LogLevel_DEBUG
+	case level < slog.LevelWarn:
+		return LogLevel_INFO
+	case level < slog.LevelError:
+		return LogLevel_WARN
+	case level < slog.LevelError+4:
+		return LogLevel_ERROR
+	default:
+		return LogLevel_FATAL
+	}
+}
+
+// slogLevelFromLogLevel maps LogLevel to slog.Level
+func slogLevelFromLogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevel_TRACE:
+		return slog.LevelDebug - 4
+	case LogLevel_DEBUG:
+		return slog.LevelDebug
+	case LogLevel_INFO:
+		return slog.LevelInfo
+	case LogLevel_WARN:
+		return slog.LevelWarn
+	case LogLevel_ERROR:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
@@ -0,0 +1,116 @@
+package golog
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// parseTextLine parses a line encoded by TextLogEvent into the map expected by slogtest.
+// Messages must not contain spaces.
+func parseTextLine(t *testing.T, line string) map[string]any {
+	parts := strings.SplitN(line, " ", 5)
+	if len(parts) != 5 {
+		t.Fatalf("unexpected line %q", line)
+	}
+
+	result := map[string]any{
+		slog.LevelKey: parts[0],
+	}
+	if parts[1] != "" {
+		result[slog.TimeKey] = parts[1]
+	}
+	if parts[3] != "()" {
+		result[slog.SourceKey] = parts[3]
+	}
+
+	rest := parts[4]
+	message, rest, _ := strings.Cut(rest, " ")
+	result[slog.MessageKey] = message
+
+	for rest != "" {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			t.Fatalf("unexpected fields %q", rest)
+		}
+		if strings.HasPrefix(value, `"`) {
+			quoted, err := strconv.QuotedPrefix(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rest = strings.TrimPrefix(value[len(quoted):], " ")
+			value, _ = strconv.Unquote(quoted)
+		} else {
+			value, rest, _ = strings.Cut(value, " ")
+		}
+
+		// dotted keys are nested groups
+		group := result
+		names := strings.Split(key, ".")
+		for _, name := range names[:len(names)-1] {
+			if _, ok := group[name]; !ok {
+				group[name] = map[string]any{}
+			}
+			group = group[name].(map[string]any)
+		}
+		group[names[len(names)-1]] = value
+	}
+	return result
+}
+
+func TestSlogHandler(t *testing.T) {
+
+	t.Run("slogtest", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("slogtest", LogLevel_TRACE, appender)
+
+		err := slogtest.TestHandler(NewSlogHandler(&logger), func() []map[string]any {
+			var results []map[string]any
+			for _, line := range strings.Split(strings.TrimSuffix(appender.String(), "\n"), "\n") {
+				results = append(results, parseTextLine(t, line))
+			}
+			return results
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("levels are mapped and filtered by the logger", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_INFO, appender)
+		logger.DisableLogEventMetadata()
+		slogger := slog.New(NewSlogHandler(&logger))
+
+		slogger.Debug("debug")
+		slogger.Info("info", "user", "u1")
+		slogger.With("requestID", "abc").WithGroup("db").Warn("warn", "table", "users")
+
+		assert.Equal(t, "info user=u1\nwarn requestID=abc db.table=users\n", appender.String())
+	})
+
+	t.Run("source points to the caller of slog", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.SetMetadataConfig(&MetadataConfig{IsEnabledSourceFile: true, IsEnabledSourceLine: true})
+
+		slog.New(NewSlogHandler(&logger)).Info("message")
+
+		assert.Contains(t, appender.String(), "slog_handler_test.go(")
+	})
+}
+
+func TestLogLevelFromSlog(t *testing.T) {
+	assert.Equal(t, LogLevel_TRACE, logLevelFromSlog(slog.LevelDebug-1))
+	assert.Equal(t, LogLevel_DEBUG, logLevelFromSlog(slog.LevelDebug))
+	assert.Equal(t, LogLevel_INFO, logLevelFromSlog(slog.LevelInfo))
+	assert.Equal(t, LogLevel_WARN, logLevelFromSlog(slog.LevelWarn))
+	assert.Equal(t, LogLevel_ERROR, logLevelFromSlog(slog.LevelError))
+	assert.Equal(t, LogLevel_FATAL, logLevelFromSlog(slog.LevelError+4))
+
+	for _, level := range []LogLevel{LogLevel_TRACE, LogLevel_DEBUG, LogLevel_INFO, LogLevel_WARN, LogLevel_ERROR, LogLevel_FATAL} {
+		assert.Equal(t, level, logLevelFromSlog(slogLevelFromLogLevel(level)))
+	}
+}
@@ -0,0 +1,87 @@
+package golog
+
+import "encoding/binary"
+
+// Minimal snappy block encoder used by the Loki appender.
+// It finds matches of at least 4 bytes by a hash table and emits them as 2 byte offset copies.
+
+// snappy tags
+const (
+	snappyTagLiteral = 0x00
+	snappyTagCopy2   = 0x02
+)
+
+// snappy encoder parameters
+const (
+	snappyTableBits  = 14
+	snappyMaxOffset  = 1<<16 - 1
+	snappyMaxCopyLen = 64
+)
+
+// encodeSnappy encodes src by the snappy block format
+func encodeSnappy(src []byte) []byte {
+	dst := make([]byte, 0, binary.MaxVarintLen32+len(src)+len(src)/6)
+	dst = binary.AppendUvarint(dst, uint64(len(src)))
+
+	// table holds the position + 1 of the last 4 bytes with the hash, zero means empty
+	var table [1 << snappyTableBits]int32
+	literal := 0
+	for i := 0; i+4 <= len(src); {
+		current := binary.LittleEndian.Uint32(src[i:])
+		hash := (current * 0x1e35a7bd) >> (32 - snappyTableBits)
+		candidate := int(table[hash]) - 1
+		table[hash] = int32(i + 1)
+
+		if candidate < 0 || i-candidate > snappyMaxOffset || binary.LittleEndian.Uint32(src[candidate:]) != current {
+			i++
+			continue
+		}
+
+		length := 4
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length++
+		}
+
+		dst = appendSnappyLiteral(dst, src[literal:i])
+		dst = appendSnappyCopy(dst, i-candidate, length)
+		i += length
+		literal = i
+	}
+
+	return appendSnappyLiteral(dst, src[literal:])
+}
+
+// appendSnappyLiteral
+func appendSnappyLiteral(dst []byte, literal []byte) []byte {
+	if len(literal) == 0 {
+		return dst
+	}
+
+	n := uint32(len(literal) - 1)
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2|snappyTagLiteral)
+	case n < 1<<8:
+		dst = append(dst, 60<<2|snappyTagLiteral, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2|snappyTagLiteral, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2|snappyTagLiteral, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2|snappyTagLiteral, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, literal...)
+}
+
+// appendSnappyCopy appends copies of at most 64 bytes
+func appendSnappyCopy(dst []byte, offset int, length int) []byte {
+	for length > 0 {
+		n := length
+		if n > snappyMaxCopyLen {
+			n = snappyMaxCopyLen
+		}
+		dst = append(dst, byte(n-1)<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+	return dst
+}
@@ -0,0 +1,114 @@
+package golog
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeSnappy decodes the snappy block format
+func decodeSnappy(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 {
+		return nil, errors.New("invalid length")
+	}
+	src = src[n:]
+
+	dst := make([]byte, 0, length)
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 3 {
+		case 0:
+			literal := int(tag >> 2)
+			src = src[1:]
+			if literal >= 60 {
+				size := literal - 59
+				if len(src) < size {
+					return nil, errors.New("invalid literal")
+				}
+				literal = 0
+				for i := size - 1; i >= 0; i-- {
+					literal = literal<<8 | int(src[i])
+				}
+				src = src[size:]
+			}
+			literal++
+			if len(src) < literal {
+				return nil, errors.New("invalid literal")
+			}
+			dst = append(dst, src[:literal]...)
+			src = src[literal:]
+		case 1:
+			if len(src) < 2 {
+				return nil, errors.New("invalid copy")
+			}
+			copyLength := int(tag>>2&7) + 4
+			offset := int(tag>>5)<<8 | int(src[1])
+			src = src[2:]
+			if err := snappyCopy(&dst, offset, copyLength); err != nil {
+				return nil, err
+			}
+		case 2:
+			if len(src) < 3 {
+				return nil, errors.New("invalid copy")
+			}
+			copyLength := int(tag>>2) + 1
+			offset := int(binary.LittleEndian.Uint16(src[1:]))
+			src = src[3:]
+			if err := snappyCopy(&dst, offset, copyLength); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("unsupported copy")
+		}
+	}
+
+	if uint64(len(dst)) != length {
+		return nil, errors.New("length mismatch")
+	}
+	return dst, nil
+}
+
+// snappyCopy
+func snappyCopy(dst *[]byte, offset int, length int) error {
+	if offset <= 0 || offset > len(*dst) {
+		return errors.New("invalid offset")
+	}
+	for i := 0; i < length; i++ {
+		*dst = append(*dst, (*dst)[len(*dst)-offset])
+	}
+	return nil
+}
+
+func TestEncodeSnappy(t *testing.T) {
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	for _, tt := range []struct {
+		name string
+		src  []byte
+	}{
+		{"empty", nil},
+		{"short", []byte("abc")},
+		{"repeated", []byte(strings.Repeat("golog ", 1000))},
+		{"log lines", []byte(strings.Repeat(`{"level":"info","message":"hello"}`+"\n", 300))},
+		{"long literal", random},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encodeSnappy(tt.src)
+			decoded, err := decodeSnappy(encoded)
+			assert.Nil(t, err)
+			assert.Equal(t, len(tt.src), len(decoded))
+			assert.True(t, string(tt.src) == string(decoded))
+		})
+	}
+
+	t.Run("compressed", func(t *testing.T) {
+		src := []byte(strings.Repeat("golog ", 1000))
+		assert.Less(t, len(encodeSnappy(src)), len(src)/10)
+	})
+}
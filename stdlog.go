@@ -0,0 +1,129 @@
+package golog
+
+import (
+	"log"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// stdLogWriter is an io.Writer used as the output of a *log.Logger.
+// It strips the header written by the log package and appends the message to a Logger.
+type stdLogWriter struct {
+	logger *Logger
+	level  LogLevel
+
+	// source is the *log.Logger writing to this writer, whose prefix and flags are stripped
+	source *log.Logger
+}
+
+// StdLogOption is an option of NewStdLogger and RedirectStdLog
+type StdLogOption func(*stdLogWriter)
+
+// WithStdLogLevel sets the level of messages written by the log package.
+// The default level is LogLevel_INFO.
+func WithStdLogLevel(level LogLevel) StdLogOption {
+	return func(writer *stdLogWriter) {
+		writer.level = level
+	}
+}
+
+// newStdLogWriter
+func newStdLogWriter(logger *Logger, source *log.Logger, options ...StdLogOption) *stdLogWriter {
+	writer := &stdLogWriter{
+		logger: logger,
+		level:  LogLevel_INFO,
+		source: source,
+	}
+	for _, option := range options {
+		option(writer)
+	}
+	return writer
+}
+
+// NewStdLogger returns a *log.Logger which writes to logger.
+// It can be passed to APIs which accept a *log.Logger, e.g. http.Server.ErrorLog.
+func NewStdLogger(logger *Logger, options ...StdLogOption) *log.Logger {
+	stdLogger := log.New(nil, "", 0)
+	stdLogger.SetOutput(newStdLogWriter(logger, stdLogger, options...))
+	return stdLogger
+}
+
+// RedirectStdLog sets logger as the output of the standard logger of the log package,
+// so that log.Printf etc. are written to the appenders of logger.
+// The prefix and the flags of the standard logger are stripped from messages.
+// It returns a function which restores the previous output.
+func RedirectStdLog(logger *Logger, options ...StdLogOption) func() {
+	previous := log.Writer()
+	log.SetOutput(newStdLogWriter(logger, log.Default(), options...))
+	return func() {
+		log.SetOutput(previous)
+	}
+}
+
+// Write implements io.Writer
+func (writer *stdLogWriter) Write(data []byte) (n int, err error) {
+	if !writer.logger.IsEnabled(writer.level) {
+		return len(data), nil
+	}
+
+	message := strings.TrimSuffix(string(data), "\n")
+	message = stripStdLogHeader(message, writer.source.Prefix(), writer.source.Flags())
+
+	event := TextLogEvent{Event: message, Fields: writer.logger.fields}
+	writer.logger.appendEventAt(writer.logger.ctx, writer.level, event, stdLogCallerPC(), time.Now())
+	return len(data), nil
+}
+
+// stdLogCallerPC returns the program counter of the caller of the log package
+func stdLogCallerPC() uintptr {
+	var pcs [16]uintptr
+	// skip runtime.Callers, stdLogCallerPC and stdLogWriter.Write
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			return pc
+		}
+	}
+	return 0
+}
+
+// stripStdLogHeader removes the prefix, the date, the time and the file written by the log package
+func stripStdLogHeader(message string, prefix string, flags int) string {
+	if flags&log.Lmsgprefix == 0 {
+		message = strings.TrimPrefix(message, prefix)
+	}
+
+	if flags&log.Ldate != 0 {
+		message = cutStdLogHeader(message, len("2006/01/02 "))
+	}
+
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		if flags&log.Lmicroseconds != 0 {
+			message = cutStdLogHeader(message, len("15:04:05.000000 "))
+		} else {
+			message = cutStdLogHeader(message, len("15:04:05 "))
+		}
+	}
+
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if _, after, ok := strings.Cut(message, ": "); ok {
+			message = after
+		}
+	}
+
+	if flags&log.Lmsgprefix != 0 {
+		message = strings.TrimPrefix(message, prefix)
+	}
+
+	return message
+}
+
+// cutStdLogHeader removes the first n bytes of message if it is long enough
+func cutStdLogHeader(message string, n int) string {
+	if len(message) < n {
+		return message
+	}
+	return message[n:]
+}
@@ -0,0 +1,88 @@
+package golog
+
+import (
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStdLogger(t *testing.T) {
+
+	t.Run("messages are written without the std header", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.DisableLogEventMetadata()
+
+		stdLogger := NewStdLogger(&logger, WithStdLogLevel(LogLevel_ERROR))
+		stdLogger.SetPrefix("[lib] ")
+		stdLogger.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+		stdLogger.Printf("failed %d", 1)
+
+		assert.Equal(t, "failed 1\n", appender.String())
+	})
+
+	t.Run("source points to the caller of the log package", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.SetMetadataConfig(&MetadataConfig{IsEnabledSourceFile: true, IsEnabledSourceLine: true})
+
+		NewStdLogger(&logger).Print("message")
+
+		assert.Contains(t, appender.String(), "stdlog_test.go(32) message")
+	})
+
+	t.Run("disabled level is not written", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_WARN, appender)
+
+		NewStdLogger(&logger).Print("message")
+
+		assert.Equal(t, "", appender.String())
+	})
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	appender := NewByteBufferAppender()
+	logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+	logger.DisableLogEventMetadata()
+
+	output := log.Writer()
+	flags := log.Flags()
+	prefix := log.Prefix()
+	defer func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}()
+	log.SetOutput(io.Discard)
+	log.SetFlags(log.LstdFlags | log.Lmsgprefix)
+	log.SetPrefix("lib: ")
+
+	restore := RedirectStdLog(&logger, WithStdLogLevel(LogLevel_WARN))
+	log.Println("redirected")
+	restore()
+	log.Println("not redirected")
+
+	assert.Equal(t, "redirected\n", appender.String())
+	assert.Equal(t, io.Discard, log.Writer())
+}
+
+func TestStripStdLogHeader(t *testing.T) {
+	for _, test := range []struct {
+		line     string
+		prefix   string
+		flags    int
+		expected string
+	}{
+		{"message", "", 0, "message"},
+		{"p: message", "p: ", 0, "message"},
+		{"p: 2009/01/23 01:23:23 message", "p: ", log.LstdFlags, "message"},
+		{"2009/01/23 01:23:23.123123 /a/b/c/d.go:23: message", "", log.LstdFlags | log.Lmicroseconds | log.Llongfile, "message"},
+		{"01:23:23 d.go:23: p: message: detail", "p: ", log.Ltime | log.Lshortfile | log.Lmsgprefix, "message: detail"},
+	} {
+		assert.Equal(t, test.expected, stripStdLogHeader(test.line, test.prefix, test.flags), strings.TrimSpace(test.line))
+	}
+}
@@ -0,0 +1,131 @@
+package golog
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+)
+
+// TraceID is the W3C trace-id, compatible with trace.TraceID of OpenTelemetry
+type TraceID [16]byte
+
+// SpanID is the W3C parent-id, compatible with trace.SpanID of OpenTelemetry
+type SpanID [8]byte
+
+// TraceFlags is the W3C trace-flags, compatible with trace.TraceFlags of OpenTelemetry
+type TraceFlags byte
+
+// TraceFlags_SAMPLED
+const TraceFlags_SAMPLED TraceFlags = 0x01
+
+// IsValid reports whether the trace id is not all zeros
+func (traceID TraceID) IsValid() bool {
+	return traceID != TraceID{}
+}
+
+// String returns the lowercase hex encoding
+func (traceID TraceID) String() string {
+	return hex.EncodeToString(traceID[:])
+}
+
+// IsValid reports whether the span id is not all zeros
+func (spanID SpanID) IsValid() bool {
+	return spanID != SpanID{}
+}
+
+// String returns the lowercase hex encoding
+func (spanID SpanID) String() string {
+	return hex.EncodeToString(spanID[:])
+}
+
+// IsSampled reports whether the sampled flag is set
+func (flags TraceFlags) IsSampled() bool {
+	return flags&TraceFlags_SAMPLED != 0
+}
+
+// String returns the lowercase hex encoding
+func (flags TraceFlags) String() string {
+	return hex.EncodeToString([]byte{byte(flags)})
+}
+
+// TraceContext identifies the span in which an event is logged
+type TraceContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	TraceFlags TraceFlags
+}
+
+// IsValid reports whether both the trace id and the span id are valid
+func (traceContext TraceContext) IsValid() bool {
+	return traceContext.TraceID.IsValid() && traceContext.SpanID.IsValid()
+}
+
+// Traceparent returns the W3C traceparent header value, e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func (traceContext TraceContext) Traceparent() string {
+	return "00-" + traceContext.TraceID.String() + "-" + traceContext.SpanID.String() + "-" + traceContext.TraceFlags.String()
+}
+
+// ErrInvalidTraceparent is returned by ParseTraceparent
+var ErrInvalidTraceparent = errors.New("golog: invalid traceparent")
+
+// ParseTraceparent parses the W3C traceparent header value
+func ParseTraceparent(traceparent string) (TraceContext, error) {
+	var traceContext TraceContext
+
+	// version-traceid-parentid-flags
+	const length = 2 + 1 + 32 + 1 + 16 + 1 + 2
+	if len(traceparent) < length || traceparent[2] != '-' || traceparent[35] != '-' || traceparent[52] != '-' {
+		return traceContext, ErrInvalidTraceparent
+	}
+
+	var version [1]byte
+	if !decodeLowerHex(version[:], traceparent[0:2]) || version[0] == 0xff {
+		return traceContext, ErrInvalidTraceparent
+	}
+	// version 00 has no additional fields, future versions may append "-..."
+	if (version[0] == 0 && len(traceparent) != length) || (len(traceparent) > length && traceparent[length] != '-') {
+		return traceContext, ErrInvalidTraceparent
+	}
+
+	var flags [1]byte
+	if !decodeLowerHex(traceContext.TraceID[:], traceparent[3:35]) ||
+		!decodeLowerHex(traceContext.SpanID[:], traceparent[36:52]) ||
+		!decodeLowerHex(flags[:], traceparent[53:55]) {
+		return TraceContext{}, ErrInvalidTraceparent
+	}
+	traceContext.TraceFlags = TraceFlags(flags[0])
+
+	if !traceContext.IsValid() {
+		return TraceContext{}, ErrInvalidTraceparent
+	}
+	return traceContext, nil
+}
+
+// decodeLowerHex decodes src into dst. Uppercase hex digits are invalid in traceparent.
+func decodeLowerHex(dst []byte, src string) bool {
+	for i := 0; i < len(src); i++ {
+		if c := src[i]; c >= 'A' && c <= 'F' {
+			return false
+		}
+	}
+	n, err := hex.Decode(dst, []byte(src))
+	return err == nil && n == len(dst)
+}
+
+// traceContextKey is the key of TraceContext stored in context.Context
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a copy of ctx which carries traceContext
+func ContextWithTraceContext(ctx context.Context, traceContext TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, traceContext)
+}
+
+// TraceContextFromContext returns TraceContext stored by ContextWithTraceContext
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	traceContext, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return traceContext, ok && traceContext.IsValid()
+}
+
+// TraceContextExtractor returns TraceContext of the span active in ctx.
+// To use the spans of OpenTelemetry, convert trace.SpanContextFromContext(ctx) to TraceContext.
+type TraceContextExtractor = func(ctx context.Context) (TraceContext, bool)
@@ -0,0 +1,105 @@
+package golog
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		traceContext, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		assert.Nil(t, err)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceContext.TraceID.String())
+		assert.Equal(t, "00f067aa0ba902b7", traceContext.SpanID.String())
+		assert.True(t, traceContext.TraceFlags.IsSampled())
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceContext.Traceparent())
+	})
+
+	t.Run("future version may have additional fields", func(t *testing.T) {
+		_, err := ParseTraceparent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-what-the-future-will-be-like")
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, traceparent := range []string{
+			"",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+			"00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",
+		} {
+			_, err := ParseTraceparent(traceparent)
+			assert.Equal(t, ErrInvalidTraceparent, err, traceparent)
+		}
+	})
+}
+
+func TestLogger_TraceContext(t *testing.T) {
+	traceContext, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithTraceContext(context.Background(), traceContext)
+
+	t.Run("text event renders the trace context", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.SetMetadataConfig(&MetadataConfig{IsEnabledTrace: true})
+
+		logger.InfoCtx(ctx, "message")
+		logger.Info("no span")
+
+		assert.Equal(t, "   () message trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 trace_flags=01\n   () no span\n", appender.String())
+	})
+
+	t.Run("json event emits the trace context under standard keys", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.SetMetadataConfig(&MetadataConfig{IsEnabledTrace: true})
+
+		logger.WithContext(ctx).Infoj("message")
+
+		var decoded map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(appender.String()), &decoded))
+		assert.Equal(t, map[string]interface{}{
+			"EventData":   "message",
+			"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id":     "00f067aa0ba902b7",
+			"trace_flags": "01",
+		}, decoded)
+	})
+
+	t.Run("disabled by metadata config", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.SetMetadataConfig(&MetadataConfig{})
+
+		logger.InfoCtx(ctx, "message")
+
+		assert.Equal(t, "   () message\n", appender.String())
+	})
+
+	t.Run("custom extractor and formatter", func(t *testing.T) {
+		appender := NewByteBufferAppender()
+		logger := NewLogger("testLogger", LogLevel_TRACE, appender)
+		logger.SetMetadataConfig(&MetadataConfig{IsEnabledTrace: true})
+		logger.SetTraceContextExtractor(func(ctx context.Context) (TraceContext, bool) {
+			parsed, err := ParseTraceparent(ctx.Value(testContextKey("traceparent")).(string))
+			return parsed, err == nil
+		})
+		formatter := NewDefaultMetadataFormatter()
+		formatter.TraceIDFormatter = func(traceID TraceID) string {
+			return strings.ToUpper(traceID.String())
+		}
+		logger.SetMetadataFormatter(&formatter)
+
+		logger.InfoCtx(context.WithValue(context.Background(), testContextKey("traceparent"), traceContext.Traceparent()), "message")
+
+		assert.Equal(t, "   () message trace_id=4BF92F3577B34DA6A3CE929D0E0E4736 span_id=00f067aa0ba902b7 trace_flags=01\n", appender.String())
+	})
+}